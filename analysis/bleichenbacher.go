@@ -0,0 +1,349 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/Lavode/cryptopals/padding"
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/Lavode/cryptopals/rsa"
+)
+
+// Interval is a closed interval [Lower, Upper] of integers.
+type Interval struct {
+	Lower *big.Int
+	Upper *big.Int
+}
+
+// BleichenbacherState is the state of a running Bleichenbacher attack. It
+// contains everything required to resume an interrupted attack.
+type BleichenbacherState struct {
+	// C0 is the (possibly blinded) PKCS-conforming ciphertext which is
+	// being decrypted.
+	C0 *big.Int
+	// S0 is the blinding factor, such that C0 = c * S0^e mod N.
+	S0 *big.Int
+	// S is the most recently found multiplier which made C0 conforming.
+	S *big.Int
+	// M is the set of intervals which contain the plaintext of C0.
+	M []Interval
+	// Round is the round of the attack, starting at 1.
+	Round int
+	// Queries is the number of oracle queries made so far.
+	Queries int
+}
+
+// errBleichenbacherAborted is returned if the checkpoint callback requested
+// the attack to stop.
+var errBleichenbacherAborted = fmt.Errorf("Attack aborted by checkpoint callback")
+
+// Bleichenbacher98 decrypts an RSA ciphertext, given access to an oracle which
+// reveals whether the plaintext of arbitrary ciphertexts starts with the
+// 00 02 header of PKCS#1 v1.5 encryption padding. The ciphertext's plaintext
+// must be padded this way, and is returned without padding.
+//
+// This is the attack described by Bleichenbacher in "Chosen Ciphertext
+// Attacks Against Protocols Based on the RSA Encryption Standard PKCS #1".
+// Multiplying the ciphertext by s^e multiplies the plaintext by s, and any
+// conforming result narrows down the set of intervals containing the
+// plaintext, until only a single value is left.
+//
+// If checkpoint is not nil, it is called with the attack's state after every
+// round. Returning false from it stops the attack, which can then be resumed
+// from that state with ResumeBleichenbacher98(). The state is returned in
+// either case, including the number of oracle queries made.
+func Bleichenbacher98(pub rsa.PublicKey, ctxt *big.Int, or oracle.PKCS1v15Oracle, checkpoint func(BleichenbacherState) bool) ([]byte, BleichenbacherState, error) {
+	return Bleichenbacher98From(nil, pub, ctxt, or, checkpoint)
+}
+
+// Bleichenbacher98From decrypts an RSA ciphertext as Bleichenbacher98() does,
+// reading the blinding factors from the given source of randomness. If r is
+// nil, a CSPRNG is used.
+func Bleichenbacher98From(r io.Reader, pub rsa.PublicKey, ctxt *big.Int, or oracle.PKCS1v15Oracle, checkpoint func(BleichenbacherState) bool) ([]byte, BleichenbacherState, error) {
+	state, err := bleichenbacherBlind(r, pub, ctxt, or)
+	if err != nil {
+		return []byte{}, state, err
+	}
+
+	return ResumeBleichenbacher98(pub, or, state, checkpoint)
+}
+
+// ResumeBleichenbacher98 resumes a Bleichenbacher attack from the given state,
+// as passed to the checkpoint callback of Bleichenbacher98().
+func ResumeBleichenbacher98(pub rsa.PublicKey, or oracle.PKCS1v15Oracle, state BleichenbacherState, checkpoint func(BleichenbacherState) bool) ([]byte, BleichenbacherState, error) {
+	b2, b3 := bleichenbacherBounds(pub)
+	state = state.clone()
+
+	for {
+		// Step 4: Done once a single value is left.
+		if len(state.M) == 1 && state.M[0].Lower.Cmp(state.M[0].Upper) == 0 {
+			m := new(big.Int).ModInverse(state.S0, pub.N)
+			m.Mul(m, state.M[0].Lower)
+			m.Mod(m, pub.N)
+
+			padded := make([]byte, pub.Size())
+			m.FillBytes(padded)
+
+			msg, err := padding.PKCS1v15Unpad(padded)
+			if err != nil {
+				return []byte{}, state, fmt.Errorf("Error unpadding recovered plaintext: %v", err)
+			}
+
+			return msg, state, nil
+		}
+
+		if len(state.M) == 0 {
+			return []byte{}, state, fmt.Errorf("No intervals left to search in round %d", state.Round)
+		}
+
+		// Step 2: Search for the next conforming multiplier.
+		var s *big.Int
+		var err error
+		if state.Round == 1 {
+			// Step 2a: Start with the smallest multiplier which
+			// could possibly lead to a conforming plaintext.
+			s, err = bleichenbacherSearch(pub, or, &state, ceilDiv(pub.N, b3))
+		} else if len(state.M) > 1 {
+			// Step 2b: Multiple intervals left, so we'll keep
+			// searching linearly.
+			s, err = bleichenbacherSearch(pub, or, &state, new(big.Int).Add(state.S, big.NewInt(1)))
+		} else {
+			// Step 2c: A single interval left.
+			s, err = bleichenbacherSearchInterval(pub, or, &state, b2, b3)
+		}
+		if err != nil {
+			return []byte{}, state, err
+		}
+
+		state.S = s
+		state.M = bleichenbacherNarrow(pub, state.M, s, b2, b3)
+		state.Round++
+
+		if checkpoint != nil && !checkpoint(state.clone()) {
+			return []byte{}, state, errBleichenbacherAborted
+		}
+	}
+}
+
+// bleichenbacherBlind performs step 1 of the attack, reading blinding factors
+// from r. If the ciphertext is already PKCS-conforming, blinding is skipped.
+func bleichenbacherBlind(r io.Reader, pub rsa.PublicKey, ctxt *big.Int, or oracle.PKCS1v15Oracle) (BleichenbacherState, error) {
+	b2, b3 := bleichenbacherBounds(pub)
+	state := BleichenbacherState{
+		C0:    new(big.Int).Set(ctxt),
+		S0:    big.NewInt(1),
+		M:     []Interval{{Lower: b2, Upper: new(big.Int).Sub(b3, big.NewInt(1))}},
+		Round: 1,
+	}
+
+	for {
+		conforming, err := or.IsConforming(state.C0)
+		state.Queries++
+		if err != nil {
+			return state, fmt.Errorf("Error querying padding oracle: %v", err)
+		}
+
+		if conforming {
+			return state, nil
+		}
+
+		s0, err := rand.Int(randutil.Reader(r), pub.N)
+		if err != nil {
+			return state, fmt.Errorf("Error generating blinding factor: %v", err)
+		}
+		state.S0 = s0
+		state.C0 = bleichenbacherMultiply(pub, ctxt, s0)
+	}
+}
+
+// bleichenbacherSearch searches for the smallest multiplier s >= from such
+// that c0 * s^e is PKCS-conforming.
+func bleichenbacherSearch(pub rsa.PublicKey, or oracle.PKCS1v15Oracle, state *BleichenbacherState, from *big.Int) (*big.Int, error) {
+	s := new(big.Int).Set(from)
+
+	for {
+		conforming, err := bleichenbacherQuery(pub, or, state, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if conforming {
+			return s, nil
+		}
+
+		s.Add(s, big.NewInt(1))
+	}
+}
+
+// bleichenbacherSearchInterval performs step 2c of the attack, where a single
+// interval [a, b] is left. It iterates over values of r, starting at:
+//
+//	r >= 2 * (b * s - 2B) / N
+//
+// and for each tries all multipliers s with:
+//
+//	(2B + r * N) / b <= s < (3B + r * N) / a
+func bleichenbacherSearchInterval(pub rsa.PublicKey, or oracle.PKCS1v15Oracle, state *BleichenbacherState, b2, b3 *big.Int) (*big.Int, error) {
+	a := state.M[0].Lower
+	b := state.M[0].Upper
+
+	r := new(big.Int).Mul(b, state.S)
+	r.Sub(r, b2)
+	r.Lsh(r, 1)
+	r = ceilDiv(r, pub.N)
+
+	for ; ; r.Add(r, big.NewInt(1)) {
+		rn := new(big.Int).Mul(r, pub.N)
+
+		s := ceilDiv(new(big.Int).Add(b2, rn), b)
+		upper := ceilDiv(new(big.Int).Add(b3, rn), a)
+
+		for ; s.Cmp(upper) < 0; s.Add(s, big.NewInt(1)) {
+			conforming, err := bleichenbacherQuery(pub, or, state, s)
+			if err != nil {
+				return nil, err
+			}
+
+			if conforming {
+				return s, nil
+			}
+		}
+	}
+}
+
+// bleichenbacherNarrow performs step 3 of the attack. For each interval
+// [a, b] and each r with:
+//
+//	(a * s - 3B + 1) / N <= r <= (b * s - 2B) / N
+//
+// the plaintext must be in:
+//
+//	[max(a, (2B + r * N) / s), min(b, (3B - 1 + r * N) / s)]
+//
+// The resulting intervals are merged where they overlap.
+func bleichenbacherNarrow(pub rsa.PublicKey, intervals []Interval, s, b2, b3 *big.Int) []Interval {
+	out := make([]Interval, 0)
+	b3MinusOne := new(big.Int).Sub(b3, big.NewInt(1))
+
+	for _, interval := range intervals {
+		rMin := new(big.Int).Mul(interval.Lower, s)
+		rMin.Sub(rMin, b3MinusOne)
+		rMin = ceilDiv(rMin, pub.N)
+
+		rMax := new(big.Int).Mul(interval.Upper, s)
+		rMax.Sub(rMax, b2)
+		rMax.Div(rMax, pub.N)
+
+		for r := rMin; r.Cmp(rMax) <= 0; r = new(big.Int).Add(r, big.NewInt(1)) {
+			rn := new(big.Int).Mul(r, pub.N)
+
+			lower := ceilDiv(new(big.Int).Add(b2, rn), s)
+			if lower.Cmp(interval.Lower) < 0 {
+				lower.Set(interval.Lower)
+			}
+
+			upper := new(big.Int).Add(b3MinusOne, rn)
+			upper.Div(upper, s)
+			if upper.Cmp(interval.Upper) > 0 {
+				upper.Set(interval.Upper)
+			}
+
+			if lower.Cmp(upper) <= 0 {
+				out = append(out, Interval{Lower: lower, Upper: upper})
+			}
+		}
+	}
+
+	return mergeIntervals(out)
+}
+
+// mergeIntervals merges overlapping intervals, returning them sorted by their
+// lower bound.
+func mergeIntervals(intervals []Interval) []Interval {
+	if len(intervals) == 0 {
+		return intervals
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Lower.Cmp(intervals[j].Lower) < 0
+	})
+
+	merged := []Interval{intervals[0]}
+	for _, interval := range intervals[1:] {
+		last := &merged[len(merged)-1]
+
+		if interval.Lower.Cmp(last.Upper) <= 0 {
+			if interval.Upper.Cmp(last.Upper) > 0 {
+				last.Upper = interval.Upper
+			}
+		} else {
+			merged = append(merged, interval)
+		}
+	}
+
+	return merged
+}
+
+// bleichenbacherQuery queries the oracle whether c0 * s^e is PKCS-conforming.
+func bleichenbacherQuery(pub rsa.PublicKey, or oracle.PKCS1v15Oracle, state *BleichenbacherState, s *big.Int) (bool, error) {
+	state.Queries++
+
+	conforming, err := or.IsConforming(bleichenbacherMultiply(pub, state.C0, s))
+	if err != nil {
+		return false, fmt.Errorf("Error querying padding oracle: %v", err)
+	}
+
+	return conforming, nil
+}
+
+// bleichenbacherMultiply calculates c * s^e mod N, which is a ciphertext of
+// m * s mod N.
+func bleichenbacherMultiply(pub rsa.PublicKey, c, s *big.Int) *big.Int {
+	out := new(big.Int).Exp(s, pub.E, pub.N)
+	out.Mul(out, c)
+
+	return out.Mod(out, pub.N)
+}
+
+// bleichenbacherBounds returns 2B and 3B, where B = 2^(8(k-2)) for a modulus
+// of k bytes.
+func bleichenbacherBounds(pub rsa.PublicKey) (*big.Int, *big.Int) {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(8*(pub.Size()-2)))
+
+	b2 := new(big.Int).Lsh(b, 1)
+	b3 := new(big.Int).Add(b2, b)
+
+	return b2, b3
+}
+
+// clone returns a deep copy of the state.
+func (state BleichenbacherState) clone() BleichenbacherState {
+	out := state
+	if state.S != nil {
+		out.S = new(big.Int).Set(state.S)
+	}
+
+	out.M = make([]Interval, len(state.M))
+	for i, interval := range state.M {
+		out.M[i] = Interval{
+			Lower: new(big.Int).Set(interval.Lower),
+			Upper: new(big.Int).Set(interval.Upper),
+		}
+	}
+
+	return out
+}
+
+// ceilDiv returns ceil(a / b) for a positive b.
+func ceilDiv(a, b *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(a, b, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+
+	return q
+}
@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBleichenbacher98(t *testing.T) {
+	or := oracle.RSAPKCS1v15{Bits: 256}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msg := []byte("kick it, CC")
+	ctxt, err := or.Encrypt(msg)
+	assert.Nil(t, err)
+
+	recovered, state, err := Bleichenbacher98(pub, ctxt, &or, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, recovered)
+	assert.Greater(t, state.Queries, 0)
+}
+
+func TestBleichenbacher98Resume(t *testing.T) {
+	or := oracle.RSAPKCS1v15{Bits: 256}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msg := []byte("kick it, CC")
+	ctxt, err := or.Encrypt(msg)
+	assert.Nil(t, err)
+
+	// Abort after the third round
+	var checkpoint BleichenbacherState
+	_, _, err = Bleichenbacher98(pub, ctxt, &or, func(state BleichenbacherState) bool {
+		checkpoint = state
+		return state.Round < 3
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 3, checkpoint.Round)
+
+	queries := checkpoint.Queries
+	recovered, state, err := ResumeBleichenbacher98(pub, &or, checkpoint, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, recovered)
+	assert.Greater(t, state.Queries, queries)
+
+	// Resuming must not have modified the checkpoint
+	assert.Equal(t, queries, checkpoint.Queries)
+}
+
+func TestBleichenbacher98BlindsNonConformingCiphertext(t *testing.T) {
+	or := oracle.RSAPKCS1v15{Bits: 256}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	// A plaintext without padding requires blinding in step 1, and can't
+	// be unpadded in the end.
+	ctxt, err := pub.Encrypt(big.NewInt(1337))
+	assert.Nil(t, err)
+
+	_, state, err := Bleichenbacher98(pub, ctxt, &or, nil)
+	assert.Error(t, err)
+	assert.NotEqual(t, 0, state.S0.Cmp(big.NewInt(1)))
+}
+
+func TestBleichenbacher98768Bit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping 768-bit Bleichenbacher attack in short mode")
+	}
+
+	or := oracle.RSAPKCS1v15{Bits: 768}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msg := []byte("kick it, CC")
+	ctxt, err := or.Encrypt(msg)
+	assert.Nil(t, err)
+
+	recovered, state, err := Bleichenbacher98(pub, ctxt, &or, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, recovered)
+	t.Logf("Decrypted 768-bit ciphertext with %d oracle queries", state.Queries)
+}
+
+func TestMergeIntervals(t *testing.T) {
+	interval := func(a, b int64) Interval {
+		return Interval{Lower: big.NewInt(a), Upper: big.NewInt(b)}
+	}
+
+	assert.Equal(
+		t,
+		[]Interval{interval(1, 7), interval(9, 9), interval(10, 12)},
+		mergeIntervals([]Interval{
+			interval(10, 12), interval(3, 7), interval(1, 4), interval(9, 9), interval(5, 6),
+		}),
+	)
+}
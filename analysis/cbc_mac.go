@@ -0,0 +1,176 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Lavode/cryptopals/bitwise"
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/Lavode/cryptopals/padding"
+)
+
+// ForgeCBCMACIV calculates an IV under which the target message has the same
+// CBC-MAC as the original message has under the original IV.
+//
+// As the IV is only XORed into the first block of the message, changes to the
+// first block can be cancelled out by applying the same changes to the IV:
+//
+//	IV' = IV XOR m_1 XOR m'_1
+//
+// The target message must thus have the same length as the original message
+// and may differ from it only in the first block.
+func ForgeCBCMACIV(msg []byte, iv []byte, target []byte) ([]byte, error) {
+	if len(iv) != cipher.AESBlockSize {
+		return []byte{}, fmt.Errorf("Expected IV of length %d, but got %d", cipher.AESBlockSize, len(iv))
+	}
+
+	if len(msg) != len(target) {
+		return []byte{}, fmt.Errorf("Target message must be of same length as original message")
+	}
+
+	if len(msg) > cipher.AESBlockSize && !bytes.Equal(msg[cipher.AESBlockSize:], target[cipher.AESBlockSize:]) {
+		return []byte{}, fmt.Errorf("Target message may only differ from original message in the first block")
+	}
+
+	// Messages shorter than a block are padded, which the XOR leaves
+	// untouched as it is equal for both.
+	length := cipher.AESBlockSize
+	if len(msg) < length {
+		length = len(msg)
+	}
+
+	forged := make([]byte, cipher.AESBlockSize)
+	copy(forged, iv)
+	for i := 0; i < length; i++ {
+		forged[i] ^= msg[i] ^ target[i]
+	}
+
+	return forged, nil
+}
+
+// ForgeCBCMACExtension combines two messages authenticated with CBC-MAC
+// under the same key and IV into a message which has the same MAC as the
+// second one.
+//
+// Processing the first (padded) message leaves the CBC-MAC's state at its MAC
+// t1. XORing t1 into the first block of the second message cancels it out, so
+// the state continues exactly as if the second message had been processed
+// from the start:
+//
+//	PAD(m1) || (m2_1 XOR t1) || m2_2 || ... || m2_n
+//
+// This requires a zero IV, or the IV's contribution to the second message to
+// be cancelled out as well.
+//
+// The first block of the second message is garbled in the process.
+func ForgeCBCMACExtension(msg1 []byte, mac1 []byte, msg2 []byte) ([]byte, error) {
+	if len(mac1) != cipher.AESBlockSize {
+		return []byte{}, fmt.Errorf("Expected MAC of length %d, but got %d", cipher.AESBlockSize, len(mac1))
+	}
+
+	if len(msg2) < cipher.AESBlockSize {
+		return []byte{}, fmt.Errorf("Second message must be at least one block long")
+	}
+
+	forged := padding.PKCS7Pad(msg1, cipher.AESBlockSize)
+	forged = append(forged, bitwise.Xor(msg2[:cipher.AESBlockSize], mac1)...)
+	forged = append(forged, msg2[cipher.AESBlockSize:]...)
+
+	return forged, nil
+}
+
+// ForgeTransferV1 forges a version 1 request to the transfer server, which
+// transfers the given amount from the victim's account to the attacker's.
+//
+// It does so by having the client create a request for a transfer from the
+// attacker's account to itself, and then changing the sender in the first
+// block of the message using ForgeCBCMACIV(). As such the victim's account
+// number must be of the same length as the attacker's.
+func ForgeTransferV1(server *oracle.TransferServer, victim int, amount int) ([]byte, error) {
+	attacker := server.AttackerAccount
+	if len(fmt.Sprint(victim)) != len(fmt.Sprint(attacker)) {
+		return []byte{}, fmt.Errorf("Account numbers of victim and attacker must be of same length")
+	}
+
+	request, err := server.ClientV1(attacker, attacker, amount)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error querying client: %v", err)
+	}
+
+	msgLength := len(request) - 2*cipher.AESBlockSize
+	msg := request[:msgLength]
+	iv := request[msgLength : msgLength+cipher.AESBlockSize]
+	mac := request[msgLength+cipher.AESBlockSize:]
+
+	target := []byte(fmt.Sprintf("from=%d&to=%d&amount=%d", victim, attacker, amount))
+	forgedIV, err := ForgeCBCMACIV(msg, iv, target)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	forged := append(target, forgedIV...)
+	return append(forged, mac...), nil
+}
+
+// ForgeTransferV2 forges a version 2 request to the transfer server, which
+// transfers the given amount from the victim's account to the attacker's.
+//
+// It does so by capturing a request of the victim, and extending it with a
+// request of the attacker using ForgeCBCMACExtension(), which garbles the
+// first block of the attacker's request, and with it some or all of its
+// from=#{attacker}&tx_list= header.
+//
+// Unless the header's '&' lies beyond the garbled block, the attacker's
+// transaction list thus continues the victim's. It starts with a dummy
+// transaction which absorbs the padding of the victim's request, the garbled
+// block and any rest of the header following it. Otherwise the header starts
+// a tx_list parameter of its own, which takes precedence over the victim's.
+//
+// The garbled block depends on the MAC of the captured request. Should it
+// contain characters which break parsing of the resulting request, the
+// attacker waits for the victim to send another request. The request is
+// only returned once parsing it yields the victim's transfer to the attacker.
+func ForgeTransferV2(server *oracle.TransferServer, victim int, amount int) ([]byte, error) {
+	attacker := server.AttackerAccount
+
+	transfers := []oracle.Transfer{
+		{To: attacker, Amount: 1},
+		{To: attacker, Amount: amount},
+	}
+
+	request, err := server.ClientV2(attacker, transfers)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error querying client: %v", err)
+	}
+	msg2 := request[:len(request)-cipher.AESBlockSize]
+	mac2 := request[len(request)-cipher.AESBlockSize:]
+
+	target := oracle.Transfer{From: victim, To: attacker, Amount: amount}
+	for attempt := 0; attempt < 100; attempt++ {
+		captured, err := server.CaptureV2(victim)
+		if err != nil {
+			return []byte{}, fmt.Errorf("Error capturing request: %v", err)
+		}
+		msg1 := captured[:len(captured)-cipher.AESBlockSize]
+		mac1 := captured[len(captured)-cipher.AESBlockSize:]
+
+		forged, err := ForgeCBCMACExtension(msg1, mac1, msg2)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		parsed, err := oracle.ParseTransfersV2(forged)
+		if err != nil {
+			continue
+		}
+
+		for _, transfer := range parsed {
+			if transfer == target {
+				return append(forged, mac2...), nil
+			}
+		}
+	}
+
+	return []byte{}, fmt.Errorf("Unable to find request which yields transfer from %d to %d", victim, attacker)
+}
@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/bitwise"
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// CBCMACHashCollision forges a message of the form:
+//
+//	prefix || glue || original
+//
+// which has the same CBCMACHash as the original message, given as the target
+// hash. The prefix can be chosen arbitrarily.
+//
+// As the hash's key is public, the CBC-MAC state after any message can be
+// calculated, and the block cipher inverted. A final glue block of:
+//
+//	D_K(0) XOR s
+//
+// where s is the state before it, thus resets the state to the all-zero IV,
+// after which the original message is processed as if it were on its own.
+// As the glue ends on a block boundary, the original message's padding is
+// unchanged as well.
+//
+// If allowed is not nil, all bytes of the glue must satisfy it, as is
+// required for it to e.g. be hidden inside a comment. To this end the prefix
+// is filled up to the next block boundary using an allowed byte, followed by
+// a block of random allowed bytes, which is chosen anew until the final glue
+// block consists of allowed bytes only.
+func CBCMACHashCollision(target []byte, original []byte, prefix []byte, allowed func(byte) bool) ([]byte, error) {
+	return CBCMACHashCollisionFrom(nil, target, original, prefix, allowed)
+}
+
+// CBCMACHashCollisionFrom forges a message as CBCMACHashCollision() does,
+// reading the random block from the given source of randomness. If r is nil,
+// a CSPRNG is used.
+func CBCMACHashCollisionFrom(r io.Reader, target []byte, original []byte, prefix []byte, allowed func(byte) bool) ([]byte, error) {
+	if allowed == nil {
+		allowed = func(byte) bool { return true }
+	}
+
+	allowedBytes := make([]byte, 0)
+	for i := 0; i < 256; i++ {
+		if allowed(byte(i)) {
+			allowedBytes = append(allowedBytes, byte(i))
+		}
+	}
+	if len(allowedBytes) == 0 {
+		return []byte{}, fmt.Errorf("No byte values allowed in glue")
+	}
+
+	hash, err := cipher.CBCMACHash(original)
+	if err != nil {
+		return []byte{}, err
+	}
+	if !bytes.Equal(hash, target) {
+		return []byte{}, fmt.Errorf("Original message does not match target hash")
+	}
+
+	// Fill prefix up to block boundary. We'll prefer spaces for the
+	// sake of legibility.
+	filler := allowedBytes[0]
+	if allowed(' ') {
+		filler = ' '
+	}
+	aligned := append([]byte{}, prefix...)
+	for len(aligned)%cipher.AESBlockSize != 0 {
+		aligned = append(aligned, filler)
+	}
+
+	// State after the aligned prefix
+	state := make([]byte, cipher.AESBlockSize)
+	if len(aligned) > 0 {
+		cbc := cipher.AESCBC{Key: cipher.CBCMACHashKey, IV: state}
+		ctxt, err := cbc.Encrypt(aligned)
+		if err != nil {
+			return []byte{}, err
+		}
+		state = ctxt[len(ctxt)-cipher.AESBlockSize:]
+	}
+
+	// D_K(0)
+	ecb := cipher.AESECB{Key: cipher.CBCMACHashKey}
+	decryptedZero, err := ecb.Decrypt(make([]byte, cipher.AESBlockSize))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	cbc := cipher.AESCBC{Key: cipher.CBCMACHashKey, IV: state}
+	for attempt := 0; attempt < 1<<24; attempt++ {
+		random, err := randomBytesFrom(r, allowedBytes, cipher.AESBlockSize)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		randomState, err := cbc.Encrypt(random)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		glue := bitwise.Xor(decryptedZero, randomState)
+		if !allBytes(glue, allowed) {
+			continue
+		}
+
+		forged := append(aligned, random...)
+		forged = append(forged, glue...)
+		forged = append(forged, original...)
+
+		return forged, nil
+	}
+
+	return []byte{}, fmt.Errorf("Unable to find glue block consisting of allowed bytes")
+}
+
+// JavaScriptCommentByte returns whether the byte may be used inside a
+// single-line JavaScript comment, without ending it.
+//
+// Besides the ASCII line terminators, this excludes all non-ASCII bytes, as
+// some of them might decode to the Unicode line or paragraph separators.
+func JavaScriptCommentByte(b byte) bool {
+	return b != '\n' && b != '\r' && b < 0x80
+}
+
+// randomBytesFrom returns n bytes chosen uniformly at random from the given
+// alphabet, reading from r.
+func randomBytesFrom(r io.Reader, alphabet []byte, n int) ([]byte, error) {
+	out := make([]byte, n)
+	buf := make([]byte, 1)
+
+	for i := range out {
+		// Rejection sampling, to prevent modulo bias
+		for {
+			_, err := io.ReadFull(randutil.Reader(r), buf)
+			if err != nil {
+				return []byte{}, fmt.Errorf("Error generating random bytes: %v", err)
+			}
+
+			if int(buf[0]) < 256-256%len(alphabet) {
+				out[i] = alphabet[int(buf[0])%len(alphabet)]
+				break
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// allBytes returns whether all bytes satisfy the predicate.
+func allBytes(bs []byte, predicate func(byte) bool) bool {
+	for _, b := range bs {
+		if !predicate(b) {
+			return false
+		}
+	}
+
+	return true
+}
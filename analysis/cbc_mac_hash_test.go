@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBCMACHashCollision(t *testing.T) {
+	original := []byte("alert('MZA who was that?');\n")
+	target, err := hex.DecodeString("296b8d7cb78a243dda4d0a61d33bbdd1")
+	assert.Nil(t, err)
+
+	prefix := []byte("alert('Ayo, the Wu is back!');//")
+
+	forged, err := CBCMACHashCollision(target, original, prefix, JavaScriptCommentByte)
+	assert.Nil(t, err)
+
+	hash, err := cipher.CBCMACHash(forged)
+	assert.Nil(t, err)
+	assert.Equal(t, target, hash)
+
+	assert.Equal(t, prefix, forged[:len(prefix)])
+	assert.Equal(t, original, forged[len(forged)-len(original):])
+
+	glue := forged[len(prefix) : len(forged)-len(original)]
+	for _, b := range glue {
+		assert.True(t, JavaScriptCommentByte(b))
+	}
+}
+
+func TestCBCMACHashCollisionUnconstrained(t *testing.T) {
+	original := []byte("alert('MZA who was that?');\n")
+	target, err := cipher.CBCMACHash(original)
+	assert.Nil(t, err)
+
+	// Unaligned prefix
+	forged, err := CBCMACHashCollision(target, original, []byte("Hello"), nil)
+	assert.Nil(t, err)
+
+	hash, err := cipher.CBCMACHash(forged)
+	assert.Nil(t, err)
+	assert.Equal(t, target, hash)
+
+	_, err = CBCMACHashCollision([]byte("not the hash"), original, []byte("Hello"), nil)
+	assert.Error(t, err)
+}
+
+func TestJavaScriptCommentByte(t *testing.T) {
+	assert.True(t, JavaScriptCommentByte('a'))
+	assert.True(t, JavaScriptCommentByte('/'))
+	assert.False(t, JavaScriptCommentByte('\n'))
+	assert.False(t, JavaScriptCommentByte('\r'))
+	assert.False(t, JavaScriptCommentByte(0xe2))
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForgeCBCMACIV(t *testing.T) {
+	key, err := cipher.NewKey()
+	assert.Nil(t, err)
+	iv, err := cipher.NewKey()
+	assert.Nil(t, err)
+
+	msg := []byte("from=1&to=2&amount=100")
+	target := []byte("from=7&to=9&amount=100")
+
+	cbc := cipher.AESCBC{Key: key, IV: iv}
+	mac, err := cbc.MAC(msg)
+	assert.Nil(t, err)
+
+	forgedIV, err := ForgeCBCMACIV(msg, iv, target)
+	assert.Nil(t, err)
+
+	forged := cipher.AESCBC{Key: key, IV: forgedIV}
+	valid, err := forged.VerifyMAC(target, mac)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	// Changes past the first block can't be forged
+	_, err = ForgeCBCMACIV(msg, iv, []byte("from=1&to=2&amount=999"))
+	assert.Error(t, err)
+}
+
+func TestForgeCBCMACExtension(t *testing.T) {
+	key, err := cipher.NewKey()
+	assert.Nil(t, err)
+	cbc := cipher.AESCBC{Key: key, IV: make([]byte, cipher.AESBlockSize)}
+
+	msg1 := []byte("from=1&tx_list=2:100;3:250")
+	msg2 := []byte("from=4&tx_list=4:1;4:1000000")
+
+	mac1, err := cbc.MAC(msg1)
+	assert.Nil(t, err)
+	mac2, err := cbc.MAC(msg2)
+	assert.Nil(t, err)
+
+	forged, err := ForgeCBCMACExtension(msg1, mac1, msg2)
+	assert.Nil(t, err)
+	assert.Equal(t, msg1, forged[:len(msg1)])
+	assert.Equal(t, msg2[cipher.AESBlockSize:], forged[len(forged)-len(msg2)+cipher.AESBlockSize:])
+
+	valid, err := cbc.VerifyMAC(forged, mac2)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+}
+
+func TestForgeTransferV1(t *testing.T) {
+	server := oracle.TransferServer{AttackerAccount: 42}
+
+	request, err := ForgeTransferV1(&server, 17, 1000000)
+	assert.Nil(t, err)
+
+	transfer, err := server.HandleV1(request)
+	assert.Nil(t, err)
+	assert.Equal(t, oracle.Transfer{From: 17, To: 42, Amount: 1000000}, transfer)
+
+	// Tampering without fixing the IV is detected
+	request[5] = '1'
+	request[6] = '8'
+	_, err = server.HandleV1(request)
+	assert.Error(t, err)
+}
+
+func TestForgeTransferV2(t *testing.T) {
+	// Account numbers of 1 to 12 digits, whose headers fit into the
+	// garbled block, are cut by it, or have their '&' beyond it. Those of
+	// more than 10 digits do not fit into 32-bit ints.
+	digits := 12
+	if strconv.IntSize == 32 {
+		digits = 10
+	}
+
+	attacker := int64(0)
+	for d := 1; d <= digits; d++ {
+		attacker = 10*attacker + int64(d%10)
+		server := oracle.TransferServer{AttackerAccount: int(attacker)}
+
+		request, err := ForgeTransferV2(&server, 17, 1000000)
+		assert.Nil(t, err, "Attacker %d", attacker)
+
+		transfers, err := server.HandleV2(request)
+		assert.Nil(t, err)
+		assert.Contains(t, transfers, oracle.Transfer{From: 17, To: int(attacker), Amount: 1000000})
+	}
+
+	// The client refuses to create requests for other accounts
+	server := oracle.TransferServer{AttackerAccount: 42}
+	_, err := server.ClientV2(17, []oracle.Transfer{{To: 42, Amount: 1000000}})
+	assert.Error(t, err)
+}
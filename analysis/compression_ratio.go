@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+// Base64Alphabet contains all characters which may occur in standard Base64
+// encoding, including the padding character.
+const Base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/="
+
+// compressionRatioJunk are characters which neither occur in the request nor
+// compress well among themselves. They are used to pad the body to a block
+// boundary.
+const compressionRatioJunk = "!@#$%^&*()[]{}<>~|`;',.?_\\\"-"
+
+// compressionRatioSeparator is a character which does not occur in the
+// request, used to cut a guess's match short.
+const compressionRatioSeparator = 0
+
+// compressionRatioMaxLookahead is the maximum number of characters by which
+// candidates are extended to break ties.
+const compressionRatioMaxLookahead = 2
+
+// CompressionRatioRecover recovers a secret from a request, given access to an
+// oracle which leaks the length of the compressed and encrypted request, and
+// allows to choose the request's body.
+//
+// The secret must directly follow the known prefix in the request, consist of
+// characters from the alphabet, and be terminated by the terminator
+// character.
+//
+// As compression replaces repeated strings with references, a body which
+// repeats a prefix of the secret compresses better than one which does not.
+// The secret is thus recovered character by character, by appending each
+// candidate to the known part and picking the one which compresses best.
+// To cancel out the cost of the candidate itself, each candidate is scored by
+// how much better the body compresses with the candidate directly following
+// the known part, than with a separator between the two.
+//
+// Lengths are rounded to full bytes, or even full blocks, so often several
+// candidates score the same. Ties are thus narrowed down by prefixing the
+// body with an increasing number of incompressible junk characters, until the
+// correct candidate's compressed length is just shy of the next byte or block
+// boundary while the other candidates' lengths cross it.
+// Should several candidates remain, they are told apart by extending each
+// with one or more further characters.
+func CompressionRatioRecover(or oracle.LengthOracle, known []byte, alphabet []byte, terminator byte) ([]byte, error) {
+	guess := append([]byte{}, known...)
+	candidates := append(append([]byte{}, alphabet...), terminator)
+
+	for {
+		best, err := compressionRatioBest(or, guess, candidates, candidates, 0)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		// Tie-break by looking further ahead
+		for depth := 1; len(best) > 1 && depth <= compressionRatioMaxLookahead; depth++ {
+			best, err = compressionRatioBest(or, guess, best, candidates, depth)
+			if err != nil {
+				return []byte{}, err
+			}
+		}
+
+		if len(best) != 1 {
+			return []byte{}, fmt.Errorf("Unable to pick between candidates %q after recovering %q", best, guess)
+		}
+
+		if best[0] == terminator {
+			return guess[len(known):], nil
+		}
+
+		guess = append(guess, best[0])
+	}
+}
+
+// compressionRatioBest returns those candidates which, appended to the
+// guess, compress best.
+//
+// Each candidate is extended with depth characters of the lookahead, and
+// scored by its best extension. Candidates are narrowed down over all
+// lengths of junk padding, until only one remains.
+func compressionRatioBest(or oracle.LengthOracle, guess []byte, candidates []byte, lookahead []byte, depth int) ([]byte, error) {
+	for padLength := 0; padLength <= len(compressionRatioJunk) && len(candidates) > 1; padLength++ {
+		pad := []byte(compressionRatioJunk[:padLength])
+
+		scores := make([]int, len(candidates))
+		for i, candidate := range candidates {
+			score, err := compressionRatioScore(or, pad, append(append([]byte{}, guess...), candidate), lookahead, depth)
+			if err != nil {
+				return []byte{}, err
+			}
+			scores[i] = score
+		}
+
+		min := scores[0]
+		for _, score := range scores {
+			if score < min {
+				min = score
+			}
+		}
+
+		best := make([]byte, 0)
+		for i, score := range scores {
+			if score == min {
+				best = append(best, candidates[i])
+			}
+		}
+		candidates = best
+	}
+
+	return candidates, nil
+}
+
+// compressionRatioScore returns the difference in ciphertext length between
+// a body consisting of the pad followed by the guess, and one where the
+// guess's last character is moved behind a separator. If depth is greater
+// than zero, it returns the minimal score of the guess extended by depth
+// characters of the lookahead.
+func compressionRatioScore(or oracle.LengthOracle, pad []byte, guess []byte, lookahead []byte, depth int) (int, error) {
+	if depth == 0 {
+		last := len(guess) - 1
+
+		joined := append(append([]byte{}, pad...), guess...)
+		joined = append(joined, compressionRatioSeparator)
+
+		separated := append(append([]byte{}, pad...), guess[:last]...)
+		separated = append(separated, compressionRatioSeparator, guess[last])
+
+		joinedLength, err := or.Length(joined)
+		if err != nil {
+			return 0, fmt.Errorf("Error querying oracle: %v", err)
+		}
+
+		separatedLength, err := or.Length(separated)
+		if err != nil {
+			return 0, fmt.Errorf("Error querying oracle: %v", err)
+		}
+
+		return joinedLength - separatedLength, nil
+	}
+
+	min := 0
+	for i, c := range lookahead {
+		score, err := compressionRatioScore(or, pad, append(append([]byte{}, guess...), c), lookahead, depth-1)
+		if err != nil {
+			return 0, err
+		}
+
+		if i == 0 || score < min {
+			min = score
+		}
+	}
+
+	return min, nil
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionRatioRecover(t *testing.T) {
+	sessionID := []byte("TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE=")
+
+	for _, c := range []oracle.CompressionCipher{oracle.StreamCipher, oracle.BlockCipher} {
+		or := oracle.CompressionRatio{SessionID: sessionID, Cipher: c}
+
+		recovered, err := CompressionRatioRecover(&or, []byte("sessionid="), []byte(Base64Alphabet), '\n')
+		assert.Nil(t, err)
+		assert.Equal(t, sessionID, recovered)
+	}
+}
+
+func TestCompressionRatioRecoverRandom(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping recovery of random session IDs in short mode")
+	}
+
+	for i := 0; i < 3; i++ {
+		// Varying lengths, with and without padding
+		raw := make([]byte, 31+i)
+		_, err := rand.Read(raw)
+		assert.Nil(t, err)
+		sessionID := []byte(base64.StdEncoding.EncodeToString(raw))
+
+		for _, c := range []oracle.CompressionCipher{oracle.StreamCipher, oracle.BlockCipher} {
+			or := oracle.CompressionRatio{SessionID: sessionID, Cipher: c}
+
+			recovered, err := CompressionRatioRecover(&or, []byte("sessionid="), []byte(Base64Alphabet), '\n')
+			assert.Nil(t, err, "Session ID %q", sessionID)
+			assert.Equal(t, sessionID, recovered)
+		}
+	}
+}
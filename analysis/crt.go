@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// crt combines the residues x = residues[i] mod moduli[i] into x mod M, using
+// the Chinese remainder theorem, where M is the product of the pairwise
+// coprime moduli. It returns x mod M and M.
+func crt(residues []*big.Int, moduli []*big.Int) (*big.Int, *big.Int, error) {
+	if len(residues) != len(moduli) {
+		return nil, nil, fmt.Errorf("Got %d residues, but %d moduli", len(residues), len(moduli))
+	}
+
+	x := big.NewInt(0)
+	m := big.NewInt(1)
+	for i := range residues {
+		// Find x' = x + m * t with x' = r_i mod m_i, that is
+		// t = (r_i - x) * m^-1 mod m_i.
+		inv := new(big.Int).ModInverse(m, moduli[i])
+		if inv == nil {
+			return nil, nil, fmt.Errorf("Modulus %v not coprime to previous moduli", moduli[i])
+		}
+
+		t := new(big.Int).Sub(residues[i], x)
+		t.Mul(t, inv)
+		t.Mod(t, moduli[i])
+
+		x.Add(x, t.Mul(t, m))
+		m.Mul(m, moduli[i])
+	}
+
+	return x, m, nil
+}
+
+// smallFactors returns the distinct prime factors of n which are not greater
+// than the bound, found by trial division.
+func smallFactors(n *big.Int, bound int64) []*big.Int {
+	factors := make([]*big.Int, 0)
+	rest := new(big.Int).Set(n)
+	mod := new(big.Int)
+
+	for d := int64(2); d <= bound; d++ {
+		div := big.NewInt(d)
+		if mod.Mod(rest, div).Sign() != 0 {
+			continue
+		}
+
+		factors = append(factors, div)
+		for mod.Mod(rest, div).Sign() == 0 {
+			rest.Div(rest, div)
+		}
+	}
+
+	return factors
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRT(t *testing.T) {
+	x, m, err := crt(
+		[]*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(2)},
+		[]*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(23), x)
+	assert.Equal(t, big.NewInt(105), m)
+
+	_, _, err = crt(
+		[]*big.Int{big.NewInt(1), big.NewInt(1)},
+		[]*big.Int{big.NewInt(4), big.NewInt(6)},
+	)
+	assert.Error(t, err)
+}
+
+func TestSmallFactors(t *testing.T) {
+	// 2^3 * 3 * 5^2 * 101 * 65537
+	n := big.NewInt(8 * 3 * 25 * 101 * 65537)
+
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(101)}, smallFactors(n, 1000))
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3)}, smallFactors(n, 4))
+}
@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dh"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// DHSubgroupConfinement recovers the private key of a Diffie-Hellman
+// responder modulo the product of the small prime factors of the cofactor
+// (P - 1) / Q, given that it does not validate its peer's public key.
+//
+// For each prime factor r of the cofactor up to the bound, it sends an element
+// h of order r as its public key. The responder's shared secret h^x then only
+// depends on x mod r, which is found by brute-forcing the MAC of the
+// responder's message. The residues are combined with the Chinese remainder
+// theorem.
+//
+// It returns x mod M and M, where M is the product of the factors used.
+// Factors are used in ascending order until M exceeds Q, in which case x mod
+// M is the private key itself.
+func DHSubgroupConfinement(params dh.Parameters, or oracle.DHOracle, bound int64) (*big.Int, *big.Int, error) {
+	return DHSubgroupConfinementFrom(nil, params, or, bound)
+}
+
+// DHSubgroupConfinementFrom recovers the private key modulo small factors as
+// DHSubgroupConfinement() does, reading the elements sent from the given
+// source of randomness. If random is nil, a CSPRNG is used.
+func DHSubgroupConfinementFrom(random io.Reader, params dh.Parameters, or oracle.DHOracle, bound int64) (*big.Int, *big.Int, error) {
+	residues := make([]*big.Int, 0)
+	moduli := make([]*big.Int, 0)
+	product := big.NewInt(1)
+
+	for _, r := range smallFactors(params.Cofactor(), bound) {
+		if product.Cmp(params.Q) > 0 {
+			break
+		}
+
+		h, err := dhElementOfOrder(random, params.P, r)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		msg, mac, err := or.Respond(h)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error querying responder: %v", err)
+		}
+
+		residue, err := dhBruteForceMAC(params.P, h, r, msg, mac)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		residues = append(residues, residue)
+		moduli = append(moduli, r)
+		product.Mul(product, r)
+	}
+
+	return crt(residues, moduli)
+}
+
+// dhElementOfOrder returns a random element of order r in the multiplicative
+// group modulo p, where r must be a prime factor of p - 1, reading from
+// random.
+func dhElementOfOrder(random io.Reader, p *big.Int, r *big.Int) (*big.Int, error) {
+	exp := new(big.Int).Sub(p, big.NewInt(1))
+	exp.Div(exp, r)
+
+	max := new(big.Int).Sub(p, big.NewInt(2))
+	for {
+		h, err := rand.Int(randutil.Reader(random), max)
+		if err != nil {
+			return nil, fmt.Errorf("Error generating random element: %v", err)
+		}
+		h.Add(h, big.NewInt(2))
+
+		h.Exp(h, exp, p)
+		if h.Cmp(big.NewInt(1)) != 0 {
+			return h, nil
+		}
+	}
+}
+
+// dhBruteForceMAC finds b in [0, r) such that the MAC of the message under the
+// shared secret h^b mod p is the given one.
+func dhBruteForceMAC(p *big.Int, h *big.Int, r *big.Int, msg []byte, mac []byte) (*big.Int, error) {
+	secret := big.NewInt(1)
+
+	for b := big.NewInt(0); b.Cmp(r) < 0; b.Add(b, big.NewInt(1)) {
+		if hmac.Equal(dh.MAC(secret, msg), mac) {
+			return b, nil
+		}
+
+		secret.Mul(secret, h)
+		secret.Mod(secret, p)
+	}
+
+	return nil, fmt.Errorf("No residue modulo %v matches MAC", r)
+}
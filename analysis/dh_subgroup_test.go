@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/dh"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDHSubgroupConfinement(t *testing.T) {
+	params := dh.SubgroupParameters()
+	or := oracle.DHResponder{Parameters: params}
+
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	x, m, err := DHSubgroupConfinement(params, &or, 1<<16)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, m.Cmp(params.Q))
+
+	recovered := dh.NewPrivateKey(params, x)
+	assert.Equal(t, pub.Y, recovered.Y)
+}
+
+func TestDHSubgroupConfinementPartial(t *testing.T) {
+	params := dh.SubgroupParameters()
+	or := oracle.DHResponder{Parameters: params}
+
+	key, _, err := DHSubgroupConfinement(params, &or, 1<<16)
+	assert.Nil(t, err)
+
+	// Only the factors 2, 3, 5 and 109
+	x, m, err := DHSubgroupConfinement(params, &or, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2*3*5*109), m.Int64())
+	assert.Equal(t, new(big.Int).Mod(key, m), x)
+}
+
+func TestDHSubgroupConfinementValidatingResponder(t *testing.T) {
+	params := dh.SubgroupParameters()
+	or := oracle.DHResponder{Parameters: params, ValidatePeerKey: true}
+
+	_, _, err := DHSubgroupConfinement(params, &or, 1<<16)
+	assert.Error(t, err)
+}
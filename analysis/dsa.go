@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"crypto"
+	"fmt"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dsa"
+)
+
+// DSASignedMessage is a message along with its DSA signature.
+type DSASignedMessage struct {
+	Msg       []byte
+	Signature dsa.Signature
+}
+
+// DSAKeyFromNonce recovers the private key used to create the signature,
+// given the nonce k which was used to create it.
+//
+// As s = k^-1 (H(m) + x * r) mod q, the private key is:
+//
+//	x = (s * k - H(m)) * r^-1 mod q
+//
+// An error is returned if the recovered key does not match the public key.
+func DSAKeyFromNonce(pub dsa.PublicKey, hash crypto.Hash, msg []byte, sig dsa.Signature, k *big.Int) (dsa.PrivateKey, error) {
+	h, err := dsa.Digest(hash, msg, pub.Q)
+	if err != nil {
+		return dsa.PrivateKey{}, err
+	}
+
+	priv, ok := dsaKeyFromNonce(pub, h, sig, k)
+	if !ok {
+		return dsa.PrivateKey{}, fmt.Errorf("Recovered private key does not match public key")
+	}
+
+	return priv, nil
+}
+
+// DSAKeyFromSmallNonce recovers the private key used to create the signature,
+// if the nonce k was drawn from the (small) range [from, to].
+//
+// It does so by trying all nonces in the range. As r = (g^k mod p) mod q only
+// depends on the nonce, candidates can be checked with a single modular
+// multiplication each, and only a matching candidate requires the private key
+// to be derived and checked against the public key.
+func DSAKeyFromSmallNonce(pub dsa.PublicKey, hash crypto.Hash, msg []byte, sig dsa.Signature, from, to *big.Int) (dsa.PrivateKey, error) {
+	h, err := dsa.Digest(hash, msg, pub.Q)
+	if err != nil {
+		return dsa.PrivateKey{}, err
+	}
+
+	k := new(big.Int).Set(from)
+	gk := new(big.Int).Exp(pub.G, k, pub.P)
+	r := new(big.Int)
+
+	for ; k.Cmp(to) <= 0; k.Add(k, big.NewInt(1)) {
+		r.Mod(gk, pub.Q)
+		if r.Cmp(sig.R) == 0 {
+			priv, ok := dsaKeyFromNonce(pub, h, sig, k)
+			if ok {
+				return priv, nil
+			}
+		}
+
+		// g^(k+1) = g^k * g
+		gk.Mul(gk, pub.G)
+		gk.Mod(gk, pub.P)
+	}
+
+	return dsa.PrivateKey{}, fmt.Errorf("Nonce not in range [%v, %v]", from, to)
+}
+
+// FindRepeatedDSANonces returns the indices of all pairs of signatures which
+// were created using the same nonce.
+//
+// As r = (g^k mod p) mod q only depends on the nonce, two signatures with the
+// same r component are assumed to share their nonce.
+func FindRepeatedDSANonces(msgs []DSASignedMessage) [][2]int {
+	pairs := make([][2]int, 0)
+	seen := make(map[string][]int)
+
+	for i, msg := range msgs {
+		r := msg.Signature.R.String()
+		for _, j := range seen[r] {
+			pairs = append(pairs, [2]int{j, i})
+		}
+		seen[r] = append(seen[r], i)
+	}
+
+	return pairs
+}
+
+// DSAKeyFromRepeatedNonce recovers the private key from a batch of signed
+// messages, at least two of which were signed using the same nonce.
+//
+// Given two such signatures (r, s1) and (r, s2) of messages m1 and m2, the
+// nonce is:
+//
+//	k = (H(m1) - H(m2)) * (s1 - s2)^-1 mod q
+//
+// from which the private key follows as in DSAKeyFromNonce().
+func DSAKeyFromRepeatedNonce(pub dsa.PublicKey, hash crypto.Hash, msgs []DSASignedMessage) (dsa.PrivateKey, error) {
+	for _, pair := range FindRepeatedDSANonces(msgs) {
+		a := msgs[pair[0]]
+		b := msgs[pair[1]]
+
+		ha, err := dsa.Digest(hash, a.Msg, pub.Q)
+		if err != nil {
+			return dsa.PrivateKey{}, err
+		}
+		hb, err := dsa.Digest(hash, b.Msg, pub.Q)
+		if err != nil {
+			return dsa.PrivateKey{}, err
+		}
+
+		sDiff := new(big.Int).Sub(a.Signature.S, b.Signature.S)
+		sDiff.Mod(sDiff, pub.Q)
+		sDiffInv := new(big.Int).ModInverse(sDiff, pub.Q)
+		if sDiffInv == nil {
+			// Same message signed twice, which tells us nothing.
+			continue
+		}
+
+		k := new(big.Int).Sub(ha, hb)
+		k.Mul(k, sDiffInv)
+		k.Mod(k, pub.Q)
+
+		priv, ok := dsaKeyFromNonce(pub, ha, a.Signature, k)
+		if ok {
+			return priv, nil
+		}
+	}
+
+	return dsa.PrivateKey{}, fmt.Errorf("No pair of signatures with repeated nonce found")
+}
+
+// dsaKeyFromNonce recovers the private key given the digest h of the signed
+// message, the signature and the nonce. It returns false if the resulting key
+// does not match the public key.
+func dsaKeyFromNonce(pub dsa.PublicKey, h *big.Int, sig dsa.Signature, k *big.Int) (dsa.PrivateKey, bool) {
+	rInv := new(big.Int).ModInverse(sig.R, pub.Q)
+	if rInv == nil {
+		return dsa.PrivateKey{}, false
+	}
+
+	x := new(big.Int).Mul(sig.S, k)
+	x.Sub(x, h)
+	x.Mul(x, rInv)
+	x.Mod(x, pub.Q)
+
+	priv := dsa.NewPrivateKey(pub.Parameters, x)
+	if priv.Y.Cmp(pub.Y) != 0 {
+		return dsa.PrivateKey{}, false
+	}
+
+	return priv, true
+}
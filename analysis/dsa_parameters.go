@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dsa"
+)
+
+// DSAMagicSignature forges a signature which is valid for any message, if the
+// verifier can be tricked into using g = p + 1 as generator.
+//
+// With g = p + 1 = 1 mod p, the verifier's v = (g^u1 * y^u2 mod p) mod q
+// reduces to (y^u2 mod p) mod q, independent of the message. Choosing an
+// arbitrary z, the signature:
+//
+//	r = (y^z mod p) mod q
+//	s = r * z^-1 mod q
+//
+// results in u2 = r * s^-1 = z, and hence v = r.
+//
+// The public key must contain the tampered parameters, and its y may be any
+// value, such as the victim's genuine public key.
+func DSAMagicSignature(pub dsa.PublicKey, z *big.Int) (dsa.Signature, error) {
+	gExpected := new(big.Int).Add(pub.P, big.NewInt(1))
+	if pub.G.Cmp(gExpected) != 0 {
+		return dsa.Signature{}, fmt.Errorf("Generator must be p + 1")
+	}
+
+	zInv := new(big.Int).ModInverse(z, pub.Q)
+	if zInv == nil {
+		return dsa.Signature{}, fmt.Errorf("z = %v not invertible modulo q", z)
+	}
+
+	r := new(big.Int).Exp(pub.Y, z, pub.P)
+	r.Mod(r, pub.Q)
+
+	s := new(big.Int).Mul(r, zInv)
+	s.Mod(s, pub.Q)
+
+	return dsa.Signature{R: r, S: s}, nil
+}
+
+// DSAZeroSignature forges a signature which is valid for any message, if the
+// verifier can be tricked into using g = 0 as generator, and does not reject
+// signatures with r = 0.
+//
+// With g = 0, the verifier's v = (g^u1 * y^u2 mod p) mod q is zero for any
+// u1 != 0, so r = 0 matches for any message and any public key. Any
+// invertible s will do, we pick s = 1.
+func DSAZeroSignature() dsa.Signature {
+	return dsa.Signature{R: big.NewInt(0), S: big.NewInt(1)}
+}
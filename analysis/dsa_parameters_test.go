@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"crypto"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/dsa"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSAMagicSignature(t *testing.T) {
+	priv, err := dsa.GenerateKey(dsa.DefaultParameters())
+	assert.Nil(t, err)
+
+	pub := priv.PublicKey
+	pub.G = new(big.Int).Add(pub.P, big.NewInt(1))
+
+	sig, err := DSAMagicSignature(pub, big.NewInt(1337))
+	assert.Nil(t, err)
+
+	verifier := oracle.DSAVerifier{}
+	assert.True(t, verifier.Verify(pub, []byte("Hello, world"), sig))
+	assert.True(t, verifier.Verify(pub, []byte("Goodbye, world"), sig))
+
+	verifier.ValidateParameters = true
+	assert.False(t, verifier.Verify(pub, []byte("Hello, world"), sig))
+
+	// Only works with the tampered generator
+	_, err = DSAMagicSignature(priv.PublicKey, big.NewInt(1337))
+	assert.Error(t, err)
+}
+
+func TestDSAZeroSignature(t *testing.T) {
+	params := dsa.DefaultParameters()
+	params.G = big.NewInt(0)
+
+	// Keys and signatures generated under g = 0 have r = 0 ...
+	priv, err := dsa.GenerateKey(params)
+	assert.Nil(t, err)
+	_, err = priv.SignWithNonce(crypto.SHA1, []byte("Hello, world"), big.NewInt(42))
+	assert.Error(t, err)
+
+	// ... and a sloppy verifier accepts those for any message.
+	pub, err := dsa.GenerateKey(dsa.DefaultParameters())
+	assert.Nil(t, err)
+	pub.G = big.NewInt(0)
+
+	sig := DSAZeroSignature()
+	verifier := oracle.DSAVerifier{}
+	assert.True(t, verifier.Verify(pub.PublicKey, []byte("Hello, world"), sig))
+	assert.True(t, verifier.Verify(pub.PublicKey, []byte("Goodbye, world"), sig))
+
+	// Neither a careful verifier nor the regular verification do.
+	assert.False(t, pub.Verify(crypto.SHA1, []byte("Hello, world"), sig))
+	verifier.ValidateParameters = true
+	assert.False(t, verifier.Verify(pub.PublicKey, []byte("Hello, world"), sig))
+}
@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/dsa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSAKeyFromSmallNonce(t *testing.T) {
+	y, _ := new(big.Int).SetString(
+		"84ad4719d044495496a3201c8ff484feb45b962e7302e56a392aee4"+
+			"abab3e4bdebf2955b4736012f21a08084056b19bcd7fee56048e004"+
+			"e44984e2f411788efdc837a0d2e5abb7b555039fd243ac01f0fb2ed"+
+			"1dec568280ce678e931868d23eb095fde9d3779191b8c0299d6e07b"+
+			"bb283e6633451e535c45513b2d33c99ea17",
+		16,
+	)
+	r, _ := new(big.Int).SetString("548099063082341131477253921760299949438196259240", 10)
+	s, _ := new(big.Int).SetString("857042759984254168557880549501802188789837994940", 10)
+
+	pub := dsa.PublicKey{Parameters: dsa.DefaultParameters(), Y: y}
+	msg := []byte("For those that envy a MC it can be hazardous to your health\nSo be friendly, a matter of life and death, just like a etch-a-sketch\n")
+
+	priv, err := DSAKeyFromSmallNonce(
+		pub, crypto.SHA1, msg, dsa.Signature{R: r, S: s},
+		big.NewInt(0), big.NewInt(1<<16),
+	)
+	assert.Nil(t, err)
+
+	fingerprint := sha1.Sum([]byte(priv.X.Text(16)))
+	assert.Equal(t, "0954edd5e0afe5542a4adf012611a91912a3ec16", hex.EncodeToString(fingerprint[:]))
+}
+
+func TestDSAKeyFromSmallNonceOutOfRange(t *testing.T) {
+	priv, err := dsa.GenerateKey(dsa.DefaultParameters())
+	assert.Nil(t, err)
+
+	msg := []byte("Hello world")
+	sig, err := priv.SignWithNonce(crypto.SHA256, msg, big.NewInt(5000))
+	assert.Nil(t, err)
+
+	_, err = DSAKeyFromSmallNonce(priv.PublicKey, crypto.SHA256, msg, sig, big.NewInt(0), big.NewInt(1000))
+	assert.Error(t, err)
+
+	recovered, err := DSAKeyFromSmallNonce(priv.PublicKey, crypto.SHA256, msg, sig, big.NewInt(4000), big.NewInt(6000))
+	assert.Nil(t, err)
+	assert.Equal(t, priv.X, recovered.X)
+}
+
+func TestDSAKeyFromRepeatedNonce(t *testing.T) {
+	priv, err := dsa.GenerateKey(dsa.DefaultParameters())
+	assert.Nil(t, err)
+
+	texts := []string{
+		"Listen up, it's time to take flight",
+		"The nonce is random, or so we hope",
+		"Another message signed by a careless signer",
+		"And a fourth one for good measure",
+	}
+	nonces := []int64{1234567, 7654321, 1234567, 42}
+
+	msgs := make([]DSASignedMessage, len(texts))
+	for i, text := range texts {
+		sig, err := priv.SignWithNonce(crypto.SHA1, []byte(text), big.NewInt(nonces[i]))
+		assert.Nil(t, err)
+
+		msgs[i] = DSASignedMessage{Msg: []byte(text), Signature: sig}
+	}
+
+	assert.Equal(t, [][2]int{{0, 2}}, FindRepeatedDSANonces(msgs))
+
+	recovered, err := DSAKeyFromRepeatedNonce(priv.PublicKey, crypto.SHA1, msgs)
+	assert.Nil(t, err)
+	assert.Equal(t, priv.X, recovered.X)
+
+	// Without the repeated nonce, there is nothing to be found.
+	_, err = DSAKeyFromRepeatedNonce(priv.PublicKey, crypto.SHA1, msgs[:2])
+	assert.Error(t, err)
+}
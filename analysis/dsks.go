@@ -0,0 +1,283 @@
+package analysis
+
+import (
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dsa"
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/padding"
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/Lavode/cryptopals/rsa"
+)
+
+// ECDSADuplicateKey creates a new key pair under which the given ECDSA
+// signature of the message is valid as well, by choosing a new generator.
+//
+// A verifier checks that the x-coordinate of R = u1 * G + u2 * Q matches r,
+// where u1 = H(m) * s^-1 and u2 = r * s^-1. Picking a random private key d'
+// and setting:
+//
+//	t = u1 + u2 * d' mod n
+//	G' = t^-1 * R
+//	Q' = d' * G'
+//
+// yields u1 * G' + u2 * Q' = t * G' = R, so the signature verifies under
+// the new key pair. This works as long as the verifier does not fix the
+// generator, which it must to bind a signature to a single public key.
+func ECDSADuplicateKey(pub ec.PublicKey, hash crypto.Hash, msg []byte, sig ec.Signature) (ec.PrivateKey, error) {
+	return ECDSADuplicateKeyFrom(nil, pub, hash, msg, sig)
+}
+
+// ECDSADuplicateKeyFrom creates a new key pair as ECDSADuplicateKey() does,
+// reading its private key from the given source of randomness. If random is
+// nil, a CSPRNG is used.
+func ECDSADuplicateKeyFrom(random io.Reader, pub ec.PublicKey, hash crypto.Hash, msg []byte, sig ec.Signature) (ec.PrivateKey, error) {
+	if !pub.Verify(hash, msg, sig) {
+		return ec.PrivateKey{}, fmt.Errorf("Signature does not verify under original key")
+	}
+
+	h, err := dsa.Digest(hash, msg, pub.N)
+	if err != nil {
+		return ec.PrivateKey{}, err
+	}
+	r := pub.R(h, sig)
+
+	w := new(big.Int).ModInverse(sig.S, pub.N)
+	u1 := new(big.Int).Mul(h, w)
+	u1.Mod(u1, pub.N)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, pub.N)
+
+	for {
+		d, err := rand.Int(randutil.Reader(random), new(big.Int).Sub(pub.N, big.NewInt(1)))
+		if err != nil {
+			return ec.PrivateKey{}, fmt.Errorf("Error generating private key: %v", err)
+		}
+		d.Add(d, big.NewInt(1))
+
+		t := new(big.Int).Mul(u2, d)
+		t.Add(t, u1)
+		t.Mod(t, pub.N)
+		if t.Sign() == 0 {
+			continue
+		}
+
+		curve := pub.Curve
+		curve.G = curve.ScalarMult(r, new(big.Int).ModInverse(t, pub.N))
+
+		return ec.NewPrivateKey(curve, d), nil
+	}
+}
+
+// rsaSmoothPrimeBound is the exclusive upper bound of the prime factors of
+// p - 1 and q - 1 used by RSADuplicateKey().
+const rsaSmoothPrimeBound = 1 << 12
+
+// RSADuplicateKey creates a new key pair under which the given RSA signature
+// of the message, using PKCS#1 v1.5 signature padding, is valid as well.
+//
+// With pad(m) the padded message, the new key pair is chosen such that
+// s^e' = pad(m) mod N'. For this, N' = p * q is built of primes p and q such
+// that p - 1 and q - 1 are smooth, and s generates the multiplicative groups
+// modulo p and q. The discrete logarithms of pad(m) to the base s modulo p
+// and q are then easy to find with the Pohlig-Hellman algorithm, and combine
+// into e' with the Chinese remainder theorem.
+//
+// The odd prime factors of p - 1 and q - 1 are distinct, so they only share
+// the factor 2. As both discrete logarithms must be invertible for e' to be a
+// valid exponent, they are odd and thus agree modulo 2.
+func RSADuplicateKey(pub rsa.PublicKey, hash crypto.Hash, msg []byte, sig []byte) (rsa.PrivateKey, error) {
+	return RSADuplicateKeyFrom(nil, pub, hash, msg, sig)
+}
+
+// RSADuplicateKeyFrom creates a new key pair as RSADuplicateKey() does,
+// reading the choice of prime factors from the given source of randomness. If
+// random is nil, a CSPRNG is used.
+func RSADuplicateKeyFrom(random io.Reader, pub rsa.PublicKey, hash crypto.Hash, msg []byte, sig []byte) (rsa.PrivateKey, error) {
+	if !pub.Verify(hash, msg, sig) {
+		return rsa.PrivateKey{}, fmt.Errorf("Signature does not verify under original key")
+	}
+
+	padded, err := padding.PKCS1v15SignaturePad(hash, msg, pub.Size())
+	if err != nil {
+		return rsa.PrivateKey{}, err
+	}
+	m := new(big.Int).SetBytes(padded)
+	s := new(big.Int).SetBytes(sig)
+
+	bits := pub.N.BitLen()
+	pool := smallPrimes(1<<8, rsaSmoothPrimeBound)
+	one := big.NewInt(1)
+
+	for attempt := 0; attempt < 100; attempt++ {
+		used := make(map[int64]bool)
+
+		p, ep, err := rsaSmoothPrime(random, bits-bits/2, pool, used, s, m)
+		if err != nil {
+			return rsa.PrivateKey{}, err
+		}
+
+		q, eq, err := rsaSmoothPrime(random, bits/2, pool, used, s, m)
+		if err != nil {
+			return rsa.PrivateKey{}, err
+		}
+
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits || n.Cmp(s) <= 0 || n.Cmp(m) <= 0 {
+			continue
+		}
+
+		pMinusOne := new(big.Int).Sub(p, one)
+		qHalf := new(big.Int).Sub(q, one)
+		qHalf.Rsh(qHalf, 1)
+
+		e, lambda, err := crt(
+			[]*big.Int{ep, new(big.Int).Mod(eq, qHalf)},
+			[]*big.Int{pMinusOne, qHalf},
+		)
+		if err != nil {
+			return rsa.PrivateKey{}, err
+		}
+
+		d := new(big.Int).ModInverse(e, lambda)
+		if d == nil {
+			return rsa.PrivateKey{}, fmt.Errorf("Public exponent %v not invertible", e)
+		}
+
+		return rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: e},
+			D:         d,
+			P:         p,
+			Q:         q,
+		}, nil
+	}
+
+	return rsa.PrivateKey{}, fmt.Errorf("Unable to find modulus of %d bits", bits)
+}
+
+// rsaSmoothPrime returns a prime p of the given size, such that p - 1 is
+// twice a product of distinct primes of the pool which are not yet used, as
+// well as x = log_s(y) mod p - 1.
+//
+// The base s must generate the multiplicative group modulo p, and x must be
+// invertible modulo p - 1. The prime factors of p - 1 are marked as used, and
+// picked at random by reading from random.
+func rsaSmoothPrime(random io.Reader, bits int, pool []*big.Int, used map[int64]bool, s, y *big.Int) (*big.Int, *big.Int, error) {
+	one := big.NewInt(1)
+
+	for attempt := 0; attempt < 1000000; attempt++ {
+		factors := []*big.Int{big.NewInt(2)}
+		picked := make(map[int64]bool)
+		pMinusOne := big.NewInt(2)
+
+		for pMinusOne.BitLen() < bits {
+			i, err := rand.Int(randutil.Reader(random), big.NewInt(int64(len(pool))))
+			if err != nil {
+				return nil, nil, fmt.Errorf("Error picking prime factor: %v", err)
+			}
+
+			r := pool[i.Int64()]
+			if used[r.Int64()] || picked[r.Int64()] {
+				continue
+			}
+
+			picked[r.Int64()] = true
+			factors = append(factors, r)
+			pMinusOne.Mul(pMinusOne, r)
+		}
+
+		p := new(big.Int).Add(pMinusOne, one)
+		if p.BitLen() != bits || !p.ProbablyPrime(20) {
+			continue
+		}
+
+		generator := true
+		for _, r := range factors {
+			exp := new(big.Int).Div(pMinusOne, r)
+			if new(big.Int).Exp(s, exp, p).Cmp(one) == 0 {
+				generator = false
+				break
+			}
+		}
+		if !generator {
+			continue
+		}
+
+		x, err := pohligHellman(s, y, p, factors)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if new(big.Int).GCD(nil, nil, x, pMinusOne).Cmp(one) != 0 {
+			continue
+		}
+
+		for r := range picked {
+			used[r] = true
+		}
+
+		return p, x, nil
+	}
+
+	return nil, nil, fmt.Errorf("Unable to find %d-bit prime with smooth order", bits)
+}
+
+// pohligHellman solves y = g^x mod p for x modulo p - 1, where p - 1 is the
+// product of the given distinct primes, using the Pohlig-Hellman algorithm.
+//
+// For each factor r, raising both sides to the power of (p - 1) / r moves
+// them into the subgroup of order r, where x mod r is found by brute force.
+func pohligHellman(g, y, p *big.Int, factors []*big.Int) (*big.Int, error) {
+	order := new(big.Int).Sub(p, big.NewInt(1))
+	residues := make([]*big.Int, len(factors))
+
+	for i, r := range factors {
+		exp := new(big.Int).Div(order, r)
+		gr := new(big.Int).Exp(g, exp, p)
+		yr := new(big.Int).Exp(y, exp, p)
+
+		acc := big.NewInt(1)
+		for k := big.NewInt(0); k.Cmp(r) < 0; k.Add(k, big.NewInt(1)) {
+			if acc.Cmp(yr) == 0 {
+				residues[i] = new(big.Int).Set(k)
+				break
+			}
+
+			acc.Mul(acc, gr)
+			acc.Mod(acc, p)
+		}
+
+		if residues[i] == nil {
+			return nil, fmt.Errorf("No discrete logarithm modulo %v", r)
+		}
+	}
+
+	x, _, err := crt(residues, factors)
+	return x, err
+}
+
+// smallPrimes returns all primes in [from, to), found with the sieve of
+// Eratosthenes.
+func smallPrimes(from, to int64) []*big.Int {
+	composite := make([]bool, to)
+	primes := make([]*big.Int, 0)
+
+	for i := int64(2); i < to; i++ {
+		if composite[i] {
+			continue
+		}
+
+		if i >= from {
+			primes = append(primes, big.NewInt(i))
+		}
+		for j := i * i; j < to; j += i {
+			composite[j] = true
+		}
+	}
+
+	return primes
+}
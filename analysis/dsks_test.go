@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"crypto"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/rsa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECDSADuplicateKey(t *testing.T) {
+	priv, err := ec.GenerateKey(ec.ToyCurve())
+	assert.Nil(t, err)
+
+	msg := []byte("I owe Eve 1 dollar")
+	sig, err := priv.Sign(crypto.SHA256, msg)
+	assert.Nil(t, err)
+
+	eve, err := ECDSADuplicateKey(priv.PublicKey, crypto.SHA256, msg, sig)
+	assert.Nil(t, err)
+	assert.False(t, eve.Q.Equal(priv.Q))
+	assert.True(t, eve.Verify(crypto.SHA256, msg, sig))
+
+	// Eve's key pair is a proper one
+	other, err := eve.Sign(crypto.SHA256, []byte("Something else"))
+	assert.Nil(t, err)
+	assert.True(t, eve.Verify(crypto.SHA256, []byte("Something else"), other))
+
+	_, err = ECDSADuplicateKey(priv.PublicKey, crypto.SHA256, []byte("I owe Eve 2 dollars"), sig)
+	assert.Error(t, err)
+}
+
+func TestRSADuplicateKey(t *testing.T) {
+	priv, err := rsa.GenerateKey(1024, rsa.DefaultExponent)
+	assert.Nil(t, err)
+
+	msg := []byte("I owe Eve 1 dollar")
+	sig, err := priv.Sign(crypto.SHA256, msg)
+	assert.Nil(t, err)
+
+	eve, err := RSADuplicateKey(priv.PublicKey, crypto.SHA256, msg, sig)
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, eve.N.Cmp(priv.N))
+	assert.Equal(t, priv.N.BitLen(), eve.N.BitLen())
+	assert.True(t, eve.Verify(crypto.SHA256, msg, sig))
+
+	// Eve's key pair is a proper one
+	other, err := eve.Sign(crypto.SHA256, []byte("Something else"))
+	assert.Nil(t, err)
+	assert.True(t, eve.Verify(crypto.SHA256, []byte("Something else"), other))
+
+	_, err = RSADuplicateKey(priv.PublicKey, crypto.SHA256, []byte("I owe Eve 2 dollars"), sig)
+	assert.Error(t, err)
+}
+
+func TestPohligHellman(t *testing.T) {
+	// p - 1 = 2 * 3 * 5 * 7 * 11
+	p := big.NewInt(2311)
+	factors := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(7), big.NewInt(11)}
+
+	g := big.NewInt(0)
+	for candidate := int64(2); g.Sign() == 0; candidate++ {
+		c := big.NewInt(candidate)
+		generator := true
+		for _, r := range factors {
+			exp := new(big.Int).Div(big.NewInt(2310), r)
+			if new(big.Int).Exp(c, exp, p).Cmp(big.NewInt(1)) == 0 {
+				generator = false
+			}
+		}
+		if generator {
+			g = c
+		}
+	}
+
+	y := new(big.Int).Exp(g, big.NewInt(1234), p)
+	x, err := pohligHellman(g, y, p, factors)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1234), x.Int64())
+}
+
+func TestSmallPrimes(t *testing.T) {
+	primes := smallPrimes(10, 30)
+	assert.Equal(t, 6, len(primes))
+	assert.Equal(t, int64(11), primes[0].Int64())
+	assert.Equal(t, int64(29), primes[5].Int64())
+}
@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+// ECInvalidCurve recovers the private key of an ECDH responder on the given
+// curve, given that it does not check whether its peer's public key is on the
+// curve.
+//
+// The invalid curves must only differ from the responder's curve in B, and
+// their orders must be known. For each small prime factor r of their orders
+// up to the bound, an element h of order r on the invalid curve is sent as
+// public key. The responder's shared secret d * h then only depends on d mod
+// r, which is found by brute-forcing the MAC of the responder's message. The
+// residues are combined with the Chinese remainder theorem.
+//
+// It returns d mod M and M, where M is the product of the factors used.
+// Factors are used in ascending order until M exceeds N, in which case d mod
+// M is the private key itself.
+func ECInvalidCurve(curve ec.Curve, invalid []ec.Curve, or oracle.ECDHOracle, bound int64) (*big.Int, *big.Int, error) {
+	return ECInvalidCurveFrom(nil, curve, invalid, or, bound)
+}
+
+// ECInvalidCurveFrom recovers the private key as ECInvalidCurve() does,
+// reading the points sent from the given source of randomness. If random is
+// nil, a CSPRNG is used.
+func ECInvalidCurveFrom(random io.Reader, curve ec.Curve, invalid []ec.Curve, or oracle.ECDHOracle, bound int64) (*big.Int, *big.Int, error) {
+	type subgroup struct {
+		curve ec.Curve
+		r     *big.Int
+	}
+
+	subgroups := make([]subgroup, 0)
+	seen := make(map[int64]bool)
+	for _, c := range invalid {
+		for _, r := range smallFactors(c.Order, bound) {
+			if seen[r.Int64()] {
+				continue
+			}
+
+			seen[r.Int64()] = true
+			subgroups = append(subgroups, subgroup{curve: c, r: r})
+		}
+	}
+	sort.Slice(subgroups, func(i, j int) bool {
+		return subgroups[i].r.Cmp(subgroups[j].r) < 0
+	})
+
+	residues := make([]*big.Int, 0)
+	moduli := make([]*big.Int, 0)
+	product := big.NewInt(1)
+
+	for _, sub := range subgroups {
+		if product.Cmp(curve.N) > 0 {
+			break
+		}
+
+		h, err := ecPointOfOrder(random, sub.curve, sub.r)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		msg, mac, err := or.Respond(h)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error querying responder: %v", err)
+		}
+
+		residue, err := ecBruteForceMAC(sub.curve, h, sub.r, msg, mac)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		residues = append(residues, residue)
+		moduli = append(moduli, sub.r)
+		product.Mul(product, sub.r)
+	}
+
+	return crt(residues, moduli)
+}
+
+// ecPointOfOrder returns a random point of order r on the curve, where r must
+// be a prime factor of the curve's order, reading from random.
+//
+// If r divides the order more than once, the r-part of the group need not be
+// cyclic. A random point is thus first projected into the r-part by removing
+// all other factors, and then multiplied by r until its order is exactly r.
+func ecPointOfOrder(random io.Reader, c ec.Curve, r *big.Int) (ec.Point, error) {
+	cofactor := new(big.Int).Set(c.Order)
+	for new(big.Int).Mod(cofactor, r).Sign() == 0 {
+		cofactor.Div(cofactor, r)
+	}
+
+	for {
+		p, err := c.RandomPointFrom(random)
+		if err != nil {
+			return ec.Point{}, err
+		}
+
+		h := c.ScalarMult(p, cofactor)
+		if h.IsInfinity() {
+			continue
+		}
+
+		for {
+			next := c.ScalarMult(h, r)
+			if next.IsInfinity() {
+				return h, nil
+			}
+			h = next
+		}
+	}
+}
+
+// ecBruteForceMAC finds k in [0, r) such that the MAC of the message under
+// the shared secret k * h is the given one.
+func ecBruteForceMAC(c ec.Curve, h ec.Point, r *big.Int, msg []byte, mac []byte) (*big.Int, error) {
+	secret := ec.Infinity()
+
+	for k := big.NewInt(0); k.Cmp(r) < 0; k.Add(k, big.NewInt(1)) {
+		if hmac.Equal(ec.MAC(c.Marshal(secret), msg), mac) {
+			return k, nil
+		}
+
+		secret = c.Add(secret, h)
+	}
+
+	return nil, fmt.Errorf("No residue modulo %v matches MAC", r)
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECInvalidCurve(t *testing.T) {
+	curve := ec.ToyCurve()
+	or := oracle.ECDHResponder{Curve: curve}
+
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	d, m, err := ECInvalidCurve(curve, ec.ToyInvalidCurves(), &or, 1<<16)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, m.Cmp(curve.N))
+
+	recovered := ec.NewPrivateKey(curve, d)
+	assert.True(t, pub.Q.Equal(recovered.Q))
+}
+
+func TestECInvalidCurveValidatingResponder(t *testing.T) {
+	curve := ec.ToyCurve()
+	or := oracle.ECDHResponder{Curve: curve, ValidatePeerKey: true}
+
+	_, _, err := ECInvalidCurve(curve, ec.ToyInvalidCurves(), &or, 1<<16)
+	assert.Error(t, err)
+}
@@ -0,0 +1,366 @@
+package analysis
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+// ECTwistResidues recovers the private key of an ECDH responder on the given
+// Montgomery curve modulo small factors of the order of the curve's quadratic
+// twist, given that it does not check whether its peer's u-coordinate is on
+// the curve.
+//
+// As the Montgomery ladder does not depend on B, it happily computes on the
+// twist, whose order - unlike the curve's - may have small factors. For each
+// odd prime factor r of the twist's order up to the bound, which divides it
+// exactly once, the u-coordinate of a point h of order r on the twist is sent
+// as public key. The responder's shared secret d * h then only depends on d
+// mod r. As the ladder only yields u-coordinates, d * h and -d * h can not be
+// told apart, so brute-forcing the MAC of the responder's message only
+// yields d mod r up to its sign.
+//
+// The sign of each new residue relative to the previous ones is then fixed by
+// sending a point of order M * r, with M the product of the previous factors,
+// and checking which of the two combinations of residues matches the MAC.
+//
+// It returns x and M such that d = ±x mod M. Factors are used in ascending
+// order until M exceeds N.
+func ECTwistResidues(curve ec.MontgomeryCurve, or oracle.MontgomeryECDHOracle, bound int64) (*big.Int, *big.Int, error) {
+	return ECTwistResiduesFrom(nil, curve, or, bound)
+}
+
+// ECTwistResiduesFrom recovers the private key modulo small factors as
+// ECTwistResidues() does, reading the points sent from the given source of
+// randomness. If random is nil, a CSPRNG is used.
+func ECTwistResiduesFrom(random io.Reader, curve ec.MontgomeryCurve, or oracle.MontgomeryECDHOracle, bound int64) (*big.Int, *big.Int, error) {
+	primes := make([]*big.Int, 0)
+	for _, r := range smallFactors(curve.TwistOrder, bound) {
+		square := new(big.Int).Mul(r, r)
+		if r.Bit(0) == 0 || new(big.Int).Mod(curve.TwistOrder, square).Sign() == 0 {
+			continue
+		}
+
+		primes = append(primes, r)
+	}
+
+	x := big.NewInt(0)
+	modulus := big.NewInt(1)
+	used := make([]*big.Int, 0)
+
+	for _, r := range primes {
+		if modulus.Cmp(curve.N) > 0 {
+			break
+		}
+
+		h, err := ecTwistPointOfOrder(random, curve, []*big.Int{r})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		msg, mac, err := or.Respond(h)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error querying responder: %v", err)
+		}
+
+		k, err := ecTwistBruteForceMAC(curve, h, r, msg, mac)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		used = append(used, r)
+		if len(used) == 1 {
+			x, modulus = k, new(big.Int).Set(r)
+			continue
+		}
+
+		// Fix the sign of k relative to x with a point of order M * r
+		h, err = ecTwistPointOfOrder(random, curve, used)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		msg, mac, err = or.Respond(h)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error querying responder: %v", err)
+		}
+
+		found := false
+		for _, candidate := range []*big.Int{k, new(big.Int).Sub(r, k)} {
+			combined, m, err := crt([]*big.Int{x, candidate}, []*big.Int{modulus, r})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			secret := curve.Ladder(h, combined)
+			if hmac.Equal(ec.MAC(curve.Marshal(secret), msg), mac) {
+				x, modulus = combined, m
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, nil, fmt.Errorf("Neither sign of residue modulo %v matches MAC", r)
+		}
+	}
+
+	return x, modulus, nil
+}
+
+// ECTwistAttack recovers the private key of an ECDH responder on the given
+// Montgomery curve, with u-coordinate pub of its public key, given that it
+// does not check whether its peer's u-coordinate is on the curve.
+//
+// It first recovers the private key d up to its sign modulo the product M of
+// small factors of the twist's order with ECTwistResidues(). The remaining
+// bits are then found with Pollard's kangaroo algorithm on the curve's
+// Weierstrass form, as in ECKangarooFromResidue().
+//
+// Lifting pub to a point Q yields either d * G or -d * G, and the residue is
+// only known up to its sign, so the kangaroo search has four candidates for
+// the residue of the scalar of Q modulo M: ±x and N ± x. As the tame
+// kangaroo does not depend on Q, it is shared among them.
+//
+// As the ladder can not tell d and N - d apart, either may be returned. It
+// also returns the number of group operations of the kangaroo search.
+func ECTwistAttack(curve ec.MontgomeryCurve, pub *big.Int, or oracle.MontgomeryECDHOracle, bound int64, jumps KangarooJumps) (*big.Int, int, error) {
+	return ECTwistAttackFrom(nil, curve, pub, or, bound, jumps)
+}
+
+// ECTwistAttackFrom recovers the private key as ECTwistAttack() does, reading
+// the points sent from the given source of randomness. If random is nil, a
+// CSPRNG is used.
+func ECTwistAttackFrom(random io.Reader, curve ec.MontgomeryCurve, pub *big.Int, or oracle.MontgomeryECDHOracle, bound int64, jumps KangarooJumps) (*big.Int, int, error) {
+	x, modulus, err := ECTwistResiduesFrom(random, curve, or, bound)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	q, err := curve.Lift(pub)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	w := curve.Weierstrass()
+	y := curve.ToWeierstrass(q)
+
+	g := w.ScalarBaseMult(modulus)
+	b := new(big.Int).Sub(w.N, big.NewInt(1))
+	b.Div(b, modulus)
+
+	if len(jumps.Sizes) == 0 {
+		jumps = DefaultKangarooJumps(b)
+	}
+	steps := ecKangarooSteps(w, g, jumps)
+
+	xT, yT, ops := ecKangarooTame(w, g, b, steps, jumps)
+
+	candidates := []*big.Int{
+		x,
+		new(big.Int).Sub(modulus, x),
+		new(big.Int).Sub(w.N, x),
+		new(big.Int).Add(w.N, x),
+	}
+	for _, candidate := range candidates {
+		candidate.Mod(candidate, modulus)
+
+		y2 := w.Add(y, w.Neg(w.ScalarBaseMult(candidate)))
+		xW, n, ok := ecKangarooWild(w, y2, b, xT, yT, steps, jumps)
+		ops += n
+		if !ok {
+			continue
+		}
+
+		d := new(big.Int).Add(b, xT)
+		d.Sub(d, xW)
+		d.Mul(d, modulus)
+		d.Add(d, candidate)
+		d.Mod(d, w.N)
+
+		if curve.Ladder(curve.G.X, d).Cmp(pub) == 0 {
+			return d, ops, nil
+		}
+	}
+
+	return nil, ops, fmt.Errorf("Kangaroos escaped for all candidate residues")
+}
+
+// ECKangaroo solves y = x * g on the curve for x in the interval [a, b],
+// using Pollard's kangaroo algorithm, as described in Kangaroo(). It returns
+// x and the number of group operations it took.
+//
+// The jump function is applied to the x-coordinate of the kangaroos'
+// positions.
+func ECKangaroo(c ec.Curve, g, y ec.Point, a, b *big.Int, jumps KangarooJumps) (*big.Int, int, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 {
+		return nil, 0, fmt.Errorf("Interval [%v, %v] is empty", a, b)
+	}
+
+	if len(jumps.Sizes) == 0 {
+		jumps = DefaultKangarooJumps(width)
+	}
+	steps := ecKangarooSteps(c, g, jumps)
+
+	// Shifting the interval to [0, b - a] allows to reuse the tame
+	// kangaroo's trap between different a.
+	y2 := c.Add(y, c.Neg(c.ScalarMult(g, a)))
+
+	xT, yT, ops := ecKangarooTame(c, g, width, steps, jumps)
+	xW, n, ok := ecKangarooWild(c, y2, width, xT, yT, steps, jumps)
+	ops += n
+	if !ok {
+		return nil, ops, fmt.Errorf("Wild kangaroo escaped, discrete logarithm not in [%v, %v] or unlucky jump function", a, b)
+	}
+
+	x := new(big.Int).Add(b, xT)
+	return x.Sub(x, xW), ops, nil
+}
+
+// ECKangarooFromResidue solves y = x * G on the curve, given x modulo some
+// modulus, like KangarooFromResidue().
+func ECKangarooFromResidue(c ec.Curve, y ec.Point, residue, modulus *big.Int, jumps KangarooJumps) (*big.Int, int, error) {
+	g := c.ScalarBaseMult(modulus)
+	y2 := c.Add(y, c.Neg(c.ScalarBaseMult(new(big.Int).Mod(residue, c.N))))
+
+	b := new(big.Int).Sub(c.N, big.NewInt(1))
+	b.Div(b, modulus)
+
+	m, ops, err := ECKangaroo(c, g, y2, big.NewInt(0), b, jumps)
+	if err != nil {
+		return nil, ops, err
+	}
+
+	x := m.Mul(m, modulus)
+	return x.Add(x, residue), ops, nil
+}
+
+// ecKangarooTame lets a tame kangaroo jump from b * g, and returns the
+// distance it travelled, the trap it left where it stopped, and the number
+// of jumps.
+func ecKangarooTame(c ec.Curve, g ec.Point, b *big.Int, steps []ec.Point, jumps KangarooJumps) (*big.Int, ec.Point, int) {
+	mean := kangarooMean(jumps)
+	n := new(big.Int).Mul(mean, big.NewInt(4))
+	ops := 0
+
+	xT := big.NewInt(0)
+	yT := c.ScalarMult(g, b)
+	for i := big.NewInt(0); i.Cmp(n) < 0; i.Add(i, big.NewInt(1)) {
+		j := ecKangarooIndex(yT, jumps)
+		xT.Add(xT, jumps.Sizes[j])
+		yT = c.Add(yT, steps[j])
+		ops++
+	}
+
+	return xT, yT, ops
+}
+
+// ecKangarooWild lets a wild kangaroo jump from y until it either lands in
+// the trap at yT, or has passed it. It returns the distance it travelled,
+// the number of jumps, and whether it was caught.
+func ecKangarooWild(c ec.Curve, y ec.Point, width, xT *big.Int, yT ec.Point, steps []ec.Point, jumps KangarooJumps) (*big.Int, int, bool) {
+	limit := new(big.Int).Add(width, xT)
+	ops := 0
+
+	xW := big.NewInt(0)
+	yW := y
+	for xW.Cmp(limit) <= 0 {
+		if yW.Equal(yT) {
+			return xW, ops, true
+		}
+
+		j := ecKangarooIndex(yW, jumps)
+		xW.Add(xW, jumps.Sizes[j])
+		yW = c.Add(yW, steps[j])
+		ops++
+	}
+
+	return nil, ops, false
+}
+
+// ecKangarooIndex applies the jump function to the point's x-coordinate.
+func ecKangarooIndex(p ec.Point, jumps KangarooJumps) int {
+	if p.IsInfinity() {
+		return 0
+	}
+
+	return jumps.Index(p.X)
+}
+
+// ecKangarooSteps returns s * g for each jump size s.
+func ecKangarooSteps(c ec.Curve, g ec.Point, jumps KangarooJumps) []ec.Point {
+	steps := make([]ec.Point, len(jumps.Sizes))
+	for i, size := range jumps.Sizes {
+		steps[i] = c.ScalarMult(g, size)
+	}
+
+	return steps
+}
+
+// ecTwistPointOfOrder returns the u-coordinate of a random point on the
+// twist, whose order is the product of the given primes, reading from random.
+// Each of them must divide the twist's order exactly once.
+func ecTwistPointOfOrder(random io.Reader, c ec.MontgomeryCurve, primes []*big.Int) (*big.Int, error) {
+	order := big.NewInt(1)
+	for _, r := range primes {
+		order.Mul(order, r)
+	}
+	cofactor := new(big.Int).Div(c.TwistOrder, order)
+
+	for {
+		u, err := c.RandomTwistPointFrom(random)
+		if err != nil {
+			return nil, err
+		}
+
+		h := c.Ladder(u, cofactor)
+
+		// The point must not be of lower order
+		ok := h.Sign() != 0
+		for _, r := range primes {
+			if !ok {
+				break
+			}
+			ok = c.Ladder(h, new(big.Int).Div(order, r)).Sign() != 0
+		}
+
+		if ok {
+			return h, nil
+		}
+	}
+}
+
+// ecTwistBruteForceMAC finds k in [0, r/2] such that the MAC of the message
+// under the u-coordinate of k * h is the given one, where h is the
+// u-coordinate of a point of odd order r. The multiples of h are enumerated
+// with differential additions.
+func ecTwistBruteForceMAC(c ec.MontgomeryCurve, h *big.Int, r *big.Int, msg []byte, mac []byte) (*big.Int, error) {
+	// The point at infinity maps to 0
+	prev := big.NewInt(0)
+	if hmac.Equal(ec.MAC(c.Marshal(prev), msg), mac) {
+		return big.NewInt(0), nil
+	}
+
+	cur := h
+	half := new(big.Int).Rsh(r, 1).Int64()
+	for k := int64(1); k <= half; k++ {
+		if hmac.Equal(ec.MAC(c.Marshal(cur), msg), mac) {
+			return big.NewInt(k), nil
+		}
+
+		var next *big.Int
+		if k == 1 {
+			next = c.Ladder(h, big.NewInt(2))
+		} else {
+			next = c.DifferentialAdd(cur, h, prev)
+		}
+		prev, cur = cur, next
+	}
+
+	return nil, fmt.Errorf("No residue modulo %v matches MAC", r)
+}
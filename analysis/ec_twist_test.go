@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECTwistResidues(t *testing.T) {
+	curve := ec.ToyMontgomeryCurve()
+	or := oracle.MontgomeryECDHResponder{Curve: curve}
+
+	// Factors 11, 107, 197 and 1621
+	x, m, err := ECTwistResidues(curve, &or, 1<<11)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11*107*197*1621), m.Int64())
+
+	// Factors 11, 107, 197, 1621 and 105143
+	x2, m2, err := ECTwistResidues(curve, &or, 1<<17)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11*107*197*1621*105143), m2.Int64())
+
+	// Both must agree up to the sign
+	reduced := new(big.Int).Mod(x2, m)
+	negated := new(big.Int).Sub(m, x)
+	assert.True(t, reduced.Cmp(x) == 0 || reduced.Cmp(negated) == 0)
+}
+
+func TestECTwistResiduesValidatingResponder(t *testing.T) {
+	curve := ec.ToyMontgomeryCurve()
+	or := oracle.MontgomeryECDHResponder{Curve: curve, ValidatePeerKey: true}
+
+	_, _, err := ECTwistResidues(curve, &or, 1<<11)
+	assert.Error(t, err)
+}
+
+func TestECTwistAttack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping kangaroo search over 40 bits in short mode")
+	}
+
+	curve := ec.ToyMontgomeryCurve()
+	or := oracle.MontgomeryECDHResponder{Curve: curve}
+
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	d, _, err := ECTwistAttack(curve, pub.U, &or, 1<<22, KangarooJumps{})
+	assert.Nil(t, err)
+	assert.Equal(t, pub.U, curve.Ladder(curve.G.X, d))
+}
+
+func TestECKangaroo(t *testing.T) {
+	c := ec.ToyCurve()
+
+	x := big.NewInt(1234567)
+	y := c.ScalarBaseMult(x)
+
+	found, _, err := ECKangaroo(c, c.G, y, big.NewInt(1000000), big.NewInt(1<<21), KangarooJumps{})
+	assert.Nil(t, err)
+	assert.Equal(t, x, found)
+
+	_, _, err = ECKangaroo(c, c.G, y, big.NewInt(0), big.NewInt(1<<12), KangarooJumps{})
+	assert.Error(t, err)
+}
+
+func TestECKangarooFromResidue(t *testing.T) {
+	c := ec.ToyCurve()
+
+	priv, err := ec.GenerateKey(c)
+	assert.Nil(t, err)
+
+	// All but about 24 bits are known
+	modulus := new(big.Int).Lsh(big.NewInt(1), 100)
+	residue := new(big.Int).Mod(priv.D, modulus)
+
+	d, _, err := ECKangarooFromResidue(c, priv.Q, residue, modulus, KangarooJumps{})
+	assert.Nil(t, err)
+	assert.Equal(t, priv.D, d)
+}
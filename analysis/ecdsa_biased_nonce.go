@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"crypto"
+	"fmt"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dsa"
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/lattice"
+)
+
+// ECDSASignedMessage is a message along with its ECDSA signature.
+type ECDSASignedMessage struct {
+	Msg       []byte
+	Signature ec.Signature
+}
+
+// ECDSAKeyFromBiasedNonces recovers the private key from a batch of signed
+// messages, whose nonces all had their lowest bits bits set to zero.
+//
+// With k = 2^l * b, each signature gives:
+//
+//	b = k / 2^l = (H(m) + d * r) / (s * 2^l) = d * t - u mod n
+//
+// with t = r / (s * 2^l) and u = -H(m) / (s * 2^l), where 0 <= b < n / 2^l
+// is small. Shifting u by c = n / 2^(l+1) centers b around zero, which gains
+// another bit per signature: |b - c| <= n / 2^(l+1).
+//
+// Recovering d from such relations is an instance of the hidden number
+// problem, which is solved by finding a short vector in the lattice spanned
+// by the rows of:
+//
+//	n   0   ... 0   0     0
+//	0   n   ... 0   0     0
+//	        ...
+//	0   0   ... n   0     0
+//	t1  t2  ... tk  ct    0
+//	u1  u2  ... uk  0     cu
+//
+// with ct = 1/2^(l+1) and cu = n/2^(l+1), and u_i shifted by c. It contains
+// the vector (b1 - c, ..., bk - c, d/2^(l+1), -n/2^(l+1)), which - given
+// enough signatures - is unusually short, and thus likely part of the basis
+// reduced with lattice.LLL().
+//
+// Each signature leaks a bit more than l bits of the private key, so a few
+// more than log2(n) / l signatures are needed.
+func ECDSAKeyFromBiasedNonces(pub ec.PublicKey, hash crypto.Hash, msgs []ECDSASignedMessage, bits uint) (ec.PrivateKey, error) {
+	if len(msgs) == 0 {
+		return ec.PrivateKey{}, fmt.Errorf("Need at least one signed message")
+	}
+
+	n := pub.N
+	k := len(msgs)
+	scale := new(big.Int).Lsh(big.NewInt(1), bits)
+	center := new(big.Int).Rsh(n, bits+1)
+
+	basis := make([]lattice.Vector, k+2)
+	for i := 0; i < k; i++ {
+		basis[i] = lattice.NewVector(k + 2)
+		basis[i][i].SetInt(n)
+	}
+
+	rowT := lattice.NewVector(k + 2)
+	rowU := lattice.NewVector(k + 2)
+	for i, msg := range msgs {
+		h, err := dsa.Digest(hash, msg.Msg, n)
+		if err != nil {
+			return ec.PrivateKey{}, err
+		}
+
+		// 1 / (s * 2^l)
+		inv := new(big.Int).Mul(msg.Signature.S, scale)
+		if inv.ModInverse(inv, n) == nil {
+			return ec.PrivateKey{}, fmt.Errorf("Signature %d has s not invertible modulo n", i)
+		}
+
+		t := new(big.Int).Mul(msg.Signature.R, inv)
+		rowT[i].SetInt(t.Mod(t, n))
+
+		u := new(big.Int).Mul(h, inv)
+		u.Neg(u)
+		u.Add(u, center)
+		rowU[i].SetInt(u.Mod(u, n))
+	}
+
+	ct := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(scale, 1))
+	cu := new(big.Rat).SetFrac(n, new(big.Int).Lsh(scale, 1))
+	rowT[k].Set(ct)
+	rowU[k+1].Set(cu)
+	basis[k] = rowT
+	basis[k+1] = rowU
+
+	reduced := lattice.LLL(basis, nil)
+
+	negCu := new(big.Rat).Neg(cu)
+	for _, row := range reduced {
+		var sign int64
+		switch {
+		case row[k+1].Cmp(negCu) == 0:
+			sign = 1
+		case row[k+1].Cmp(cu) == 0:
+			sign = -1
+		default:
+			continue
+		}
+
+		// d / 2^(l+1) = row[k], up to the sign of the row
+		scaled := new(big.Rat).Mul(row[k], new(big.Rat).SetInt(new(big.Int).Lsh(scale, 1)))
+		if !scaled.IsInt() {
+			continue
+		}
+
+		d := new(big.Int).Mul(scaled.Num(), big.NewInt(sign))
+		d.Mod(d, n)
+
+		priv := ec.NewPrivateKey(pub.Curve, d)
+		if priv.Q.Equal(pub.Q) {
+			return priv, nil
+		}
+	}
+
+	return ec.PrivateKey{}, fmt.Errorf("No short vector revealed the private key, more signatures might be required")
+}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"crypto"
+	"fmt"
+	"testing"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func biasedSignatures(t *testing.T, or *oracle.ECDSABiasedSigner, count int) []ECDSASignedMessage {
+	msgs := make([]ECDSASignedMessage, count)
+	for i := range msgs {
+		msg := []byte(fmt.Sprintf("Message number %d", i))
+		sig, err := or.Sign(msg)
+		assert.Nil(t, err)
+
+		msgs[i] = ECDSASignedMessage{Msg: msg, Signature: sig}
+	}
+
+	return msgs
+}
+
+func TestECDSAKeyFromBiasedNonces(t *testing.T) {
+	or := oracle.ECDSABiasedSigner{Curve: ec.P256(), Bits: 12}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msgs := biasedSignatures(t, &or, 24)
+
+	priv, err := ECDSAKeyFromBiasedNonces(pub, crypto.SHA256, msgs, 12)
+	assert.Nil(t, err)
+	assert.True(t, priv.Q.Equal(pub.Q))
+}
+
+func TestECDSAKeyFromBiasedNoncesChallenge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping reduction of 38-dimensional lattice in short mode")
+	}
+
+	// The challenge's 8 bits of bias require about three dozen
+	// signatures for a 256-bit curve.
+	or := oracle.ECDSABiasedSigner{Curve: ec.P256(), Bits: 8}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msgs := biasedSignatures(t, &or, 36)
+
+	priv, err := ECDSAKeyFromBiasedNonces(pub, crypto.SHA256, msgs, 8)
+	assert.Nil(t, err)
+	assert.True(t, priv.Q.Equal(pub.Q))
+}
+
+func TestECDSAKeyFromBiasedNoncesTooFewSignatures(t *testing.T) {
+	or := oracle.ECDSABiasedSigner{Curve: ec.P256(), Bits: 8}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msgs := biasedSignatures(t, &or, 5)
+
+	_, err = ECDSAKeyFromBiasedNonces(pub, crypto.SHA256, msgs, 8)
+	assert.Error(t, err)
+
+	_, err = ECDSAKeyFromBiasedNonces(pub, crypto.SHA256, []ECDSASignedMessage{}, 8)
+	assert.Error(t, err)
+}
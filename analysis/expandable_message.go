@@ -0,0 +1,189 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/toyhash"
+)
+
+// ExpandableMessage is a set of messages of any length in [k, k + 2^k - 1]
+// blocks, which all lead from the same initial state to the same final state
+// of an iterated hash.
+//
+// It consists of k pairs of colliding messages. The i-th pair consists of a
+// single block, and of 2^(k-1-i) + 1 blocks. Choosing either message of each
+// pair allows to pick the message's length bit by bit.
+type ExpandableMessage struct {
+	Short [][]byte
+	Long  [][]byte
+	State uint32
+}
+
+// NewExpandableMessage generates an expandable message of k pairs, starting
+// at the given state.
+//
+// As described by Kelsey and Schneier in "Second Preimages on n-bit Hash
+// Functions for Much Less than 2^n Work", each pair is found with a birthday
+// attack between a single block and a final block which follows 2^(k-1-i)
+// dummy blocks.
+func NewExpandableMessage(h *toyhash.Hash, state uint32, k int) (ExpandableMessage, error) {
+	return NewExpandableMessageFrom(nil, h, state, k)
+}
+
+// NewExpandableMessageFrom generates an expandable message as
+// NewExpandableMessage() does, reading candidate blocks from the given source
+// of randomness. If r is nil, a CSPRNG is used.
+func NewExpandableMessageFrom(r io.Reader, h *toyhash.Hash, state uint32, k int) (ExpandableMessage, error) {
+	em := ExpandableMessage{State: state}
+
+	for i := 0; i < k; i++ {
+		dummy := make([]byte, (1<<(k-1-i))*toyhash.BlockSize)
+		dummyState := h.Iterate(em.State, dummy)
+
+		short, long, next, err := toyHashCollisionBetween(h, em.State, dummyState, randomBlocks(r))
+		if err != nil {
+			return ExpandableMessage{}, err
+		}
+
+		em.Short = append(em.Short, short)
+		em.Long = append(em.Long, append(dummy, long...))
+		em.State = next
+	}
+
+	return em, nil
+}
+
+// MinBlocks returns the length of the shortest message in blocks.
+func (em ExpandableMessage) MinBlocks() int {
+	return len(em.Short)
+}
+
+// MaxBlocks returns the length of the longest message in blocks.
+func (em ExpandableMessage) MaxBlocks() int {
+	return len(em.Short) + 1<<len(em.Short) - 1
+}
+
+// Message returns the message of the given length in blocks.
+func (em ExpandableMessage) Message(blocks int) ([]byte, error) {
+	if blocks < em.MinBlocks() || blocks > em.MaxBlocks() {
+		return []byte{}, fmt.Errorf("Length must be in [%d, %d] blocks, but was %d", em.MinBlocks(), em.MaxBlocks(), blocks)
+	}
+
+	k := len(em.Short)
+	extra := blocks - k
+
+	msg := make([]byte, 0, blocks*toyhash.BlockSize)
+	for i := 0; i < k; i++ {
+		if (extra>>(k-1-i))&1 == 1 {
+			msg = append(msg, em.Long[i]...)
+		} else {
+			msg = append(msg, em.Short[i]...)
+		}
+	}
+
+	return msg, nil
+}
+
+// SecondPreimage finds a message which is distinct from, but of the same
+// length as, the given message, and has the same hash.
+//
+// An expandable message is generated first, followed by a search for a
+// bridge block which leads from its final state to any of the message's
+// intermediate states. The forged message then consists of the expandable
+// message expanded to the right length, the bridge block, and the remainder
+// of the original message. As the lengths match, so does the padding.
+//
+// For a message of 2^k blocks and a state of b bits this requires about
+// k * 2^(b/2) + 2^(b-k) calls to the compression function, rather than the
+// 2^b of a brute-force search.
+func SecondPreimage(h *toyhash.Hash, msg []byte) ([]byte, error) {
+	return SecondPreimageFrom(nil, h, msg)
+}
+
+// SecondPreimageFrom finds a second preimage as SecondPreimage() does,
+// reading candidate blocks from the given source of randomness. If r is nil,
+// a CSPRNG is used.
+func SecondPreimageFrom(r io.Reader, h *toyhash.Hash, msg []byte) ([]byte, error) {
+	blocks := len(msg) / toyhash.BlockSize
+
+	k := 1
+	for 1<<k < blocks {
+		k++
+	}
+
+	// Intermediate states after processing i blocks, which can be reached
+	// by an expandable message of i-1 blocks followed by a bridge block.
+	targets := make(map[uint32]int)
+	state := h.IV
+	for i := 1; i <= blocks; i++ {
+		state = h.Compress(state, msg[(i-1)*toyhash.BlockSize:i*toyhash.BlockSize])
+		if i-1 >= k {
+			targets[state] = i
+		}
+	}
+
+	if len(targets) == 0 {
+		return []byte{}, fmt.Errorf("Message must be at least %d blocks long", k+1)
+	}
+
+	em, err := NewExpandableMessageFrom(r, h, h.IV, k)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	bridges := randomBlocks(r)
+	for {
+		bridge, err := bridges()
+		if err != nil {
+			return []byte{}, err
+		}
+
+		i, ok := targets[h.Compress(em.State, bridge)]
+		if !ok {
+			continue
+		}
+
+		prefix, err := em.Message(i - 1)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		forged := append(prefix, bridge...)
+		return append(forged, msg[i*toyhash.BlockSize:]...), nil
+	}
+}
+
+// toyHashCollisionBetween finds two blocks a and b which lead from the states
+// s1 and s2 respectively to the same state under the hash's compression
+// function, and returns them along with the resulting state. Candidate blocks
+// are taken from the generator.
+func toyHashCollisionBetween(h *toyhash.Hash, s1, s2 uint32, blocks blockGenerator) ([]byte, []byte, uint32, error) {
+	seen1 := make(map[uint32][]byte)
+	seen2 := make(map[uint32][]byte)
+
+	for {
+		a, err := blocks()
+		if err != nil {
+			return []byte{}, []byte{}, 0, err
+		}
+		b, err := blocks()
+		if err != nil {
+			return []byte{}, []byte{}, 0, err
+		}
+
+		next := h.Compress(s1, a)
+		other, ok := seen2[next]
+		if ok {
+			return a, other, next, nil
+		}
+		seen1[next] = a
+
+		next = h.Compress(s2, b)
+		other, ok = seen1[next]
+		if ok {
+			return other, b, next, nil
+		}
+		seen2[next] = b
+	}
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/Lavode/cryptopals/toyhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandableMessage(t *testing.T) {
+	h, err := toyhash.New(16, 0x1234)
+	assert.Nil(t, err)
+
+	em, err := NewExpandableMessage(h, h.IV, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, em.MinBlocks())
+	assert.Equal(t, 19, em.MaxBlocks())
+
+	for blocks := em.MinBlocks(); blocks <= em.MaxBlocks(); blocks++ {
+		msg, err := em.Message(blocks)
+		assert.Nil(t, err)
+
+		assert.Equal(t, blocks*toyhash.BlockSize, len(msg))
+		assert.Equal(t, em.State, h.Iterate(h.IV, msg))
+	}
+
+	_, err = em.Message(3)
+	assert.Error(t, err)
+	_, err = em.Message(20)
+	assert.Error(t, err)
+}
+
+func TestSecondPreimage(t *testing.T) {
+	h, err := toyhash.New(20, 0x1234)
+	assert.Nil(t, err)
+
+	// Unaligned message of 2^10 blocks and a bit
+	msg := make([]byte, (1<<10)*toyhash.BlockSize+7)
+	_, err = rand.Read(msg)
+	assert.Nil(t, err)
+
+	forged, err := SecondPreimage(h, msg)
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, msg, forged)
+	assert.Equal(t, len(msg), len(forged))
+	assert.Equal(t, h.Sum(msg), h.Sum(forged))
+
+	_, err = SecondPreimage(h, msg[:toyhash.BlockSize])
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/bitwise"
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/gf128"
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+// GCMMessage is a ciphertext along with its additional data and tag, as
+// produced by AES-GCM.
+type GCMMessage struct {
+	// Nonce is only required by attacks which submit modified messages
+	// for verification.
+	Nonce      []byte
+	AAD        []byte
+	Ciphertext []byte
+	Tag        []byte
+}
+
+// GCMRecoverAuthenticationKey recovers candidates for the GHASH key H from
+// two or more messages which were encrypted under the same key and nonce.
+//
+// The tag of a message is t = GHASH(H, A, C) + s, where s = E(K, J0) only
+// depends on key and nonce. With X_1, ..., X_n the blocks processed by GHASH,
+// H is thus a root of:
+//
+//	g(y) = X_1 * y^n + ... + X_n * y + t + s
+//
+// As s is shared between messages, it cancels out in the difference g_1 - g_2
+// of two such polynomials, which is known and has H as a root. Its roots are
+// found by factoring it. With more than two messages, the gcd of all
+// differences is factored, which rules out spurious roots.
+//
+// The candidates returned are the roots of this polynomial. Any of them
+// satisfies all given messages, so more messages or a forgery are needed to
+// single out H if more than one is returned.
+func GCMRecoverAuthenticationKey(msgs []GCMMessage) ([]gf128.Element, error) {
+	return GCMRecoverAuthenticationKeyFrom(nil, msgs)
+}
+
+// GCMRecoverAuthenticationKeyFrom recovers candidates for the GHASH key as
+// GCMRecoverAuthenticationKey() does, reading from the given source of
+// randomness while factoring. If r is nil, a CSPRNG is used.
+func GCMRecoverAuthenticationKeyFrom(r io.Reader, msgs []GCMMessage) ([]gf128.Element, error) {
+	if len(msgs) < 2 {
+		return []gf128.Element{}, fmt.Errorf("Need at least two messages, got %d", len(msgs))
+	}
+
+	first := gcmPolynomial(msgs[0])
+	var f gf128.Polynomial
+	for _, msg := range msgs[1:] {
+		diff := first.Add(gcmPolynomial(msg))
+		if diff.IsZero() {
+			// Identical messages do not add any information
+			continue
+		}
+
+		if f.IsZero() {
+			f = diff
+		} else {
+			f = gf128.GCD(f, diff)
+		}
+	}
+
+	if f.IsZero() {
+		return []gf128.Element{}, fmt.Errorf("Need at least two distinct messages")
+	}
+
+	roots, err := f.RootsFrom(r)
+	if err != nil {
+		return []gf128.Element{}, err
+	}
+
+	// Test each candidate against all messages. The gcd guarantees this
+	// holds, but it is cheap to make sure.
+	candidates := make([]gf128.Element, 0, len(roots))
+	for _, h := range roots {
+		s := gcmMask(h, msgs[0])
+
+		consistent := true
+		for _, msg := range msgs[1:] {
+			if gcmMask(h, msg) != s {
+				consistent = false
+				break
+			}
+		}
+
+		if consistent {
+			candidates = append(candidates, h)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return []gf128.Element{}, fmt.Errorf("No candidate for H is consistent with all messages")
+	}
+
+	return candidates, nil
+}
+
+// GCMForgeTag calculates the tag of the given ciphertext and additional
+// data, which is valid under the key and nonce of the known message, given
+// the GHASH key h.
+//
+// The mask s = E(K, J0) of the tag is recovered as t + GHASH(h, A, C) from
+// the known message, which then yields the forged tag as GHASH(h, A', C') + s.
+// If the known tag is truncated, so is the forged one.
+func GCMForgeTag(h gf128.Element, known GCMMessage, aad []byte, ctxt []byte) []byte {
+	s := gcmMask(h, known)
+
+	return cipher.GHASH(h, aad, ctxt).Add(s).Bytes()[:len(known.Tag)]
+}
+
+// GCMNonceReuseForgery forges a tag for the given ciphertext and additional
+// data, given two or more messages encrypted under the same key and nonce.
+//
+// Candidates for the GHASH key are recovered with
+// GCMRecoverAuthenticationKey(), and a forgery with each is submitted to the
+// decryption oracle until one is accepted. The GHASH key, the valid tag and
+// the decryption of the forged ciphertext are returned.
+func GCMNonceReuseForgery(msgs []GCMMessage, aad []byte, ctxt []byte, or oracle.AEADDecryptionOracle) (gf128.Element, []byte, []byte, error) {
+	return GCMNonceReuseForgeryFrom(nil, msgs, aad, ctxt, or)
+}
+
+// GCMNonceReuseForgeryFrom forges a tag as GCMNonceReuseForgery() does,
+// reading from the given source of randomness while factoring. If r is nil, a
+// CSPRNG is used.
+func GCMNonceReuseForgeryFrom(r io.Reader, msgs []GCMMessage, aad []byte, ctxt []byte, or oracle.AEADDecryptionOracle) (gf128.Element, []byte, []byte, error) {
+	candidates, err := GCMRecoverAuthenticationKeyFrom(r, msgs)
+	if err != nil {
+		return gf128.Element{}, []byte{}, []byte{}, err
+	}
+
+	for _, h := range candidates {
+		tag := GCMForgeTag(h, msgs[0], aad, ctxt)
+
+		msg, err := or.Decrypt(ctxt, aad, tag)
+		if err == nil {
+			return h, tag, msg, nil
+		}
+	}
+
+	return gf128.Element{}, []byte{}, []byte{}, fmt.Errorf("None of %d candidates for H yielded a valid forgery", len(candidates))
+}
+
+// GCMRewriteCiphertext returns a ciphertext which decrypts to msg under the
+// same key and nonce as ctxt, given the known plaintext of ctxt. As GCM
+// encrypts in CTR mode, this is a matter of XORing in the keystream. The new
+// message must not be longer than the known one.
+func GCMRewriteCiphertext(ctxt []byte, known []byte, msg []byte) ([]byte, error) {
+	if len(known) != len(ctxt) {
+		return []byte{}, fmt.Errorf("Known plaintext of %d bytes does not match ciphertext of %d bytes", len(known), len(ctxt))
+	}
+
+	if len(msg) > len(known) {
+		return []byte{}, fmt.Errorf("Keystream of %d bytes insufficient for message of %d bytes", len(ctxt), len(msg))
+	}
+
+	keystream := bitwise.Xor(ctxt, known)
+
+	return bitwise.Xor(msg, keystream), nil
+}
+
+// gcmPolynomial returns the polynomial X_1 * y^n + ... + X_n * y + t of the
+// message, where X_i are the blocks processed by GHASH and t is its tag.
+func gcmPolynomial(msg GCMMessage) gf128.Polynomial {
+	blocks := make([]gf128.Element, 0)
+	for _, data := range [][]byte{msg.AAD, msg.Ciphertext} {
+		for i := 0; i < len(data); i += cipher.AESBlockSize {
+			end := i + cipher.AESBlockSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			blocks = append(blocks, gf128.FromBytes(data[i:end]))
+		}
+	}
+	blocks = append(blocks, cipher.GHASHLengthBlock(len(msg.AAD), len(msg.Ciphertext)))
+
+	n := len(blocks)
+	coefficients := make([]gf128.Element, n+1)
+	coefficients[0] = gf128.FromBytes(msg.Tag)
+	for i, block := range blocks {
+		coefficients[n-i] = block
+	}
+
+	return gf128.NewPolynomial(coefficients...)
+}
+
+// gcmMask returns the mask s = t + GHASH(h, A, C) of the message's tag.
+func gcmMask(h gf128.Element, msg GCMMessage) gf128.Element {
+	return gf128.FromBytes(msg.Tag).Add(cipher.GHASH(h, msg.AAD, msg.Ciphertext))
+}
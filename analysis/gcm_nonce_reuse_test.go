@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func gcmMessages(t *testing.T, gcm *cipher.AESGCM, msgs ...string) []GCMMessage {
+	out := make([]GCMMessage, len(msgs))
+	for i, msg := range msgs {
+		aad := []byte("header")
+		ctxt, tag, err := gcm.Encrypt([]byte(msg), aad)
+		assert.Nil(t, err)
+
+		out[i] = GCMMessage{AAD: aad, Ciphertext: ctxt, Tag: tag}
+	}
+
+	return out
+}
+
+func TestGCMRecoverAuthenticationKey(t *testing.T) {
+	key, err := cipher.NewKey()
+	assert.Nil(t, err)
+	gcm := cipher.AESGCM{Key: key, Nonce: make([]byte, cipher.AESGCMNonceSize)}
+	h, err := gcm.H()
+	assert.Nil(t, err)
+
+	msgs := gcmMessages(t, &gcm,
+		"Transfer 100 dollars to Alice, not to Eve.",
+		"Transfer 200 dollars to Bob.",
+		"Transfer 300 dollars to Carol, her account number is 1234.",
+	)
+
+	// Two messages might leave several candidates
+	candidates, err := GCMRecoverAuthenticationKey(msgs[:2])
+	assert.Nil(t, err)
+	assert.Contains(t, candidates, h)
+
+	candidates, err = GCMRecoverAuthenticationKey(msgs)
+	assert.Nil(t, err)
+	assert.Contains(t, candidates, h)
+
+	_, err = GCMRecoverAuthenticationKey(msgs[:1])
+	assert.Error(t, err)
+
+	_, err = GCMRecoverAuthenticationKey([]GCMMessage{msgs[0], msgs[0]})
+	assert.Error(t, err)
+
+	// Messages under different nonces share no common root
+	other := gcm
+	other.Nonce = []byte("Other nonce!")
+	unrelated := gcmMessages(t, &other, "Transfer 200 dollars to Bob.")
+	_, err = GCMRecoverAuthenticationKey([]GCMMessage{msgs[0], msgs[1], msgs[2], unrelated[0]})
+	assert.Error(t, err)
+}
+
+func TestGCMNonceReuseForgery(t *testing.T) {
+	or := oracle.GCMFixedNonce{}
+
+	known := []byte("Transfer 100 dollars to Alice.")
+	msgs := make([]GCMMessage, 0)
+	for _, msg := range [][]byte{known, []byte("Transfer 200 dollars to Bob.")} {
+		ctxt, tag, err := or.Encrypt(msg, []byte("header"))
+		assert.Nil(t, err)
+		msgs = append(msgs, GCMMessage{AAD: []byte("header"), Ciphertext: ctxt, Tag: tag})
+	}
+
+	forged, err := GCMRewriteCiphertext(msgs[0].Ciphertext, known, []byte("Transfer 999 dollars to Eve."))
+	assert.Nil(t, err)
+
+	_, tag, msg, err := GCMNonceReuseForgery(msgs, []byte("other header"), forged, &or)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Transfer 999 dollars to Eve."), msg)
+
+	decrypted, err := or.Decrypt(forged, []byte("other header"), tag)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	_, err = GCMRewriteCiphertext(msgs[0].Ciphertext, known, append(known, 'x'))
+	assert.Error(t, err)
+}
@@ -0,0 +1,261 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/gf128"
+	"github.com/Lavode/cryptopals/gf2"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// GCMTruncatedMACAttack recovers the GHASH key H from a single message whose
+// tag is truncated, given an oracle which verifies modified ciphertexts under
+// the message's nonce. This is Ferguson's attack on GCM with short tags. The
+// key is returned along with the number of oracle queries made.
+//
+// The ciphertext must consist of whole blocks. The blocks multiplied by
+// h^(2^i) in GHASH are modified by error blocks e_i, which changes the tag by
+// sum(e_i * h^(2^i)). As squaring is linear over GF(2), this is:
+//
+//	sum(M_(e_i) * S^i) * h = A_d * h
+//
+// with M_c the 128x128 matrix over GF(2) of multiplication by c, and S the
+// one of squaring. The entries of A_d are linear in the bits of the e_i. A
+// forgery succeeds if the first bits of A_d * h, as many as the tag has, are
+// zero.
+//
+// The errors are chosen from the kernel of the linear map which takes them to
+// the first N rows of A_d, so those are zero, and forgeries succeed with
+// probability 2^-(t - N) for a t bit tag. Each successful forgery then reveals
+// the remaining t - N rows of A_d to yield linear equations in the bits of h.
+//
+// With X a basis of the solutions of the equations gathered so far, h = X * h'
+// for some shorter h'. Zeroing rows of A_d * X rather than A_d thus takes
+// fewer constraints, so later forgeries zero more rows and succeed more
+// often. Once X has a single column, it is h.
+func GCMTruncatedMACAttack(msg GCMMessage, or oracle.GCMVerificationOracle) (gf128.Element, int, error) {
+	return GCMTruncatedMACAttackFrom(nil, msg, or)
+}
+
+// GCMTruncatedMACAttackFrom recovers the GHASH key H as
+// GCMTruncatedMACAttack() does, reading the error vectors from the given
+// source of randomness. If r is nil, a CSPRNG is used.
+func GCMTruncatedMACAttackFrom(r io.Reader, msg GCMMessage, or oracle.GCMVerificationOracle) (gf128.Element, int, error) {
+	if len(msg.Ciphertext)%cipher.AESBlockSize != 0 {
+		return gf128.Element{}, 0, fmt.Errorf("Ciphertext must consist of whole blocks, got %d bytes", len(msg.Ciphertext))
+	}
+	blocks := len(msg.Ciphertext) / cipher.AESBlockSize
+
+	tagBits := len(msg.Tag) * 8
+	if tagBits == 0 {
+		return gf128.Element{}, 0, fmt.Errorf("Tag must not be empty")
+	}
+
+	// Ciphertext block k is multiplied by h^(blocks + 1 - k), so the
+	// powers h^2, ..., h^(2^n) are available for 2^n <= blocks + 1.
+	n := 0
+	for 1<<(n+1) <= blocks+1 {
+		n++
+	}
+	if n < 2 {
+		return gf128.Element{}, 0, fmt.Errorf("Need at least 3 ciphertext blocks, got %d", blocks)
+	}
+
+	errorMatrices := gcmErrorMatrices(n)
+
+	equations := gf2.NewMatrix(0, 128)
+	x := gf2.Identity(128)
+	queries := 0
+
+	for x.Cols() > 1 {
+		d := x.Cols()
+
+		// Zeroing N rows of A_d * X takes N * d constraints on the
+		// 128 * n bits of the error blocks, which must leave a
+		// non-trivial kernel. Zeroing all t rows would reveal nothing.
+		zeroRows := (128*n - 1) / d
+		if zeroRows > tagBits-1 {
+			zeroRows = tagBits - 1
+		}
+
+		constraints := gf2.NewMatrix(zeroRows*d, len(errorMatrices))
+		for v, a := range errorMatrices {
+			rows := a.SubRows(0, zeroRows).Mul(x)
+			for r := 0; r < zeroRows; r++ {
+				for c := 0; c < d; c++ {
+					constraints.SetBit(r*d+c, v, rows.Bit(r, c))
+				}
+			}
+		}
+		kernel := constraints.Kernel()
+
+		var errorBits *gf2.Matrix
+		for {
+			var err error
+			errorBits, err = randomKernelVector(r, kernel)
+			if err != nil {
+				return gf128.Element{}, queries, err
+			}
+
+			forged := gcmApplyErrors(msg.Ciphertext, errorBits, n)
+
+			queries++
+			ok, err := or.Verify(msg.Nonce, forged, msg.AAD, msg.Tag)
+			if err != nil {
+				return gf128.Element{}, queries, fmt.Errorf("Error querying oracle: %v", err)
+			}
+
+			if ok {
+				break
+			}
+		}
+
+		// The first t rows of A_d * h are zero
+		ad := gf2.NewMatrix(128, 128)
+		for v, a := range errorMatrices {
+			if errorBits.Bit(v, 0) == 1 {
+				ad = ad.Add(a)
+			}
+		}
+
+		reduced, pivots := equations.AppendRows(ad.SubRows(0, tagBits)).ReducedRowEchelon()
+		equations = reduced.SubRows(0, len(pivots))
+		x = equations.Kernel()
+	}
+
+	if x.Cols() == 0 {
+		return gf128.Element{}, queries, fmt.Errorf("Equations gathered have no non-zero solution")
+	}
+
+	return gcmElementFromColumn(x.Column(0)), queries, nil
+}
+
+// gcmErrorMatrices returns the matrices M_(x^b) * S^i, for i in [1, n] and b
+// in [0, 128), at index 128 * (i - 1) + b.
+//
+// A_d is the sum of those for which bit b of error block e_i is set.
+func gcmErrorMatrices(n int) []*gf2.Matrix {
+	mul := make([]*gf2.Matrix, 128)
+	for b := range mul {
+		mul[b] = gcmMulMatrix(gcmBasisElement(b))
+	}
+
+	square := gf2.NewMatrix(128, 128)
+	for j := 0; j < 128; j++ {
+		gcmSetColumn(square, j, gcmBasisElement(j).Square())
+	}
+
+	out := make([]*gf2.Matrix, 0, 128*n)
+	power := gf2.Identity(128)
+	for i := 1; i <= n; i++ {
+		power = square.Mul(power)
+		for b := 0; b < 128; b++ {
+			out = append(out, mul[b].Mul(power))
+		}
+	}
+
+	return out
+}
+
+// gcmApplyErrors returns a copy of the ciphertext, with the error block
+// whose bits are at rows [128 * (i - 1), 128 * i) of errorBits added to the
+// block multiplied by h^(2^i), for i in [1, n].
+func gcmApplyErrors(ctxt []byte, errorBits *gf2.Matrix, n int) []byte {
+	out := make([]byte, len(ctxt))
+	copy(out, ctxt)
+	blocks := len(ctxt) / cipher.AESBlockSize
+
+	for i := 1; i <= n; i++ {
+		e := gf128.Zero()
+		for b := 0; b < 128; b++ {
+			if errorBits.Bit(128*(i-1)+b, 0) == 1 {
+				e = e.Add(gcmBasisElement(b))
+			}
+		}
+
+		k := blocks + 1 - (1 << i)
+		block := out[k*cipher.AESBlockSize : (k+1)*cipher.AESBlockSize]
+		copy(block, gf128.FromBytes(block).Add(e).Bytes())
+	}
+
+	return out
+}
+
+// randomKernelVector returns a random non-zero linear combination of the
+// columns of the kernel basis, reading from r.
+func randomKernelVector(r io.Reader, kernel *gf2.Matrix) (*gf2.Matrix, error) {
+	if kernel.Cols() == 0 {
+		return nil, fmt.Errorf("Kernel is trivial")
+	}
+
+	buf := make([]byte, kernel.Cols())
+	coefficients := gf2.NewMatrix(kernel.Cols(), 1)
+
+	for {
+		if _, err := io.ReadFull(randutil.Reader(r), buf); err != nil {
+			return nil, fmt.Errorf("Error generating coefficients: %v", err)
+		}
+
+		for i, b := range buf {
+			coefficients.SetBit(i, 0, uint(b))
+		}
+
+		v := kernel.Mul(coefficients)
+		if !v.IsZero() {
+			return v, nil
+		}
+	}
+}
+
+// gcmMulMatrix returns the matrix of multiplication by c, whose column j is
+// c * x^j.
+func gcmMulMatrix(c gf128.Element) *gf2.Matrix {
+	m := gf2.NewMatrix(128, 128)
+	for j := 0; j < 128; j++ {
+		gcmSetColumn(m, j, c.Mul(gcmBasisElement(j)))
+	}
+
+	return m
+}
+
+// gcmBasisElement returns x^j.
+func gcmBasisElement(j int) gf128.Element {
+	if j < 64 {
+		return gf128.Element{Hi: 1 << (63 - j)}
+	}
+
+	return gf128.Element{Lo: 1 << (127 - j)}
+}
+
+// gcmSetColumn sets column j of the matrix to the coefficients of e, with
+// row i holding the one of x^i.
+func gcmSetColumn(m *gf2.Matrix, j int, e gf128.Element) {
+	for i := 0; i < 128; i++ {
+		m.SetBit(i, j, gcmElementBit(e, i))
+	}
+}
+
+// gcmElementFromColumn returns the element whose coefficient of x^i is in
+// row i of the column vector.
+func gcmElementFromColumn(v *gf2.Matrix) gf128.Element {
+	e := gf128.Zero()
+	for i := 0; i < 128; i++ {
+		if v.Bit(i, 0) == 1 {
+			e = e.Add(gcmBasisElement(i))
+		}
+	}
+
+	return e
+}
+
+// gcmElementBit returns the coefficient of x^i of e.
+func gcmElementBit(e gf128.Element, i int) uint {
+	if i < 64 {
+		return uint(e.Hi>>(63-i)) & 1
+	}
+
+	return uint(e.Lo>>(127-i)) & 1
+}
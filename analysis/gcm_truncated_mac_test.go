@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/gf128"
+	"github.com/Lavode/cryptopals/gf2"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCMMulMatrix(t *testing.T) {
+	c := gf128.FromBytes([]byte("YELLOW SUBMARINE"))
+	y := gf128.FromBytes([]byte("PURPLE SUBMARINE"))
+
+	m := gcmMulMatrix(c)
+	column := gf2.NewMatrix(128, 1)
+	gcmSetColumn(column, 0, y)
+
+	v := m.Mul(column)
+	assert.Equal(t, c.Mul(y), gcmElementFromColumn(v))
+
+	// M_c * S^i * y = c * y^(2^i)
+	matrices := gcmErrorMatrices(3)
+	assert.Equal(t, 3*128, len(matrices))
+	x5 := gcmBasisElement(5)
+	v = matrices[2*128+5].Mul(column)
+	assert.Equal(t, x5.Mul(y.Square().Square().Square()), gcmElementFromColumn(v))
+}
+
+func TestGCMTruncatedMACAttack(t *testing.T) {
+	or := oracle.GCMTruncatedTag{TagSize: 2}
+
+	msg := make([]byte, 1023*cipher.AESBlockSize)
+	_, err := rand.Read(msg)
+	assert.Nil(t, err)
+
+	nonce, ctxt, tag, err := or.Encrypt(msg, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tag))
+
+	h, queries, err := GCMTruncatedMACAttack(GCMMessage{Nonce: nonce, Ciphertext: ctxt, Tag: tag}, &or)
+	assert.Nil(t, err)
+	t.Logf("Recovered H with %d queries", queries)
+
+	// With H known, tags of arbitrary ciphertexts can be forged
+	forged := []byte("Arbitrary ciphertext")
+	forgedTag := GCMForgeTag(h, GCMMessage{Ciphertext: ctxt, Tag: tag}, nil, forged)
+	ok, err := or.Verify(nonce, forged, nil, forgedTag)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	_, _, err = GCMTruncatedMACAttack(GCMMessage{Nonce: nonce, Ciphertext: ctxt[:17], Tag: tag}, &or)
+	assert.Error(t, err)
+}
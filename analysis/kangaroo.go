@@ -0,0 +1,399 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// KangarooJumps is the pseudorandom jump function of Pollard's kangaroo
+// algorithm. A kangaroo at element y jumps to y * g^s, where s is the jump
+// size with index Index(y).
+type KangarooJumps struct {
+	// Sizes are the exponents by which kangaroos may jump.
+	Sizes []*big.Int
+	// Index maps an element to the index of the jump size which a
+	// kangaroo at said element takes. It must be deterministic.
+	Index func(y *big.Int) int
+}
+
+// PowerOfTwoJumps returns a jump function with the k jump sizes 2^0 to
+// 2^(k-1), which picks the jump size by the element modulo k.
+func PowerOfTwoJumps(k int) KangarooJumps {
+	sizes := make([]*big.Int, k)
+	for i := range sizes {
+		sizes[i] = new(big.Int).Lsh(big.NewInt(1), uint(i))
+	}
+
+	return KangarooJumps{
+		Sizes: sizes,
+		Index: func(y *big.Int) int {
+			words := y.Bits()
+			if len(words) == 0 {
+				return 0
+			}
+
+			return int(uint64(words[0]) % uint64(k))
+		},
+	}
+}
+
+// DefaultKangarooJumps returns power-of-two jumps for an interval of the
+// given width, such that the mean jump size is about sqrt(width) / 2.
+func DefaultKangarooJumps(width *big.Int) KangarooJumps {
+	target := new(big.Int).Sqrt(width)
+	target.Rsh(target, 1)
+
+	k := 1
+	for {
+		// The mean of 2^0 to 2^(k-1) is (2^k - 1) / k
+		mean := new(big.Int).Lsh(big.NewInt(1), uint(k))
+		mean.Sub(mean, big.NewInt(1))
+		mean.Div(mean, big.NewInt(int64(k)))
+
+		if mean.Cmp(target) >= 0 {
+			return PowerOfTwoJumps(k)
+		}
+		k++
+	}
+}
+
+// Kangaroo solves y = g^x mod p for x in the interval [a, b], using Pollard's
+// kangaroo algorithm. It returns x and the number of group operations it took.
+//
+// A tame kangaroo starts at g^b and jumps a fixed number of times, leaving a
+// trap where it stops. A wild kangaroo then starts at y and jumps until it
+// either lands in the trap - in which case its distance travelled reveals x -
+// or has passed it. As the jumps only depend on the current element, the
+// wild kangaroo follows in the tame one's footsteps as soon as they land on
+// the same element.
+//
+// If jumps has no jump sizes, DefaultKangarooJumps() are used. The number of
+// jumps of the tame kangaroo is four times the mean jump size. This takes
+// about O(sqrt(b - a)) group operations, and is not guaranteed to succeed.
+func Kangaroo(g, y, p, a, b *big.Int, jumps KangarooJumps) (*big.Int, int, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 {
+		return nil, 0, fmt.Errorf("Interval [%v, %v] is empty", a, b)
+	}
+
+	if len(jumps.Sizes) == 0 {
+		jumps = DefaultKangarooJumps(width)
+	}
+	steps := kangarooSteps(g, p, jumps)
+	ops := 0
+
+	mean := kangarooMean(jumps)
+	n := new(big.Int).Mul(mean, big.NewInt(4))
+
+	// Tame kangaroo
+	xT := big.NewInt(0)
+	yT := new(big.Int).Exp(g, b, p)
+	for i := big.NewInt(0); i.Cmp(n) < 0; i.Add(i, big.NewInt(1)) {
+		j := jumps.Index(yT)
+		xT.Add(xT, jumps.Sizes[j])
+		yT.Mul(yT, steps[j])
+		yT.Mod(yT, p)
+		ops++
+	}
+
+	// Wild kangaroo
+	limit := new(big.Int).Add(width, xT)
+	xW := big.NewInt(0)
+	yW := new(big.Int).Set(y)
+	for xW.Cmp(limit) <= 0 {
+		if yW.Cmp(yT) == 0 {
+			x := new(big.Int).Add(b, xT)
+			return x.Sub(x, xW), ops, nil
+		}
+
+		j := jumps.Index(yW)
+		xW.Add(xW, jumps.Sizes[j])
+		yW.Mul(yW, steps[j])
+		yW.Mod(yW, p)
+		ops++
+	}
+
+	return nil, ops, fmt.Errorf("Wild kangaroo escaped, discrete logarithm not in [%v, %v] or unlucky jump function", a, b)
+}
+
+// KangarooParallel solves y = g^x mod p for x in the interval [a, b], like
+// Kangaroo(), but runs a tame and a wild kangaroo in each of the given number
+// of goroutines, or one per CPU if it is not positive.
+//
+// As described by van Oorschot and Wiener in "Parallel Collision Search with
+// Cryptanalytic Applications", kangaroos start at random points of the
+// interval's upper half (tame) or at y times a random offset (wild), and
+// report every distinguished element - one whose lowest bits are zero - which
+// they land on. As soon as a tame and a wild kangaroo have reported the same
+// element, x is known. A kangaroo which lands on a distinguished element
+// previously reported by one of its own kind would follow in its footsteps
+// forever, so it starts over at a new random point.
+//
+// It gives up after a generous number of group operations without a
+// collision.
+func KangarooParallel(g, y, p, a, b *big.Int, jumps KangarooJumps, workers int, distinguishedBits uint) (*big.Int, int, error) {
+	return KangarooParallelFrom(nil, g, y, p, a, b, jumps, workers, distinguishedBits)
+}
+
+// KangarooParallelFrom solves y = g^x mod p as KangarooParallel() does,
+// reading the kangaroos' starting points from the given source of randomness.
+// If r is nil, a CSPRNG is used. As it is shared between goroutines, r must be
+// safe for concurrent use, as those returned by randutil.NewSeeded() are.
+func KangarooParallelFrom(r io.Reader, g, y, p, a, b *big.Int, jumps KangarooJumps, workers int, distinguishedBits uint) (*big.Int, int, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 {
+		return nil, 0, fmt.Errorf("Interval [%v, %v] is empty", a, b)
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if len(jumps.Sizes) == 0 {
+		jumps = DefaultKangarooJumps(width)
+	}
+
+	search := kangarooSearch{
+		g:     g,
+		y:     y,
+		p:     p,
+		a:     a,
+		width: width,
+		jumps: jumps,
+		steps: kangarooSteps(g, p, jumps),
+		mask:  1<<distinguishedBits - 1,
+		rand:  randutil.Reader(r),
+		traps: make(map[string]kangarooTrap),
+	}
+
+	// Expected number of operations is about 4 * sqrt(width) in total,
+	// plus the distance of each kangaroo to its first distinguished element.
+	budget := new(big.Int).Sqrt(width)
+	budget.Mul(budget, big.NewInt(64))
+	budget.Add(budget, big.NewInt(int64(64*workers)<<distinguishedBits))
+	if budget.IsInt64() {
+		search.budget = budget.Int64()
+	} else {
+		search.budget = 1<<63 - 1
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			search.run()
+		}()
+	}
+	wg.Wait()
+
+	if search.err != nil {
+		return nil, int(search.ops), search.err
+	}
+	if search.x == nil {
+		return nil, int(search.ops), fmt.Errorf("No collision after %d operations, discrete logarithm not in [%v, %v]", search.ops, a, b)
+	}
+
+	return search.x, int(search.ops), nil
+}
+
+// kangarooTrap is a distinguished element reported by a kangaroo, along with
+// the exponent (tame) or offset from x (wild) which leads to it.
+type kangarooTrap struct {
+	tame     bool
+	exponent *big.Int
+}
+
+// kangarooSearch is the state of a parallel kangaroo search shared between
+// goroutines.
+type kangarooSearch struct {
+	// ops and done are accessed atomically. ops must come first to be
+	// 64-bit aligned on 32-bit platforms.
+	ops  int64
+	done int32
+
+	g, y, p, a, width *big.Int
+	jumps             KangarooJumps
+	steps             []*big.Int
+	mask              uint64
+	budget            int64
+	rand              io.Reader
+
+	// traps, x and err are protected by the mutex
+	mu    sync.Mutex
+	traps map[string]kangarooTrap
+	x     *big.Int
+	err   error
+}
+
+// run alternately moves a tame and a wild kangaroo until the search is done.
+func (s *kangarooSearch) run() {
+	kangaroos := make([]*kangaroo, 2)
+	for i := range kangaroos {
+		k, err := s.newKangaroo(i == 0)
+		if err != nil {
+			s.fail(err)
+			return
+		}
+		kangaroos[i] = k
+	}
+
+	for atomic.LoadInt32(&s.done) == 0 {
+		if atomic.AddInt64(&s.ops, int64(len(kangaroos))) > s.budget {
+			atomic.StoreInt32(&s.done, 1)
+			return
+		}
+
+		for i, k := range kangaroos {
+			j := s.jumps.Index(k.y)
+			k.exponent.Add(k.exponent, s.jumps.Sizes[j])
+			k.y.Mul(k.y, s.steps[j])
+			k.y.Mod(k.y, s.p)
+
+			words := k.y.Bits()
+			if len(words) > 0 && uint64(words[0])&s.mask != 0 {
+				continue
+			}
+
+			if !s.report(k) {
+				continue
+			}
+
+			// Collided with a kangaroo of its own kind, so we'll
+			// start over elsewhere.
+			fresh, err := s.newKangaroo(k.tame)
+			if err != nil {
+				s.fail(err)
+				return
+			}
+			kangaroos[i] = fresh
+		}
+	}
+}
+
+// kangaroo is a single tame or wild kangaroo of a parallel search. The
+// exponent of tame kangaroos is their discrete logarithm, the one of wild
+// kangaroos the offset of their discrete logarithm from x.
+type kangaroo struct {
+	tame     bool
+	exponent *big.Int
+	y        *big.Int
+}
+
+// newKangaroo places a kangaroo at a random point.
+func (s *kangarooSearch) newKangaroo(tame bool) (*kangaroo, error) {
+	half := new(big.Int).Rsh(s.width, 1)
+	offset, err := rand.Int(s.rand, new(big.Int).Add(half, big.NewInt(1)))
+	if err != nil {
+		return nil, fmt.Errorf("Error generating starting point: %v", err)
+	}
+
+	if tame {
+		exponent := new(big.Int).Add(s.a, half)
+		exponent.Add(exponent, offset)
+
+		return &kangaroo{tame: true, exponent: exponent, y: new(big.Int).Exp(s.g, exponent, s.p)}, nil
+	}
+
+	y := new(big.Int).Exp(s.g, offset, s.p)
+	y.Mul(y, s.y)
+	y.Mod(y, s.p)
+
+	return &kangaroo{tame: false, exponent: offset, y: y}, nil
+}
+
+// report records the distinguished element which the kangaroo landed on. If
+// a kangaroo of the other kind landed on it before, the search is done. It
+// returns whether a kangaroo of the same kind landed on it before.
+func (s *kangarooSearch) report(k *kangaroo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := string(k.y.Bytes())
+	trap, ok := s.traps[key]
+	if !ok {
+		s.traps[key] = kangarooTrap{tame: k.tame, exponent: new(big.Int).Set(k.exponent)}
+		return false
+	}
+
+	if trap.tame == k.tame {
+		return true
+	}
+
+	if s.x == nil {
+		tame, wild := trap.exponent, k.exponent
+		if k.tame {
+			tame, wild = k.exponent, trap.exponent
+		}
+		s.x = new(big.Int).Sub(tame, wild)
+	}
+	atomic.StoreInt32(&s.done, 1)
+
+	return false
+}
+
+// fail aborts the search with the given error.
+func (s *kangarooSearch) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+	atomic.StoreInt32(&s.done, 1)
+}
+
+// KangarooFromResidue solves y = g^x mod p for x in [0, q), where g is of
+// order q, given that x = residue mod modulus, as recovered by e.g.
+// DHSubgroupConfinement().
+//
+// With x = residue + modulus * m, this reduces to solving
+//
+//	y * g^-residue = (g^modulus)^m
+//
+// for m in [0, (q - 1) / modulus] with Kangaroo().
+func KangarooFromResidue(g, y, p, q, residue, modulus *big.Int, jumps KangarooJumps) (*big.Int, int, error) {
+	g2 := new(big.Int).Exp(g, modulus, p)
+
+	y2 := new(big.Int).Sub(q, new(big.Int).Mod(residue, q))
+	y2.Exp(g, y2, p)
+	y2.Mul(y2, y)
+	y2.Mod(y2, p)
+
+	b := new(big.Int).Sub(q, big.NewInt(1))
+	b.Div(b, modulus)
+
+	m, ops, err := Kangaroo(g2, y2, p, big.NewInt(0), b, jumps)
+	if err != nil {
+		return nil, ops, err
+	}
+
+	x := m.Mul(m, modulus)
+	return x.Add(x, residue), ops, nil
+}
+
+// kangarooSteps returns g^s mod p for each jump size s.
+func kangarooSteps(g, p *big.Int, jumps KangarooJumps) []*big.Int {
+	steps := make([]*big.Int, len(jumps.Sizes))
+	for i, size := range jumps.Sizes {
+		steps[i] = new(big.Int).Exp(g, size, p)
+	}
+
+	return steps
+}
+
+// kangarooMean returns the mean jump size, rounded down.
+func kangarooMean(jumps KangarooJumps) *big.Int {
+	sum := big.NewInt(0)
+	for _, size := range jumps.Sizes {
+		sum.Add(sum, size)
+	}
+
+	return sum.Div(sum, big.NewInt(int64(len(jumps.Sizes))))
+}
@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/dh"
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKangaroo(t *testing.T) {
+	params := dh.KangarooParameters()
+
+	a := new(big.Int).Lsh(big.NewInt(1), 30)
+	b := new(big.Int).Add(a, big.NewInt(1<<24))
+	x, err := rand.Int(rand.Reader, big.NewInt(1<<24))
+	assert.Nil(t, err)
+	x.Add(x, a)
+	y := new(big.Int).Exp(params.G, x, params.P)
+
+	recovered, ops, err := Kangaroo(params.G, y, params.P, a, b, KangarooJumps{})
+	assert.Nil(t, err)
+	assert.Equal(t, x, recovered)
+	assert.Greater(t, ops, 0)
+	assert.Less(t, ops, 1<<16)
+}
+
+func TestKangarooChallenge(t *testing.T) {
+	params := dh.KangarooParameters()
+	y, _ := new(big.Int).SetString("7760073848032689505395005705677365876654629189298052775754597607446617558600394076764814236081991643094239886772481052254010323780165093955236429914607119", 10)
+
+	x, _, err := Kangaroo(params.G, y, params.P, big.NewInt(0), big.NewInt(1<<20), PowerOfTwoJumps(11))
+	assert.Nil(t, err)
+	assert.Equal(t, y, new(big.Int).Exp(params.G, x, params.P))
+}
+
+func TestKangarooOutsideInterval(t *testing.T) {
+	params := dh.KangarooParameters()
+	y := new(big.Int).Exp(params.G, big.NewInt(1<<20), params.P)
+
+	_, _, err := Kangaroo(params.G, y, params.P, big.NewInt(0), big.NewInt(1<<16), KangarooJumps{})
+	assert.Error(t, err)
+
+	_, _, err = Kangaroo(params.G, y, params.P, big.NewInt(1), big.NewInt(0), KangarooJumps{})
+	assert.Error(t, err)
+}
+
+func TestKangarooCustomJumps(t *testing.T) {
+	params := dh.KangarooParameters()
+	x := big.NewInt(123456)
+	y := new(big.Int).Exp(params.G, x, params.P)
+
+	// Jumps of sizes 1, 3, 5, ..., 127, picked by the element's low byte
+	sizes := make([]*big.Int, 64)
+	for i := range sizes {
+		sizes[i] = big.NewInt(int64(2*i + 1))
+	}
+	jumps := KangarooJumps{
+		Sizes: sizes,
+		Index: func(y *big.Int) int {
+			return int(y.Bits()[0]&0xff) % 64
+		},
+	}
+
+	recovered, _, err := Kangaroo(params.G, y, params.P, big.NewInt(0), big.NewInt(1<<18), jumps)
+	assert.Nil(t, err)
+	assert.Equal(t, x, recovered)
+}
+
+func TestKangarooParallel(t *testing.T) {
+	params := dh.KangarooParameters()
+
+	b := big.NewInt(1 << 28)
+	x, err := rand.Int(rand.Reader, b)
+	assert.Nil(t, err)
+	y := new(big.Int).Exp(params.G, x, params.P)
+
+	recovered, ops, err := KangarooParallel(params.G, y, params.P, big.NewInt(0), b, KangarooJumps{}, 4, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, x, recovered)
+	t.Logf("Parallel kangaroos took %d operations", ops)
+
+	// Not in interval
+	y = new(big.Int).Exp(params.G, big.NewInt(1<<20), params.P)
+	_, _, err = KangarooParallel(params.G, y, params.P, big.NewInt(0), big.NewInt(1<<12), KangarooJumps{}, 2, 2)
+	assert.Error(t, err)
+}
+
+func TestKangarooParallelFrom(t *testing.T) {
+	params := dh.KangarooParameters()
+
+	x := big.NewInt(123456789)
+	y := new(big.Int).Exp(params.G, x, params.P)
+	b := big.NewInt(1 << 28)
+
+	// With a single worker, a seeded source makes the search repeatable.
+	recovered, ops1, err := KangarooParallelFrom(randutil.NewSeeded(42), params.G, y, params.P, big.NewInt(0), b, KangarooJumps{}, 1, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, x, recovered)
+
+	_, ops2, err := KangarooParallelFrom(randutil.NewSeeded(42), params.G, y, params.P, big.NewInt(0), b, KangarooJumps{}, 1, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, ops1, ops2)
+}
+
+func TestKangarooFromResidue(t *testing.T) {
+	params := dh.KangarooParameters()
+
+	priv, err := dh.GenerateKey(params)
+	assert.Nil(t, err)
+
+	// All but 28 bits are known
+	modulus := new(big.Int).Lsh(big.NewInt(1), 100)
+	residue := new(big.Int).Mod(priv.X, modulus)
+
+	x, _, err := KangarooFromResidue(params.G, priv.Y, params.P, params.Q, residue, modulus, KangarooJumps{})
+	assert.Nil(t, err)
+	assert.Equal(t, priv.X, x)
+}
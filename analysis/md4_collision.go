@@ -0,0 +1,245 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/Lavode/cryptopals/md4"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// md4ConditionKind specifies what a bit of a chaining variable must be equal
+// to.
+type md4ConditionKind int
+
+const (
+	// md4Zero requires the bit to be 0.
+	md4Zero md4ConditionKind = iota
+	// md4One requires the bit to be 1.
+	md4One
+	// md4Prev requires the bit to be equal to the same bit of the
+	// previous chaining variable.
+	md4Prev
+	// md4Prev2 requires the bit to be equal to the same bit of the
+	// chaining variable before the previous one.
+	md4Prev2
+)
+
+// md4Condition is a sufficient condition on a bit of a chaining variable.
+// Bits are numbered from 1 to 32, as in Wang et al.'s paper.
+type md4Condition struct {
+	bit  uint
+	kind md4ConditionKind
+}
+
+// md4Round1Conditions are the sufficient conditions on the chaining
+// variables a1, d1, c1, b1, ..., b4 of the first round, as given in table 6
+// of Wang et al.'s paper.
+var md4Round1Conditions = [16][]md4Condition{
+	// a1
+	{{7, md4Prev}},
+	// d1
+	{{7, md4Zero}, {8, md4Prev}, {11, md4Prev}},
+	// c1
+	{{7, md4One}, {8, md4One}, {11, md4Zero}, {26, md4Prev}},
+	// b1
+	{{7, md4One}, {8, md4Zero}, {11, md4Zero}, {26, md4Zero}},
+	// a2
+	{{8, md4One}, {11, md4One}, {26, md4Zero}, {14, md4Prev}},
+	// d2
+	{{14, md4Zero}, {19, md4Prev}, {20, md4Prev}, {21, md4Prev}, {22, md4Prev}, {26, md4One}},
+	// c2
+	{{13, md4Prev}, {14, md4Zero}, {15, md4Prev}, {19, md4Zero}, {20, md4Zero}, {21, md4One}, {22, md4Zero}},
+	// b2
+	{{13, md4One}, {14, md4One}, {15, md4Zero}, {17, md4Prev}, {19, md4Zero}, {20, md4Zero}, {21, md4Zero}, {22, md4Zero}},
+	// a3
+	{{13, md4One}, {14, md4One}, {15, md4One}, {17, md4Zero}, {19, md4Zero}, {20, md4Zero}, {21, md4Zero}, {22, md4One}, {23, md4Prev}, {26, md4Prev}},
+	// d3
+	{{13, md4One}, {14, md4One}, {15, md4One}, {17, md4Zero}, {20, md4Zero}, {21, md4One}, {22, md4One}, {23, md4Zero}, {26, md4One}, {30, md4Prev}},
+	// c3
+	{{17, md4One}, {20, md4Zero}, {21, md4Zero}, {22, md4Zero}, {23, md4Zero}, {26, md4Zero}, {30, md4One}, {32, md4Prev}},
+	// b3
+	{{20, md4Zero}, {21, md4One}, {22, md4One}, {23, md4Prev}, {26, md4One}, {30, md4Zero}, {32, md4Zero}},
+	// a4
+	{{23, md4Zero}, {26, md4Zero}, {27, md4Prev}, {29, md4Prev}, {30, md4One}, {32, md4Zero}},
+	// d4
+	{{23, md4Zero}, {26, md4Zero}, {27, md4One}, {29, md4One}, {30, md4Zero}, {32, md4One}},
+	// c4
+	{{19, md4Prev}, {23, md4One}, {26, md4One}, {27, md4Zero}, {29, md4Zero}, {30, md4Zero}},
+	// b4
+	{{19, md4Zero}, {26, md4Prev}, {27, md4One}, {29, md4One}, {30, md4Zero}},
+}
+
+// md4A5Conditions and md4D5Conditions are the sufficient conditions on the
+// chaining variables a5 and d5 of the second round.
+var (
+	md4A5Conditions = []md4Condition{{19, md4Prev2}, {26, md4One}, {27, md4Zero}, {29, md4One}, {32, md4One}}
+	md4D5Conditions = []md4Condition{{19, md4Prev}, {26, md4Prev2}, {27, md4Prev2}, {29, md4Prev2}, {32, md4Prev2}}
+)
+
+// MD4Collision finds two distinct single-block messages which have the same
+// MD4 digest, and returns them along with the number of messages which were
+// tried.
+//
+// This is the attack described by Wang et al. in "Cryptanalysis of the Hash
+// Functions MD4 and RIPEMD". The two messages differ by:
+//
+//	m'_1 = m_1 + 2^31
+//	m'_2 = m_2 + 2^31 - 2^28
+//	m'_12 = m_12 - 2^16
+//
+// which leads to a collision with high probability if a set of sufficient
+// conditions on the chaining variables holds.
+//
+// Random messages are modified such that all conditions of the first round
+// hold: As each message word is only used once in the first round, each
+// chaining variable can be fixed directly, and the message word recalculated
+// from it. Of the second round, only the conditions on a5 and d5 are then
+// corrected, by flipping bits of a1 and a2 respectively, and recalculating the
+// message words of the following steps such that the remaining chaining
+// variables of the first round are unchanged.
+//
+// The conditions on c5 and all later chaining variables are left to chance.
+// Correcting c5 the same way would require flipping bits of a3 which are
+// themselves constrained, so about 2^17 messages are tried on average.
+func MD4Collision() ([]byte, []byte, int, error) {
+	return MD4CollisionFrom(nil)
+}
+
+// MD4CollisionFrom finds two colliding messages as MD4Collision() does,
+// reading the random messages from the given source of randomness. If r is
+// nil, a CSPRNG is used.
+func MD4CollisionFrom(r io.Reader) ([]byte, []byte, int, error) {
+	r = randutil.Reader(r)
+	buf := make([]byte, md4.BlockSize)
+
+	for trials := 1; ; trials++ {
+		_, err := io.ReadFull(r, buf)
+		if err != nil {
+			return []byte{}, []byte{}, trials, fmt.Errorf("Error generating random message: %v", err)
+		}
+
+		m := md4.Words(buf)
+		var q md4Chain
+		q.round1(&m)
+		q.fixA5(&m)
+		q.fixD5(&m)
+
+		m2 := m
+		m2[1] += 1 << 31
+		m2[2] += 1<<31 - 1<<28
+		m2[12] -= 1 << 16
+
+		if md4.CompressWords(md4.IV, m) == md4.CompressWords(md4.IV, m2) {
+			return md4.Block(m), md4.Block(m2), trials, nil
+		}
+	}
+}
+
+// md4Chain holds the chaining variables of the first round, followed by a5
+// and d5 of the second round. The first four entries hold the initial state,
+// in the order a0, d0, c0, b0, such that step i calculates entry i+4 from
+// entries i to i+3.
+type md4Chain [22]uint32
+
+// round1 calculates the chaining variables of the first round, enforcing its
+// conditions and adjusting the message words accordingly.
+func (q *md4Chain) round1(m *[16]uint32) {
+	q[0], q[1], q[2], q[3] = md4.IV[0], md4.IV[3], md4.IV[2], md4.IV[1]
+
+	for i := 0; i < 16; i++ {
+		x := q.step1(i, m[i])
+		x = md4Enforce(x, q[i+3], q[i+2], md4Round1Conditions[i])
+		q.set1(i, x, m)
+	}
+}
+
+// step1 calculates the chaining variable of step i of the first round.
+func (q *md4Chain) step1(i int, m uint32) uint32 {
+	return bits.RotateLeft32(q[i]+md4.F(q[i+3], q[i+2], q[i+1])+m, md4.Shifts[0][i%4])
+}
+
+// set1 sets the chaining variable of step i of the first round, and
+// recalculates the corresponding message word.
+func (q *md4Chain) set1(i int, x uint32, m *[16]uint32) {
+	m[i] = bits.RotateLeft32(x, -md4.Shifts[0][i%4]) - q[i] - md4.F(q[i+3], q[i+2], q[i+1])
+	q[i+4] = x
+}
+
+// reset1 recalculates the message words of steps from to to of the first
+// round, such that their chaining variables stay the same after an earlier
+// one changed.
+func (q *md4Chain) reset1(from, to int, m *[16]uint32) {
+	for i := from; i <= to; i++ {
+		q.set1(i, q[i+4], m)
+	}
+}
+
+// a5 calculates the first chaining variable of the second round.
+func (q *md4Chain) a5(m *[16]uint32) uint32 {
+	return bits.RotateLeft32(q[16]+md4.G(q[19], q[18], q[17])+m[0]+md4.K2, md4.Shifts[1][0])
+}
+
+// d5 calculates the second chaining variable of the second round.
+func (q *md4Chain) d5(m *[16]uint32) uint32 {
+	return bits.RotateLeft32(q[17]+md4.G(q[20], q[19], q[18])+m[4]+md4.K2, md4.Shifts[1][1])
+}
+
+// fixA5 enforces the conditions on a5. As a1 and a5 are both calculated from
+// m_0 with the same rotation, flipping bit j of a1 flips bit j of a5, barring
+// carries.
+func (q *md4Chain) fixA5(m *[16]uint32) {
+	for _, cond := range md4A5Conditions {
+		a5 := q.a5(m)
+		if md4Holds(a5, q[19], q[18], cond) {
+			continue
+		}
+
+		q.set1(0, q[4]^(1<<(cond.bit-1)), m)
+		q.reset1(1, 4, m)
+	}
+	q[20] = q.a5(m)
+}
+
+// fixD5 enforces the conditions on d5. Flipping bit j-2 of a2 changes m_4 by
+// 2^(j-5), which flips bit j of d5, barring carries.
+func (q *md4Chain) fixD5(m *[16]uint32) {
+	for _, cond := range md4D5Conditions {
+		d5 := q.d5(m)
+		if md4Holds(d5, q[20], q[19], cond) {
+			continue
+		}
+
+		q.set1(4, q[8]^bits.RotateLeft32(1, int(cond.bit)-1-2), m)
+		q.reset1(5, 8, m)
+	}
+	q[21] = q.d5(m)
+}
+
+// md4Enforce modifies x such that the conditions hold, given the previous
+// chaining variable and the one before it.
+func md4Enforce(x, prev, prev2 uint32, conditions []md4Condition) uint32 {
+	for _, cond := range conditions {
+		mask := uint32(1) << (cond.bit - 1)
+
+		switch cond.kind {
+		case md4Zero:
+			x &^= mask
+		case md4One:
+			x |= mask
+		case md4Prev:
+			x ^= (x ^ prev) & mask
+		case md4Prev2:
+			x ^= (x ^ prev2) & mask
+		}
+	}
+
+	return x
+}
+
+// md4Holds returns whether the condition holds for x, given the previous
+// chaining variable and the one before it.
+func md4Holds(x, prev, prev2 uint32, cond md4Condition) bool {
+	return md4Enforce(x, prev, prev2, []md4Condition{cond}) == x
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/Lavode/cryptopals/md4"
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+	xmd4 "golang.org/x/crypto/md4"
+)
+
+func TestMD4Collision(t *testing.T) {
+	m1, m2, trials, err := MD4Collision()
+	assert.Nil(t, err)
+	assert.Greater(t, trials, 0)
+
+	assert.Equal(t, md4.BlockSize, len(m1))
+	assert.NotEqual(t, m1, m2)
+	assert.Equal(t, md4.Sum(m1), md4.Sum(m2))
+
+	// Verify with an independent implementation
+	h1 := xmd4.New()
+	h1.Write(m1)
+	h2 := xmd4.New()
+	h2.Write(m2)
+	assert.Equal(t, h1.Sum(nil), h2.Sum(nil))
+}
+
+func TestMD4CollisionFrom(t *testing.T) {
+	a1, a2, trials, err := MD4CollisionFrom(randutil.NewSeeded(42))
+	assert.Nil(t, err)
+	assert.Equal(t, md4.Sum(a1), md4.Sum(a2))
+
+	b1, b2, again, err := MD4CollisionFrom(randutil.NewSeeded(42))
+	assert.Nil(t, err)
+	assert.Equal(t, trials, again)
+	assert.Equal(t, a1, b1)
+	assert.Equal(t, a2, b2)
+}
+
+func TestMD4Round1Conditions(t *testing.T) {
+	m := md4.Words(make([]byte, md4.BlockSize))
+	var q md4Chain
+	q.round1(&m)
+	q.fixA5(&m)
+	q.fixD5(&m)
+
+	// Message modification of the second round must not have broken any
+	// conditions of the first round.
+	var check md4Chain
+	check[0], check[1], check[2], check[3] = md4.IV[0], md4.IV[3], md4.IV[2], md4.IV[1]
+	for i := 0; i < 16; i++ {
+		check[i+4] = check.step1(i, m[i])
+		for _, cond := range md4Round1Conditions[i] {
+			assert.True(t, md4Holds(check[i+4], check[i+3], check[i+2], cond), "Condition on bit %d of step %d", cond.bit, i)
+		}
+	}
+
+	check[20] = check.a5(&m)
+	check[21] = check.d5(&m)
+	assert.Equal(t, q, check)
+}
@@ -0,0 +1,224 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/Lavode/cryptopals/toyhash"
+)
+
+// Multicollision is a set of 2^n messages, n blocks each, which all lead
+// from the same initial state to the same final state of an iterated hash.
+//
+// Each message is made up of one of the two blocks Blocks[i][0] and
+// Blocks[i][1] at every position i.
+type Multicollision struct {
+	Blocks [][2][]byte
+	State  uint32
+}
+
+// blockGenerator returns a new candidate block for a search on every call.
+type blockGenerator func() ([]byte, error)
+
+// randomBlocks returns a generator of random blocks, read from r.
+func randomBlocks(r io.Reader) blockGenerator {
+	r = randutil.Reader(r)
+
+	return func() ([]byte, error) {
+		block := make([]byte, toyhash.BlockSize)
+		_, err := io.ReadFull(r, block)
+		if err != nil {
+			return []byte{}, fmt.Errorf("Error generating random block: %v", err)
+		}
+
+		return block, nil
+	}
+}
+
+// counterBlocks returns a generator of distinct blocks, consisting of the
+// label and an increasing counter, both as 64-bit big-endian integers. Unlike
+// randomBlocks(), this makes searches deterministic without requiring a
+// seeded source of randomness.
+func counterBlocks(label uint64) blockGenerator {
+	counter := uint64(0)
+
+	return func() ([]byte, error) {
+		block := make([]byte, toyhash.BlockSize)
+		binary.BigEndian.PutUint64(block, label)
+		binary.BigEndian.PutUint64(block[8:], counter)
+		counter++
+
+		return block, nil
+	}
+}
+
+// ToyHashCollision finds two distinct blocks which lead from the given state
+// to the same state under the hash's compression function, and returns them
+// along with the resulting state.
+//
+// This is a simple birthday attack, requiring about 2^(b/2) calls to the
+// compression function for a state of b bits.
+func ToyHashCollision(h *toyhash.Hash, state uint32) ([]byte, []byte, uint32, error) {
+	return ToyHashCollisionFrom(nil, h, state)
+}
+
+// ToyHashCollisionFrom finds two colliding blocks as ToyHashCollision() does,
+// reading candidate blocks from the given source of randomness. If r is nil,
+// a CSPRNG is used.
+func ToyHashCollisionFrom(r io.Reader, h *toyhash.Hash, state uint32) ([]byte, []byte, uint32, error) {
+	seen := make(map[uint32][]byte)
+	blocks := randomBlocks(r)
+
+	for {
+		block, err := blocks()
+		if err != nil {
+			return []byte{}, []byte{}, 0, err
+		}
+
+		next := h.Compress(state, block)
+		other, ok := seen[next]
+		if ok && !bytes.Equal(other, block) {
+			return other, block, next, nil
+		}
+
+		seen[next] = block
+	}
+}
+
+// JouxMulticollision generates 2^n messages which all collide under the
+// hash, starting at the given state.
+//
+// As described by Joux in "Multicollisions in Iterated Hash Functions", this
+// only requires n collision searches: Having found two blocks leading to the
+// same state, the next collision is searched for starting at that state, so
+// any combination of the colliding blocks leads to the same final state.
+func JouxMulticollision(h *toyhash.Hash, state uint32, n int) (Multicollision, error) {
+	return JouxMulticollisionFrom(nil, h, state, n)
+}
+
+// JouxMulticollisionFrom generates a multicollision as JouxMulticollision()
+// does, reading candidate blocks from the given source of randomness. If r is
+// nil, a CSPRNG is used.
+func JouxMulticollisionFrom(r io.Reader, h *toyhash.Hash, state uint32, n int) (Multicollision, error) {
+	mc := Multicollision{State: state}
+
+	err := mc.ExtendFrom(r, h, n)
+	if err != nil {
+		return Multicollision{}, err
+	}
+
+	return mc, nil
+}
+
+// Extend extends the multicollision by another n blocks, which increases the
+// number of colliding messages by a factor of 2^n.
+func (mc *Multicollision) Extend(h *toyhash.Hash, n int) error {
+	return mc.ExtendFrom(nil, h, n)
+}
+
+// ExtendFrom extends the multicollision as Extend() does, reading candidate
+// blocks from the given source of randomness. If r is nil, a CSPRNG is used.
+func (mc *Multicollision) ExtendFrom(r io.Reader, h *toyhash.Hash, n int) error {
+	for i := 0; i < n; i++ {
+		a, b, state, err := ToyHashCollisionFrom(r, h, mc.State)
+		if err != nil {
+			return err
+		}
+
+		mc.Blocks = append(mc.Blocks, [2][]byte{a, b})
+		mc.State = state
+	}
+
+	return nil
+}
+
+// Count returns the number of colliding messages.
+func (mc Multicollision) Count() uint64 {
+	return uint64(1) << len(mc.Blocks)
+}
+
+// Message returns the colliding message with the given index. The most
+// significant of the index's n bits selects the first block.
+func (mc Multicollision) Message(index uint64) []byte {
+	n := len(mc.Blocks)
+	msg := make([]byte, 0, n*toyhash.BlockSize)
+
+	for i, pair := range mc.Blocks {
+		bit := (index >> (n - 1 - i)) & 1
+		msg = append(msg, pair[bit]...)
+	}
+
+	return msg
+}
+
+// ConcatenatedHashCollision finds two distinct messages which collide under
+// the concatenation of two hashes, h(m) = f(m) || g(m).
+//
+// Rather than requiring the effort of a birthday attack on the combined
+// state, this generates a multicollision of 2^(b_g/2) messages in f, where
+// b_g is the size of g's state. Among these, a collision in g is expected by
+// the birthday bound. Should there be none, the multicollision is extended
+// one block at a time, until there is.
+//
+// The cheaper hash should thus be given as f. The number of calls to either
+// compression function is tracked by the hashes.
+func ConcatenatedHashCollision(f, g *toyhash.Hash) ([]byte, []byte, error) {
+	return ConcatenatedHashCollisionFrom(nil, f, g)
+}
+
+// ConcatenatedHashCollisionFrom finds two colliding messages as
+// ConcatenatedHashCollision() does, reading candidate blocks from the given
+// source of randomness. If r is nil, a CSPRNG is used.
+func ConcatenatedHashCollisionFrom(r io.Reader, f, g *toyhash.Hash) ([]byte, []byte, error) {
+	mc, err := JouxMulticollisionFrom(r, f, f.IV, g.Bits/2)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	// States of g after each prefix of the multicollision's messages,
+	// ordered such that the state of the message with index i is at
+	// position i.
+	states := []uint32{g.IV}
+	for i := range mc.Blocks {
+		states = concatenatedHashStep(g, states, mc.Blocks[i])
+	}
+
+	for {
+		seen := make(map[uint32]uint64, len(states))
+		for i, state := range states {
+			j, ok := seen[state]
+			if ok {
+				// All messages are of the same length, so the
+				// padding, and hence the collision, carries
+				// over to the full hashes.
+				return mc.Message(j), mc.Message(uint64(i)), nil
+			}
+
+			seen[state] = uint64(i)
+		}
+
+		if len(mc.Blocks) >= g.Bits {
+			return []byte{}, []byte{}, fmt.Errorf("No collision in g among %d messages", mc.Count())
+		}
+
+		err = mc.ExtendFrom(r, f, 1)
+		if err != nil {
+			return []byte{}, []byte{}, err
+		}
+		states = concatenatedHashStep(g, states, mc.Blocks[len(mc.Blocks)-1])
+	}
+}
+
+// concatenatedHashStep extends every given state with either block of the
+// pair.
+func concatenatedHashStep(g *toyhash.Hash, states []uint32, pair [2][]byte) []uint32 {
+	next := make([]uint32, 0, 2*len(states))
+	for _, state := range states {
+		next = append(next, g.Compress(state, pair[0]), g.Compress(state, pair[1]))
+	}
+
+	return next
+}
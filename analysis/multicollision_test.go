@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/Lavode/cryptopals/toyhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJouxMulticollision(t *testing.T) {
+	h, err := toyhash.New(16, 0x1234)
+	assert.Nil(t, err)
+
+	mc, err := JouxMulticollision(h, h.IV, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(16), mc.Count())
+
+	seen := make(map[string]bool)
+	expected := h.Sum(mc.Message(0))
+	for i := uint64(0); i < mc.Count(); i++ {
+		msg := mc.Message(i)
+		assert.Equal(t, 4*toyhash.BlockSize, len(msg))
+		assert.Equal(t, mc.State, h.Iterate(h.IV, msg))
+		assert.Equal(t, expected, h.Sum(msg))
+
+		seen[string(msg)] = true
+	}
+	assert.Equal(t, 16, len(seen))
+}
+
+func TestJouxMulticollisionFrom(t *testing.T) {
+	h, err := toyhash.New(16, 0x1234)
+	assert.Nil(t, err)
+
+	a, err := JouxMulticollisionFrom(randutil.NewSeeded(42), h, h.IV, 4)
+	assert.Nil(t, err)
+	b, err := JouxMulticollisionFrom(randutil.NewSeeded(42), h, h.IV, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, a, b)
+}
+
+func TestConcatenatedHashCollision(t *testing.T) {
+	f, err := toyhash.New(16, 0x1234)
+	assert.Nil(t, err)
+	g, err := toyhash.New(24, 0x567890)
+	assert.Nil(t, err)
+
+	a, b, err := ConcatenatedHashCollision(f, g)
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, f.Sum(a), f.Sum(b))
+	assert.Equal(t, g.Sum(a), g.Sum(b))
+
+	// About 12 collisions of 2^8 calls each in f, and 2^13 calls in g,
+	// which is far below the 2^20 calls of a birthday attack on the
+	// combined state.
+	t.Logf("Calls to f: %d, calls to g: %d", f.Calls, g.Calls)
+	assert.Less(t, f.Calls+g.Calls, 1<<18)
+}
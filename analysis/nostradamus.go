@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/Lavode/cryptopals/toyhash"
+)
+
+// Diamond is a diamond structure of an iterated hash, as described by Kelsey
+// and Kohno in "Herding Hash Functions and the Nostradamus Attack". It is a
+// binary tree of k levels, which funnels 2^k leaf states into a single root
+// state.
+type Diamond struct {
+	// Leaves are the 2^k states at the bottom of the tree.
+	Leaves []uint32
+	// Blocks[l][i] is the block which leads from node i on level l to node
+	// i/2 on level l+1, with the leaves being on level 0.
+	Blocks [][][]byte
+	// Root is the state at the top of the tree.
+	Root uint32
+}
+
+// nostradamusFiller is the character with which prefixes are padded to their
+// committed length.
+const nostradamusFiller = ' '
+
+// Prediction is a commitment to the hash of a message, consisting of a prefix
+// of PrefixBlocks blocks which is chosen only after the commitment, followed
+// by a suffix which herds it into the committed hash.
+type Prediction struct {
+	Diamond      Diamond
+	PrefixBlocks int
+	Hash         uint32
+}
+
+// NewDiamond builds a diamond structure of k levels.
+//
+// The leaves are the states 0 to 2^k - 1. On each level, the nodes are paired
+// up, and a birthday attack finds a block for either which leads both to the
+// same node on the next level. This requires about 2^k * 2^(b/2) calls to the
+// compression function for a state of b bits.
+//
+// Candidate blocks are enumerated rather than chosen at random, so the
+// structure is deterministic for a given hash.
+func NewDiamond(h *toyhash.Hash, k int) (Diamond, error) {
+	if k < 0 || k > h.Bits {
+		return Diamond{}, fmt.Errorf("Number of levels must be in [0, %d], but was %d", h.Bits, k)
+	}
+
+	d := Diamond{Leaves: make([]uint32, 1<<k)}
+	for i := range d.Leaves {
+		d.Leaves[i] = uint32(i)
+	}
+
+	blocks := counterBlocks(0)
+	states := d.Leaves
+	for level := 0; level < k; level++ {
+		next := make([]uint32, len(states)/2)
+		levelBlocks := make([][]byte, len(states))
+
+		for i := 0; i < len(states); i += 2 {
+			a, b, state, err := toyHashCollisionBetween(h, states[i], states[i+1], blocks)
+			if err != nil {
+				return Diamond{}, err
+			}
+
+			levelBlocks[i] = a
+			levelBlocks[i+1] = b
+			next[i/2] = state
+		}
+
+		d.Blocks = append(d.Blocks, levelBlocks)
+		states = next
+	}
+	d.Root = states[0]
+
+	return d, nil
+}
+
+// Path returns the blocks which lead from the given leaf to the root.
+func (d Diamond) Path(leaf int) []byte {
+	path := make([]byte, 0, len(d.Blocks)*toyhash.BlockSize)
+
+	for _, levelBlocks := range d.Blocks {
+		path = append(path, levelBlocks[leaf]...)
+		leaf /= 2
+	}
+
+	return path
+}
+
+// NostradamusPredict builds a diamond structure of k levels, and commits to
+// the hash of messages consisting of a prefix of the given number of blocks, a
+// linking block, and the path through the diamond.
+//
+// Prefixes herded into the prediction may thus be at most prefixBlocks *
+// toyhash.BlockSize bytes long. Shorter ones are padded with spaces.
+//
+// As the length of the message is fixed up front, so is its padding, which
+// is applied to the diamond's root to get the committed hash.
+func NostradamusPredict(h *toyhash.Hash, k int, prefixBlocks int) (Prediction, error) {
+	d, err := NewDiamond(h, k)
+	if err != nil {
+		return Prediction{}, err
+	}
+
+	length := (prefixBlocks + 1 + k) * toyhash.BlockSize
+	hash := h.Iterate(d.Root, toyhash.Padding(length))
+
+	return Prediction{Diamond: d, PrefixBlocks: prefixBlocks, Hash: hash}, nil
+}
+
+// Herd returns a message which starts with the given prefix, and has the
+// committed hash. The prefix is padded with spaces to the committed number of
+// blocks.
+//
+// It searches for a linking block which leads from the state after the prefix
+// to any of the diamond's leaves, which requires about 2^(b-k) calls to the
+// compression function.
+func (p Prediction) Herd(h *toyhash.Hash, prefix []byte) ([]byte, error) {
+	if len(prefix) > p.PrefixBlocks*toyhash.BlockSize {
+		return []byte{}, fmt.Errorf("Prefix must be at most of length %d, but was %d", p.PrefixBlocks*toyhash.BlockSize, len(prefix))
+	}
+
+	padded := append([]byte{}, prefix...)
+	for len(padded) < p.PrefixBlocks*toyhash.BlockSize {
+		padded = append(padded, nostradamusFiller)
+	}
+
+	leaves := make(map[uint32]int, len(p.Diamond.Leaves))
+	for i, leaf := range p.Diamond.Leaves {
+		leaves[leaf] = i
+	}
+
+	state := h.Iterate(h.IV, padded)
+	blocks := counterBlocks(1)
+	for i := uint64(0); i < uint64(1)<<h.Bits; i++ {
+		link, err := blocks()
+		if err != nil {
+			return []byte{}, err
+		}
+
+		leaf, ok := leaves[h.Compress(state, link)]
+		if !ok {
+			continue
+		}
+
+		msg := append(padded, link...)
+		return append(msg, p.Diamond.Path(leaf)...), nil
+	}
+
+	return []byte{}, fmt.Errorf("Unable to find linking block into diamond")
+}
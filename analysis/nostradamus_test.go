@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Lavode/cryptopals/toyhash"
+	"github.com/stretchr/testify/assert"
+)
+
+// Predicted results, which are padded to four blocks when herding.
+var nostradamusResults = [][]byte{
+	[]byte("Basel 3:1 Zurich, Bern 0:0 Thun, Geneva 2:1 Lugano"),
+	[]byte("Basel 0:2 Zurich, Bern 1:1 Thun, Geneva 0:4 Lugano"),
+	[]byte("Basel 1:1 Zurich, Bern 5:0 Thun, Geneva 3:3 Lugano"),
+}
+
+func TestDiamond(t *testing.T) {
+	h, err := toyhash.New(16, 0x1234)
+	assert.Nil(t, err)
+
+	d, err := NewDiamond(h, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, 32, len(d.Leaves))
+
+	for i, leaf := range d.Leaves {
+		path := d.Path(i)
+		assert.Equal(t, 5*toyhash.BlockSize, len(path))
+		assert.Equal(t, d.Root, h.Iterate(leaf, path))
+	}
+
+	_, err = NewDiamond(h, 17)
+	assert.Error(t, err)
+}
+
+func TestNostradamus(t *testing.T) {
+	h, err := toyhash.New(16, 0x1234)
+	assert.Nil(t, err)
+
+	prediction, err := NostradamusPredict(h, 8, 4)
+	assert.Nil(t, err)
+	// Diamond and linking blocks are deterministic, and so is the
+	// committed hash.
+	assert.Equal(t, uint32(0x60cc), prediction.Hash)
+
+	for _, prefix := range nostradamusResults {
+		padded := append(append([]byte{}, prefix...), bytes.Repeat([]byte(" "), 64-len(prefix))...)
+
+		msg, err := prediction.Herd(h, prefix)
+		assert.Nil(t, err)
+
+		assert.Equal(t, padded, msg[:len(padded)])
+		assert.Equal(t, prediction.Hash, h.Sum(msg))
+
+		again, err := prediction.Herd(h, padded)
+		assert.Nil(t, err)
+		assert.Equal(t, msg, again)
+	}
+
+	// Empty prefix is padded entirely
+	msg, err := prediction.Herd(h, []byte{})
+	assert.Nil(t, err)
+	assert.Equal(t, bytes.Repeat([]byte(" "), 64), msg[:64])
+	assert.Equal(t, prediction.Hash, h.Sum(msg))
+
+	_, err = prediction.Herd(h, bytes.Repeat([]byte("A"), 65))
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+// RC4Bias is a single-byte bias of RC4's keystream, where the byte at the
+// given position - counting from 1 - takes the given value more often than
+// any other.
+type RC4Bias struct {
+	Position int
+	Value    byte
+}
+
+// RC4Biases are the biases of the 16th and 32nd keystream bytes towards 240
+// and 224 respectively, as described by AlFardan et al. in "On the Security
+// of RC4 in TLS".
+var RC4Biases = []RC4Bias{{Position: 16, Value: 240}, {Position: 32, Value: 224}}
+
+// RC4BiasRecover recovers the secret which the oracle appends to requests
+// before encrypting them with RC4 under a fresh key each.
+//
+// Prefixing the secret with a request of the right length moves any of its
+// bytes to the position of a bias. As the keystream byte there is biased
+// towards the bias's value, so is the ciphertext byte towards the plaintext
+// byte XOR the value. Collecting the given number of ciphertexts for each
+// request length thus allows to pick the most likely plaintext byte. Where a
+// byte can be moved to several biases, their counts are added up.
+//
+// Every byte of the secret must be reachable by some bias, so the secret must
+// not be longer than the largest bias's position.
+//
+// The work of collecting ciphertexts is split among the given number of
+// goroutines, or one per CPU if it is not positive.
+func RC4BiasRecover(or oracle.StreamEncryptionOracle, biases []RC4Bias, samples int, workers int) ([]byte, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctxt, err := or.Encrypt([]byte{})
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error querying oracle: %v", err)
+	}
+	length := len(ctxt)
+
+	maxPosition := 0
+	covered := make([]bool, length)
+	for _, bias := range biases {
+		if bias.Position > maxPosition {
+			maxPosition = bias.Position
+		}
+
+		for i := 0; i < bias.Position && i < length; i++ {
+			covered[i] = true
+		}
+	}
+	for i, ok := range covered {
+		if !ok {
+			return []byte{}, fmt.Errorf("Byte %d of secret is not covered by any bias", i)
+		}
+	}
+
+	scores := make([][256]int, length)
+	for prefix := 0; prefix < maxPosition; prefix++ {
+		// Biases which a byte of the secret can be moved to with a
+		// prefix of this length, and the bytes in question.
+		relevant := make([]RC4Bias, 0)
+		indices := make([]int, 0)
+		for _, bias := range biases {
+			i := bias.Position - 1 - prefix
+			if i >= 0 && i < length {
+				relevant = append(relevant, bias)
+				indices = append(indices, i)
+			}
+		}
+		if len(relevant) == 0 {
+			continue
+		}
+
+		counts, err := rc4BiasCount(or, prefix, relevant, samples, workers)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		for k, bias := range relevant {
+			for c, count := range counts[k] {
+				scores[indices[k]][byte(c)^bias.Value] += count
+			}
+		}
+	}
+
+	secret := make([]byte, length)
+	for i, score := range scores {
+		best := 0
+		for c := range score {
+			if score[c] > score[best] {
+				best = c
+			}
+		}
+
+		secret[i] = byte(best)
+	}
+
+	return secret, nil
+}
+
+// rc4BiasCount collects the given number of ciphertexts of requests of the
+// given length, and counts the values of the ciphertext bytes at the biases'
+// positions.
+func rc4BiasCount(or oracle.StreamEncryptionOracle, prefix int, biases []RC4Bias, samples int, workers int) ([][256]int, error) {
+	request := bytes.Repeat([]byte("A"), prefix)
+
+	results := make([][][256]int, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		n := samples / workers
+		if w < samples%workers {
+			n++
+		}
+
+		wg.Add(1)
+		go func(w int, n int) {
+			defer wg.Done()
+
+			counts := make([][256]int, len(biases))
+			for i := 0; i < n; i++ {
+				ctxt, err := or.Encrypt(request)
+				if err != nil {
+					errs[w] = fmt.Errorf("Error querying oracle: %v", err)
+					return
+				}
+
+				for k, bias := range biases {
+					counts[k][ctxt[bias.Position-1]]++
+				}
+			}
+
+			results[w] = counts
+		}(w, n)
+	}
+	wg.Wait()
+
+	counts := make([][256]int, len(biases))
+	for w := range results {
+		if errs[w] != nil {
+			return [][256]int{}, errs[w]
+		}
+
+		for k := range counts {
+			for c := range counts[k] {
+				counts[k][c] += results[w][k][c]
+			}
+		}
+	}
+
+	return counts, nil
+}
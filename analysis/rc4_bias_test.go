@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRC4BiasRecover(t *testing.T) {
+	// The biases at positions 16 and 32 require millions of ciphertexts
+	// per byte, so we'll use the much stronger bias of the second byte
+	// towards 0 as described by Mantin and Shamir.
+	or := oracle.RC4Cookie{Cookie: []byte("Hi")}
+	biases := []RC4Bias{{Position: 2, Value: 0}}
+
+	secret, err := RC4BiasRecover(&or, biases, 1<<14, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Hi"), secret)
+}
+
+func TestRC4BiasRecoverShortSecret(t *testing.T) {
+	or := oracle.RC4Cookie{Cookie: []byte("!")}
+	biases := []RC4Bias{{Position: 2, Value: 0}}
+
+	secret, err := RC4BiasRecover(&or, biases, 1<<14, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("!"), secret)
+}
+
+func TestRC4BiasRecoverUncovered(t *testing.T) {
+	or := oracle.RC4Cookie{Cookie: []byte("Hey")}
+	biases := []RC4Bias{{Position: 2, Value: 0}}
+
+	_, err := RC4BiasRecover(&or, biases, 1<<14, 4)
+	assert.Error(t, err)
+}
+
+func TestRC4BiasRecoverAlFardan(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping recovery through biases at positions 16 and 32 in short mode")
+	}
+
+	// A single byte, which is moved to both positions. Each bias alone
+	// is weak, so this takes a few million ciphertexts.
+	or := oracle.RC4Cookie{Cookie: []byte("Q")}
+
+	secret, err := RC4BiasRecover(&or, RC4Biases, 1<<22, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Q"), secret)
+}
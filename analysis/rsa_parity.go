@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/Lavode/cryptopals/rsa"
+)
+
+// RSAParityDecrypt decrypts an RSA ciphertext, given access to an oracle
+// revealing the parity of the plaintext of arbitrary ciphertexts.
+//
+// Multiplying the ciphertext by 2^e doubles the plaintext modulo N. As N is
+// odd, 2m mod N is even if 2m < N - that is if m is in the lower half of
+// [0, N) - and odd if it wrapped around the modulus. Repeatedly doubling the
+// ciphertext thus allows a binary search for the plaintext, which takes
+// log2(N) oracle queries.
+//
+// The bounds are kept as exact rationals, as rounding them to integers would
+// introduce errors in the last bits of the recovered plaintext.
+//
+// If progress is not nil, it is called with the current upper bound after
+// each query, which converges on the plaintext from above.
+func RSAParityDecrypt(pub rsa.PublicKey, ctxt *big.Int, or oracle.ParityOracle, progress func(partial []byte)) ([]byte, error) {
+	// Multiplier which doubles the plaintext
+	double := new(big.Int).Exp(big.NewInt(2), pub.E, pub.N)
+
+	lower := new(big.Rat)
+	upper := new(big.Rat).SetInt(pub.N)
+	two := big.NewRat(2, 1)
+
+	c := new(big.Int).Set(ctxt)
+	for i := 0; i < pub.N.BitLen(); i++ {
+		c.Mul(c, double)
+		c.Mod(c, pub.N)
+
+		even, err := or.IsEven(c)
+		if err != nil {
+			return []byte{}, fmt.Errorf("Error querying parity oracle: %v", err)
+		}
+
+		mid := new(big.Rat).Add(lower, upper)
+		mid.Quo(mid, two)
+
+		if even {
+			upper = mid
+		} else {
+			lower = mid
+		}
+
+		if progress != nil {
+			progress(ratFloor(upper).Bytes())
+		}
+	}
+
+	// The plaintext is the one integer in [lower, upper), which has a
+	// width of less than one. Neither bound is an integer (other than a
+	// lower bound of 0), as N is odd.
+	msg := ratFloor(lower)
+	if !lower.IsInt() {
+		msg.Add(msg, big.NewInt(1))
+	}
+
+	return msg.Bytes(), nil
+}
+
+// ratFloor returns the largest integer which is at most the given
+// non-negative rational.
+func ratFloor(x *big.Rat) *big.Int {
+	return new(big.Int).Quo(x.Num(), x.Denom())
+}
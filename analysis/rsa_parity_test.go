@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSAParityDecrypt(t *testing.T) {
+	or := oracle.RSAParity{Bits: 1024}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	msg, err := base64.StdEncoding.DecodeString("VGhhdCdzIHdoeSBJIGZvdW5kIHlvdSBkb24ndCBwbGF5IGFyb3VuZCB3aXRoIHRoZSBGdW5reSBDb2xkIE1lZGluYQ==")
+	assert.Nil(t, err)
+
+	ctxt, err := pub.Encrypt(new(big.Int).SetBytes(msg))
+	assert.Nil(t, err)
+
+	steps := 0
+	recovered, err := RSAParityDecrypt(pub, ctxt, &or, func(partial []byte) {
+		steps++
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, msg, recovered)
+	assert.Equal(t, 1024, steps)
+}
+
+func TestRSAParityDecryptLastBytes(t *testing.T) {
+	or := oracle.RSAParity{Bits: 256}
+	pub, err := or.PublicKey()
+	assert.Nil(t, err)
+
+	// Messages whose last bytes are prone to off-by-one errors, as well as
+	// the extremes of the message space.
+	nMinusOne := new(big.Int).Sub(pub.N, big.NewInt(1))
+	msgs := [][]byte{
+		{0x01},
+		{0x41, 0x00, 0x00},
+		{0x41, 0xff, 0xff},
+		nMinusOne.Bytes(),
+	}
+
+	for _, msg := range msgs {
+		ctxt, err := pub.Encrypt(new(big.Int).SetBytes(msg))
+		assert.Nil(t, err)
+
+		recovered, err := RSAParityDecrypt(pub, ctxt, &or, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, msg, recovered)
+	}
+}
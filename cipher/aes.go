@@ -3,8 +3,10 @@ package cipher
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/randutil"
 )
 
 // AESKeySize specifies the length of an AES-128 key in bytes.
@@ -46,17 +48,40 @@ func newAES(key []byte) (cipher.Block, error) {
 // The returned byte slice is also suitable for use as an IV of a block cipher
 // mode of operation, although it may be overkill for those modes of operations
 // which only require IVs to not be reused, but do not require them to be
-// chosen at random.
+// chosen at random. NewIV() is more explicit about this use, though.
 //
 // An error is returned if the underlying CSPRNG failed to provide a sufficient
 // amount of random bytes.
 func NewKey() (key []byte, err error) {
+	return NewKeyFrom(nil)
+}
+
+// NewKeyFrom generates a new byte slice suitable for use with AES, reading
+// from the given source of randomness. If r is nil, a CSPRNG is used as done
+// by NewKey().
+//
+// With a deterministic source such as randutil.NewSeeded(), the same keys are
+// generated on every run.
+func NewKeyFrom(r io.Reader) (key []byte, err error) {
 	key = make([]byte, AESKeySize)
 
-	_, err = rand.Read(key)
+	_, err = io.ReadFull(randutil.Reader(r), key)
 	if err != nil {
 		return key, fmt.Errorf("Error generating AES key: %v", err)
 	}
 
 	return key, nil
 }
+
+// NewIV generates a random IV of one AES block, reading from the given
+// source of randomness. If r is nil, a CSPRNG is used.
+func NewIV(r io.Reader) (iv []byte, err error) {
+	iv = make([]byte, AESBlockSize)
+
+	_, err = io.ReadFull(randutil.Reader(r), iv)
+	if err != nil {
+		return iv, fmt.Errorf("Error generating IV: %v", err)
+	}
+
+	return iv, nil
+}
@@ -1,9 +1,11 @@
 package cipher
 
 import (
+	"crypto/subtle"
 	"fmt"
 
 	"github.com/Lavode/cryptopals/bitwise"
+	"github.com/Lavode/cryptopals/padding"
 )
 
 // AESCBC encapsulates an instance of the AES-128 block cipher in CBC mode.
@@ -136,3 +138,37 @@ func (cbc *AESCBC) Decrypt(ctxt []byte) (msg []byte, err error) {
 
 	return msg, nil
 }
+
+// MAC calculates the CBC-MAC of the message, which is the last block of the
+// AES-128 CBC encryption of the PKCS#7-padded message.
+//
+// The IV is part of the MAC's input. If an attacker can choose the IV, they
+// can freely modify the first block of the message, so prefer a fixed IV
+// consisting of zero bytes.
+//
+// CBC-MAC is only secure for messages of a fixed length. Given two messages
+// and their MACs, it is possible to forge the MAC of a message combining the
+// two.
+func (cbc *AESCBC) MAC(msg []byte) ([]byte, error) {
+	ctxt, err := cbc.Encrypt(padding.PKCS7Pad(msg, AESBlockSize))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	mac := make([]byte, AESBlockSize)
+	copy(mac, ctxt[len(ctxt)-AESBlockSize:])
+
+	return mac, nil
+}
+
+// VerifyMAC checks whether the MAC is a valid CBC-MAC of the message.
+//
+// The comparison takes place in constant time.
+func (cbc *AESCBC) VerifyMAC(msg []byte, mac []byte) (bool, error) {
+	expected, err := cbc.MAC(msg)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(expected, mac) == 1, nil
+}
@@ -48,3 +48,33 @@ It is also a multiple of 16 bytes. Fun!!
 	assert.Nil(t, err)
 	assert.Equal(t, expectedMsg, msg)
 }
+
+func TestAESCBCMAC(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := make([]byte, AESBlockSize)
+	cbc := AESCBC{Key: key, IV: iv}
+
+	msg := []byte("alert('MZA who was that?');\n")
+
+	mac, err := cbc.MAC(msg)
+	assert.Nil(t, err)
+	// Known-good value from the cryptopals challenges
+	assert.Equal(t, []byte{
+		0x29, 0x6b, 0x8d, 0x7c, 0xb7, 0x8a, 0x24, 0x3d,
+		0xda, 0x4d, 0x0a, 0x61, 0xd3, 0x3b, 0xbd, 0xd1,
+	}, mac)
+
+	valid, err := cbc.VerifyMAC(msg, mac)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	valid, err = cbc.VerifyMAC([]byte("alert('Ayo, the Wu is back!');\n"), mac)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// The IV is part of the MAC's input
+	cbc.IV = []byte("0123456789abcdef")
+	valid, err = cbc.VerifyMAC(msg, mac)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+}
@@ -0,0 +1,77 @@
+package cipher
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Lavode/cryptopals/bitwise"
+)
+
+// AESCTRNonceSize specifies the length of the nonce of AES in CTR mode in
+// bytes.
+const AESCTRNonceSize = 8
+
+// AESCTR encapsulates an instance of the AES-128 block cipher in CTR mode,
+// turning it into a stream cipher.
+//
+// The keystream is the encryption of consecutive blocks of the form:
+//
+//	nonce || counter
+//
+// where the nonce is 8 bytes long, and the counter is a 64 bit little-endian
+// integer starting at 0.
+//
+// The key must be chosen as a random byte slice of length 16, as done by e.g
+// NewKey(), and be kept secret.
+// The nonce must never be reused with the same key, as the keystream would
+// then repeat. It need however not be kept secret.
+type AESCTR struct {
+	Key   []byte
+	Nonce []byte
+}
+
+// Encrypt encrypts the message with the AES-128 block cipher in CTR mode.
+//
+// As CTR mode turns AES into a stream cipher, no padding is required and the
+// ciphertext is of the same length as the message.
+func (ctr *AESCTR) Encrypt(msg []byte) (ctxt []byte, err error) {
+	if len(ctr.Nonce) != AESCTRNonceSize {
+		return []byte{}, fmt.Errorf(
+			"Expected nonce of length %d, but got %d",
+			AESCTRNonceSize,
+			len(ctr.Nonce),
+		)
+	}
+
+	aes, err := newAES(ctr.Key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	ctxt = make([]byte, len(msg))
+	counterBlock := make([]byte, AESBlockSize)
+	copy(counterBlock, ctr.Nonce)
+	keystream := make([]byte, AESBlockSize)
+
+	for i := 0; i*AESBlockSize < len(msg); i++ {
+		binary.LittleEndian.PutUint64(counterBlock[AESCTRNonceSize:], uint64(i))
+		aes.Encrypt(keystream, counterBlock)
+
+		blockStart := i * AESBlockSize
+		blockEnd := blockStart + AESBlockSize
+		if blockEnd > len(msg) {
+			blockEnd = len(msg)
+		}
+
+		copy(ctxt[blockStart:blockEnd], bitwise.Xor(msg[blockStart:blockEnd], keystream))
+	}
+
+	return ctxt, nil
+}
+
+// Decrypt decrypts the ciphertext with the AES-128 block cipher in CTR mode.
+//
+// Decryption is the same operation as encryption.
+func (ctr *AESCTR) Decrypt(ctxt []byte) (msg []byte, err error) {
+	return ctr.Encrypt(ctxt)
+}
@@ -0,0 +1,33 @@
+package cipher
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAESCTREncryptAndDecrypt(t *testing.T) {
+	// Known-good ciphertext from the cryptopals challenges
+	ctxt, err := base64.StdEncoding.DecodeString("L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==")
+	assert.Nil(t, err)
+
+	ctr := AESCTR{Key: []byte("YELLOW SUBMARINE"), Nonce: make([]byte, AESCTRNonceSize)}
+
+	msg, err := ctr.Decrypt(ctxt)
+	assert.Nil(t, err)
+	assert.Equal(t, "Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby ", string(msg))
+
+	reencrypted, err := ctr.Encrypt(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, ctxt, reencrypted)
+
+	// No padding required
+	out, err := ctr.Encrypt([]byte("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(out))
+
+	ctr.Nonce = []byte{0x00}
+	_, err = ctr.Encrypt(msg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+package cipher
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Lavode/cryptopals/bitwise"
+	"github.com/Lavode/cryptopals/gf128"
+)
+
+// AESGCMNonceSize specifies the recommended length of the nonce of AES in GCM
+// mode in bytes.
+const AESGCMNonceSize = 12
+
+// AESGCMTagSize specifies the full length of the authentication tag of AES in
+// GCM mode in bytes.
+const AESGCMTagSize = 16
+
+// AESGCM encapsulates an instance of the AES-128 block cipher in Galois/Counter
+// mode, an authenticated encryption mode which also authenticates additional
+// data which is not encrypted.
+//
+// Messages are encrypted in CTR mode, with a 32 bit big-endian counter in
+// the last four bytes of the counter block. The ciphertext and additional
+// data are then authenticated with GHASH, a polynomial MAC over GF(2^128)
+// keyed with H = E(K, 0^128), whose result is masked with the encryption of
+// the initial counter block J0.
+//
+// For nonces of 12 bytes, J0 = nonce || 0^31 || 1. Nonces of other lengths
+// are hashed with GHASH to obtain J0.
+//
+// The key must be chosen as a random byte slice of length 16, as done by e.g
+// NewKey(), and be kept secret.
+// The nonce must never be reused with the same key, as this reveals the XOR
+// of the messages and allows recovery of H, and thus forgeries. This is not
+// prevented, though.
+//
+// Tags may be truncated to their first TagSize bytes. Short tags do not only
+// make forgeries by guessing more likely, but also leak information about H
+// with each successful forgery.
+type AESGCM struct {
+	Key   []byte
+	Nonce []byte
+	// TagSize is the length of the tag in bytes, in [1, 16]. Defaults to
+	// AESGCMTagSize if left unset.
+	TagSize int
+}
+
+// Encrypt encrypts the message and authenticates it along with the
+// additional data, which may be nil. It returns the ciphertext, which is of
+// the same length as the message, and the authentication tag, which is
+// truncated to TagSize bytes.
+func (gcm *AESGCM) Encrypt(msg []byte, aad []byte) (ctxt []byte, tag []byte, err error) {
+	h, j0, err := gcm.setup()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	ctxt, err = gcm.ctr(j0, msg)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	tag, err = gcm.tag(h, j0, aad, ctxt)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	return ctxt, tag, nil
+}
+
+// Decrypt verifies the authentication tag of the ciphertext and additional
+// data, which may be nil, and decrypts the ciphertext.
+//
+// An error is returned, and nothing is decrypted, if the tag is invalid.
+func (gcm *AESGCM) Decrypt(ctxt []byte, aad []byte, tag []byte) (msg []byte, err error) {
+	h, j0, err := gcm.setup()
+	if err != nil {
+		return []byte{}, err
+	}
+
+	expected, err := gcm.tag(h, j0, aad, ctxt)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return []byte{}, fmt.Errorf("Invalid authentication tag")
+	}
+
+	return gcm.ctr(j0, ctxt)
+}
+
+// H returns the authentication key H = E(K, 0^128) of GHASH.
+func (gcm *AESGCM) H() (gf128.Element, error) {
+	h, _, err := gcm.setup()
+	return h, err
+}
+
+// GHASH calculates the GHASH of the additional data and ciphertext under the
+// authentication key h.
+//
+// Both are padded with zeros to a multiple of the block size, and followed
+// by a block holding their lengths in bits as 64 bit big-endian integers.
+// With X_1, ..., X_n the resulting blocks, this is the polynomial:
+//
+//	X_1 * h^n + X_2 * h^(n-1) + ... + X_n * h
+func GHASH(h gf128.Element, aad []byte, ctxt []byte) gf128.Element {
+	y := gf128.Zero()
+
+	for _, data := range [][]byte{aad, ctxt} {
+		for i := 0; i < len(data); i += AESBlockSize {
+			end := i + AESBlockSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			y = y.Add(gf128.FromBytes(data[i:end])).Mul(h)
+		}
+	}
+
+	y = y.Add(GHASHLengthBlock(len(aad), len(ctxt))).Mul(h)
+
+	return y
+}
+
+// GHASHLengthBlock returns the last block processed by GHASH, which holds
+// the lengths of the additional data and ciphertext in bits.
+func GHASHLengthBlock(aadLength int, ctxtLength int) gf128.Element {
+	var block [AESBlockSize]byte
+	binary.BigEndian.PutUint64(block[:8], uint64(aadLength)*8)
+	binary.BigEndian.PutUint64(block[8:], uint64(ctxtLength)*8)
+
+	return gf128.FromBytes(block[:])
+}
+
+// setup derives the authentication key H and the initial counter block J0.
+func (gcm *AESGCM) setup() (gf128.Element, []byte, error) {
+	if len(gcm.Nonce) == 0 {
+		return gf128.Element{}, []byte{}, fmt.Errorf("Nonce must not be empty")
+	}
+
+	if gcm.TagSize < 0 || gcm.TagSize > AESGCMTagSize {
+		return gf128.Element{}, []byte{}, fmt.Errorf("Tag size must be in [1, %d], got %d", AESGCMTagSize, gcm.TagSize)
+	}
+
+	aes, err := newAES(gcm.Key)
+	if err != nil {
+		return gf128.Element{}, []byte{}, err
+	}
+
+	hBytes := make([]byte, AESBlockSize)
+	aes.Encrypt(hBytes, hBytes)
+	h := gf128.FromBytes(hBytes)
+
+	j0 := make([]byte, AESBlockSize)
+	if len(gcm.Nonce) == AESGCMNonceSize {
+		copy(j0, gcm.Nonce)
+		j0[AESBlockSize-1] = 1
+	} else {
+		// GHASH(nonce || 0^s || 0^64 || [len(nonce)]_64), which is
+		// the same as GHASH without additional data, except for the
+		// length block.
+		j0 = GHASH(h, nil, gcm.Nonce).Bytes()
+	}
+
+	return h, j0, nil
+}
+
+// ctr encrypts the message in CTR mode, starting with the counter block
+// following j0.
+func (gcm *AESGCM) ctr(j0 []byte, msg []byte) ([]byte, error) {
+	aes, err := newAES(gcm.Key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	out := make([]byte, len(msg))
+	counterBlock := make([]byte, AESBlockSize)
+	copy(counterBlock, j0)
+	counter := binary.BigEndian.Uint32(j0[AESBlockSize-4:])
+	keystream := make([]byte, AESBlockSize)
+
+	for i := 0; i*AESBlockSize < len(msg); i++ {
+		counter++
+		binary.BigEndian.PutUint32(counterBlock[AESBlockSize-4:], counter)
+		aes.Encrypt(keystream, counterBlock)
+
+		blockStart := i * AESBlockSize
+		blockEnd := blockStart + AESBlockSize
+		if blockEnd > len(msg) {
+			blockEnd = len(msg)
+		}
+
+		copy(out[blockStart:blockEnd], bitwise.Xor(msg[blockStart:blockEnd], keystream))
+	}
+
+	return out, nil
+}
+
+// tag calculates the authentication tag GHASH(H, A, C) XOR E(K, J0),
+// truncated to TagSize bytes.
+func (gcm *AESGCM) tag(h gf128.Element, j0 []byte, aad []byte, ctxt []byte) ([]byte, error) {
+	aes, err := newAES(gcm.Key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	mask := make([]byte, AESBlockSize)
+	aes.Encrypt(mask, j0)
+
+	size := gcm.TagSize
+	if size == 0 {
+		size = AESGCMTagSize
+	}
+
+	return bitwise.Xor(GHASH(h, aad, ctxt).Bytes()[:size], mask), nil
+}
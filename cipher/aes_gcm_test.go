@@ -0,0 +1,203 @@
+package cipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type gcmTestVector struct {
+	key   string
+	nonce string
+	msg   string
+	aad   string
+	ctxt  string
+	tag   string
+}
+
+// gcmTestVectors returns the AES-128 test cases of the GCM specification
+// submitted to NIST.
+func gcmTestVectors() []gcmTestVector {
+	msg := "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255"
+	ctxt := "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985"
+	aad := "feedfacedeadbeeffeedfacedeadbeefabaddad2"
+
+	return []gcmTestVector{
+		{
+			key:   "00000000000000000000000000000000",
+			nonce: "000000000000000000000000",
+			tag:   "58e2fccefa7e3061367f1d57a4e7455a",
+		},
+		{
+			key:   "00000000000000000000000000000000",
+			nonce: "000000000000000000000000",
+			msg:   "00000000000000000000000000000000",
+			ctxt:  "0388dace60b6a392f328c2b971b2fe78",
+			tag:   "ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			msg:   msg,
+			ctxt:  ctxt,
+			tag:   "4d5c2af327cd64a62cf35abd2ba6fab4",
+		},
+		{
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			msg:   msg[:120],
+			aad:   aad,
+			ctxt:  ctxt[:120],
+			tag:   "5bc94fbc3221a5db94fae95ae7121a47",
+		},
+		{
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbad",
+			msg:   msg[:120],
+			aad:   aad,
+			ctxt:  "61353b4c2806934a777ff51fa22a4755699b2a714fcdc6f83766e5f97b6c742373806900e49f24b22b097544d4896b424989b5e1ebac0f07c23f4598",
+			tag:   "3612d2e79e3b0785561be14aaca2fccb",
+		},
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	out, err := hex.DecodeString(s)
+	assert.Nil(t, err)
+
+	return out
+}
+
+func TestAESGCMTestVectors(t *testing.T) {
+	for i, vector := range gcmTestVectors() {
+		gcm := AESGCM{Key: mustDecodeHex(t, vector.key), Nonce: mustDecodeHex(t, vector.nonce)}
+		msg := mustDecodeHex(t, vector.msg)
+		aad := mustDecodeHex(t, vector.aad)
+		expectedCtxt := mustDecodeHex(t, vector.ctxt)
+		expectedTag := mustDecodeHex(t, vector.tag)
+
+		ctxt, tag, err := gcm.Encrypt(msg, aad)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCtxt, ctxt, "Test case %d", i+1)
+		assert.Equal(t, expectedTag, tag, "Test case %d", i+1)
+
+		decrypted, err := gcm.Decrypt(ctxt, aad, tag)
+		assert.Nil(t, err)
+		assert.Equal(t, msg, decrypted, "Test case %d", i+1)
+	}
+}
+
+func TestAESGCMMatchesStandardLibrary(t *testing.T) {
+	key := make([]byte, 16)
+	rand.Read(key)
+
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, nonceSize := range []int{AESGCMNonceSize, 8, 60} {
+		reference, err := cipher.NewGCMWithNonceSize(block, nonceSize)
+		assert.Nil(t, err)
+
+		for _, size := range []int{0, 1, 16, 33, 100} {
+			nonce := make([]byte, nonceSize)
+			rand.Read(nonce)
+			msg := make([]byte, size)
+			rand.Read(msg)
+			aad := make([]byte, size/2)
+			rand.Read(aad)
+
+			gcm := AESGCM{Key: key, Nonce: nonce}
+			ctxt, tag, err := gcm.Encrypt(msg, aad)
+			assert.Nil(t, err)
+
+			expected := reference.Seal(nil, nonce, msg, aad)
+			assert.Equal(t, expected, append(ctxt, tag...))
+		}
+	}
+}
+
+func TestAESGCMDecryptRejectsInvalidTag(t *testing.T) {
+	key, err := NewKey()
+	assert.Nil(t, err)
+	gcm := AESGCM{Key: key, Nonce: make([]byte, AESGCMNonceSize)}
+
+	msg := []byte("Attack at dawn")
+	aad := []byte("header")
+	ctxt, tag, err := gcm.Encrypt(msg, aad)
+	assert.Nil(t, err)
+
+	tampered := make([]byte, len(ctxt))
+	copy(tampered, ctxt)
+	tampered[0] ^= 0x01
+	_, err = gcm.Decrypt(tampered, aad, tag)
+	assert.Error(t, err)
+
+	_, err = gcm.Decrypt(ctxt, []byte("other header"), tag)
+	assert.Error(t, err)
+
+	_, err = gcm.Decrypt(ctxt, aad, tag[:8])
+	assert.Error(t, err)
+
+	gcm.Nonce = []byte{}
+	_, _, err = gcm.Encrypt(msg, aad)
+	assert.Error(t, err)
+}
+
+func TestAESGCMAllowsNonceReuse(t *testing.T) {
+	key, err := NewKey()
+	assert.Nil(t, err)
+	gcm := AESGCM{Key: key, Nonce: make([]byte, AESGCMNonceSize)}
+
+	a := []byte("YELLOW SUBMARINE")
+	b := []byte("PURPLE SUBMARINE")
+	ctxtA, _, err := gcm.Encrypt(a, nil)
+	assert.Nil(t, err)
+	ctxtB, _, err := gcm.Encrypt(b, nil)
+	assert.Nil(t, err)
+
+	// Same keystream, so the XOR of the ciphertexts is the XOR of the
+	// messages.
+	for i := range a {
+		assert.Equal(t, a[i]^b[i], ctxtA[i]^ctxtB[i])
+	}
+}
+
+func TestGHASH(t *testing.T) {
+	// H and GHASH(H, A, C) of test case 4
+	gcm := AESGCM{Key: mustDecodeHex(t, "feffe9928665731c6d6a8f9467308308"), Nonce: make([]byte, AESGCMNonceSize)}
+	h, err := gcm.H()
+	assert.Nil(t, err)
+	assert.Equal(t, "b83b533708bf535d0aa6e52980d53b78", h.String())
+
+	vector := gcmTestVectors()[3]
+	y := GHASH(h, mustDecodeHex(t, vector.aad), mustDecodeHex(t, vector.ctxt))
+	assert.Equal(t, "698e57f70e6ecc7fd9463b7260a9ae5f", y.String())
+}
+
+func TestAESGCMTruncatedTag(t *testing.T) {
+	vector := gcmTestVectors()[3]
+	gcm := AESGCM{Key: mustDecodeHex(t, vector.key), Nonce: mustDecodeHex(t, vector.nonce), TagSize: 4}
+	msg := mustDecodeHex(t, vector.msg)
+	aad := mustDecodeHex(t, vector.aad)
+
+	ctxt, tag, err := gcm.Encrypt(msg, aad)
+	assert.Nil(t, err)
+	assert.Equal(t, mustDecodeHex(t, vector.ctxt), ctxt)
+	assert.Equal(t, mustDecodeHex(t, vector.tag)[:4], tag)
+
+	decrypted, err := gcm.Decrypt(ctxt, aad, tag)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	// The full tag is not accepted
+	_, err = gcm.Decrypt(ctxt, aad, mustDecodeHex(t, vector.tag))
+	assert.Error(t, err)
+
+	gcm.TagSize = 17
+	_, _, err = gcm.Encrypt(msg, aad)
+	assert.Error(t, err)
+}
@@ -1,8 +1,10 @@
 package cipher
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/Lavode/cryptopals/randutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,3 +14,26 @@ func TestNewKey(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 16, len(key))
 }
+
+func TestNewKeyFrom(t *testing.T) {
+	key1, err := NewKeyFrom(randutil.NewSeeded(42))
+	assert.Nil(t, err)
+	assert.Equal(t, 16, len(key1))
+
+	key2, err := NewKeyFrom(randutil.NewSeeded(42))
+	assert.Nil(t, err)
+	assert.Equal(t, key1, key2)
+
+	_, err = NewKeyFrom(bytes.NewReader(make([]byte, 15)))
+	assert.NotNil(t, err)
+}
+
+func TestNewIV(t *testing.T) {
+	iv, err := NewIV(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, AESBlockSize, len(iv))
+
+	iv, err = NewIV(bytes.NewReader(make([]byte, 16)))
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 16), iv)
+}
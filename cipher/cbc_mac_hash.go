@@ -0,0 +1,16 @@
+package cipher
+
+// CBCMACHashKey is the fixed, public key used by CBCMACHash.
+var CBCMACHashKey = []byte("YELLOW SUBMARINE")
+
+// CBCMACHash calculates the CBC-MAC of the message under a fixed, public key
+// and a zero IV, and uses the result as a hash of the message.
+//
+// As the key is public, anyone can calculate the CBC-MAC state for any
+// message, and more importantly invert the block cipher. This makes finding
+// collisions trivial, so this must not be used as a cryptographic hash
+// function.
+func CBCMACHash(msg []byte) ([]byte, error) {
+	cbc := AESCBC{Key: CBCMACHashKey, IV: make([]byte, AESBlockSize)}
+	return cbc.MAC(msg)
+}
@@ -0,0 +1,14 @@
+package cipher
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBCMACHash(t *testing.T) {
+	hash, err := CBCMACHash([]byte("alert('MZA who was that?');\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "296b8d7cb78a243dda4d0a61d33bbdd1", hex.EncodeToString(hash))
+}
@@ -0,0 +1,60 @@
+package cipher
+
+import (
+	"fmt"
+)
+
+// RC4 encapsulates an instance of the RC4 stream cipher.
+//
+// The key may be between 1 and 256 bytes long. A fresh random key of
+// AESKeySize bytes, as generated by NewKey(), is a reasonable choice.
+//
+// The first bytes of RC4's keystream are significantly biased, so the same
+// plaintext encrypted under many different keys leaks information about
+// itself. RC4 must thus not be used for anything of importance.
+type RC4 struct {
+	Key []byte
+}
+
+// Encrypt encrypts the message with the RC4 stream cipher.
+//
+// As RC4 has no notion of a nonce, a key must never be used to encrypt more
+// than one message.
+func (rc4 *RC4) Encrypt(msg []byte) (ctxt []byte, err error) {
+	if len(rc4.Key) < 1 || len(rc4.Key) > 256 {
+		return []byte{}, fmt.Errorf("Expected key of length 1 to 256, but got %d", len(rc4.Key))
+	}
+
+	// Key scheduling
+	var s [256]byte
+	for i := range s {
+		s[i] = byte(i)
+	}
+
+	j := byte(0)
+	for i := 0; i < 256; i++ {
+		j += s[i] + rc4.Key[i%len(rc4.Key)]
+		s[i], s[j] = s[j], s[i]
+	}
+
+	// Keystream generation
+	ctxt = make([]byte, len(msg))
+	i := byte(0)
+	j = 0
+	for k := range msg {
+		i++
+		j += s[i]
+		s[i], s[j] = s[j], s[i]
+
+		ctxt[k] = msg[k] ^ s[s[i]+s[j]]
+	}
+
+	return ctxt, nil
+}
+
+// Decrypt decrypts the ciphertext with the RC4 stream cipher.
+//
+// Decryption is the same operation as encryption.
+func (rc4 *RC4) Decrypt(ctxt []byte) (msg []byte, err error) {
+	return rc4.Encrypt(ctxt)
+}
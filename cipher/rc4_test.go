@@ -0,0 +1,51 @@
+package cipher
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRC4EncryptAndDecrypt(t *testing.T) {
+	vectors := []struct {
+		key  string
+		msg  string
+		ctxt string
+	}{
+		{"Key", "Plaintext", "bbf316e8d940af0ad3"},
+		{"Wiki", "pedia", "1021bf0420"},
+		{"Secret", "Attack at dawn", "45a01f645fc35b383552544b9bf5"},
+	}
+
+	for _, vector := range vectors {
+		rc4 := RC4{Key: []byte(vector.key)}
+
+		ctxt, err := rc4.Encrypt([]byte(vector.msg))
+		assert.Nil(t, err)
+		assert.Equal(t, vector.ctxt, hex.EncodeToString(ctxt))
+
+		msg, err := rc4.Decrypt(ctxt)
+		assert.Nil(t, err)
+		assert.Equal(t, vector.msg, string(msg))
+	}
+}
+
+func TestRC4Keystream(t *testing.T) {
+	// Test vector from RFC 6229, 40-bit key, offset 0
+	rc4 := RC4{Key: []byte{0x01, 0x02, 0x03, 0x04, 0x05}}
+
+	keystream, err := rc4.Encrypt(make([]byte, 16))
+	assert.Nil(t, err)
+	assert.Equal(t, "b2396305f03dc027ccc3524a0a1118a8", hex.EncodeToString(keystream))
+}
+
+func TestRC4InvalidKey(t *testing.T) {
+	rc4 := RC4{Key: []byte{}}
+	_, err := rc4.Encrypt([]byte("abc"))
+	assert.Error(t, err)
+
+	rc4 = RC4{Key: make([]byte, 257)}
+	_, err = rc4.Encrypt([]byte("abc"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/analysis"
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/dh"
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/oracle"
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/Lavode/cryptopals/rsa"
+)
+
+func init() {
+	register(
+		Challenge{Number: 57, Set: 8, Title: "Diffie-Hellman Revisited: Small Subgroup Confinement", Solve: withoutSolution(dhSmallSubgroupConfinement)},
+		Challenge{Number: 58, Set: 8, Title: "Pollard's Method for Catching Kangaroos", Solve: withoutSolution(pollardKangaroo)},
+		Challenge{Number: 59, Set: 8, Title: "Elliptic Curve Diffie-Hellman and Invalid-Curve Attacks", Solve: withoutSolution(ecdhInvalidCurve)},
+		Challenge{Number: 60, Set: 8, Title: "Single-Coordinate Ladders and Insecure Twists", Solve: withoutSolution(ecdhTwistAttack)},
+		Challenge{Number: 61, Set: 8, Title: "Duplicate-Signature Key Selection in ECDSA (and RSA)", Solve: withoutSolution(duplicateSignatureKeySelection)},
+		Challenge{Number: 62, Set: 8, Title: "Key-Recovery Attacks on ECDSA with Biased Nonces", Solve: withoutSolution(ecdsaBiasedNonces)},
+		Challenge{Number: 63, Set: 8, Title: "Key-Recovery Attacks on GCM with Repeated Nonces", Solve: withoutSolution(gcmNonceReuse)},
+		Challenge{Number: 64, Set: 8, Title: "Key-Recovery Attacks on GCM with a Truncated MAC", Solve: withoutSolution(gcmTruncatedMAC)},
+	)
+}
+
+func dhSmallSubgroupConfinement() error {
+	params := dh.SubgroupParameters()
+	or := oracle.DHResponder{Parameters: params, Rand: random}
+
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error retrieving Bob's public key: %v", err)
+	}
+	log.Printf("Bob's public key: %v", pub.Y)
+
+	x, m, err := analysis.DHSubgroupConfinementFrom(random, params, &or, 1<<16)
+	if err != nil {
+		return fmt.Errorf("Error recovering Bob's private key: %v", err)
+	}
+	if m.Cmp(params.Q) <= 0 {
+		return fmt.Errorf("Only recovered private key modulo %v", m)
+	}
+
+	recovered := dh.NewPrivateKey(params, x)
+	log.Printf("Recovered private key %v with public key %v", x, recovered.Y)
+
+	return nil
+}
+
+func pollardKangaroo() error {
+	params := dh.KangarooParameters()
+
+	ys := []string{
+		"7760073848032689505395005705677365876654629189298052775754597607446617558600394076764814236081991643094239886772481052254010323780165093955236429914607119",
+		"9388897478013399550694114614498790691034187453089355259602614074132918843899833277397448144245883225611726912025846772975325932794909655215329941809013733",
+	}
+	bounds := []uint{20, 40}
+	for i, s := range ys {
+		y, _ := new(big.Int).SetString(s, 10)
+		b := new(big.Int).Lsh(big.NewInt(1), bounds[i])
+
+		x, ops, err := analysis.Kangaroo(params.G, y, params.P, big.NewInt(0), b, analysis.KangarooJumps{})
+		if err != nil {
+			return fmt.Errorf("Error catching kangaroo in [0, 2^%d]: %v", bounds[i], err)
+		}
+		log.Printf("Found x = %v in [0, 2^%d] with %d group operations", x, bounds[i], ops)
+	}
+
+	or := oracle.DHResponder{Parameters: params, Rand: random}
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error retrieving Bob's public key: %v", err)
+	}
+
+	residue, modulus, err := analysis.DHSubgroupConfinementFrom(random, params, &or, 1<<16)
+	if err != nil {
+		return fmt.Errorf("Error recovering Bob's private key modulo small factors: %v", err)
+	}
+	log.Printf("Recovered private key modulo %d-bit product of small factors: %v", modulus.BitLen(), residue)
+
+	x, ops, err := analysis.KangarooFromResidue(params.G, pub.Y, params.P, params.Q, residue, modulus, analysis.KangarooJumps{})
+	if err != nil {
+		return fmt.Errorf("Error recovering Bob's private key: %v", err)
+	}
+
+	recovered := dh.NewPrivateKey(params, x)
+	if recovered.Y.Cmp(pub.Y) != 0 {
+		return fmt.Errorf("Recovered private key %v does not match public key", x)
+	}
+	log.Printf("Recovered private key %v with %d group operations", x, ops)
+
+	return nil
+}
+
+func ecdhInvalidCurve() error {
+	curve := ec.ToyCurve()
+	or := oracle.ECDHResponder{Curve: curve, Rand: random}
+
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error retrieving Bob's public key: %v", err)
+	}
+	log.Printf("Bob's public key: (%v, %v)", pub.Q.X, pub.Q.Y)
+
+	d, m, err := analysis.ECInvalidCurveFrom(random, curve, ec.ToyInvalidCurves(), &or, 1<<16)
+	if err != nil {
+		return fmt.Errorf("Error recovering Bob's private key: %v", err)
+	}
+	if m.Cmp(curve.N) <= 0 {
+		return fmt.Errorf("Only recovered private key modulo %v", m)
+	}
+
+	recovered := ec.NewPrivateKey(curve, d)
+	if !recovered.Q.Equal(pub.Q) {
+		return fmt.Errorf("Recovered private key %v does not match public key", d)
+	}
+	log.Printf("Recovered private key %v", d)
+
+	return nil
+}
+
+func ecdhTwistAttack() error {
+	curve := ec.ToyMontgomeryCurve()
+	or := oracle.MontgomeryECDHResponder{Curve: curve, Rand: random}
+
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error retrieving Bob's public key: %v", err)
+	}
+	log.Printf("Bob's public key: u = %v", pub.U)
+
+	log.Printf("Catching kangaroos, this will take a while")
+	d, ops, err := analysis.ECTwistAttackFrom(random, curve, pub.U, &or, 1<<22, analysis.KangarooJumps{})
+	if err != nil {
+		return fmt.Errorf("Error recovering Bob's private key: %v", err)
+	}
+	log.Printf("Recovered private key %v (or its negation) with %d group operations", d, ops)
+
+	return nil
+}
+
+func duplicateSignatureKeySelection() error {
+	msg := []byte("I owe Eve 1 dollar")
+
+	ecPriv, err := ec.GenerateKeyFrom(random, ec.ToyCurve())
+	if err != nil {
+		return fmt.Errorf("Error generating ECDSA key: %v", err)
+	}
+	ecSig, err := ecPriv.SignFrom(random, crypto.SHA256, msg)
+	if err != nil {
+		return fmt.Errorf("Error signing message with ECDSA: %v", err)
+	}
+	log.Printf("ECDSA signature of %q: r = %v, s = %v", msg, ecSig.R, ecSig.S)
+
+	eveEC, err := analysis.ECDSADuplicateKeyFrom(random, ecPriv.PublicKey, crypto.SHA256, msg, ecSig)
+	if err != nil {
+		return fmt.Errorf("Error creating duplicate ECDSA key: %v", err)
+	}
+	log.Printf("Eve's ECDSA key: G' = (%v, %v), Q' = (%v, %v)", eveEC.G.X, eveEC.G.Y, eveEC.Q.X, eveEC.Q.Y)
+	if !eveEC.Verify(crypto.SHA256, msg, ecSig) {
+		return fmt.Errorf("Signature not valid under Eve's ECDSA key")
+	}
+	log.Printf("Signature valid under Eve's ECDSA key")
+
+	rsaPriv, err := rsa.GenerateKeyFrom(random, 1024, rsa.DefaultExponent)
+	if err != nil {
+		return fmt.Errorf("Error generating RSA key: %v", err)
+	}
+	rsaSig, err := rsaPriv.Sign(crypto.SHA256, msg)
+	if err != nil {
+		return fmt.Errorf("Error signing message with RSA: %v", err)
+	}
+	log.Printf("RSA signature of %q: %x", msg, rsaSig)
+
+	eveRSA, err := analysis.RSADuplicateKeyFrom(random, rsaPriv.PublicKey, crypto.SHA256, msg, rsaSig)
+	if err != nil {
+		return fmt.Errorf("Error creating duplicate RSA key: %v", err)
+	}
+	log.Printf("Eve's RSA key: N' = %x, e' = %x", eveRSA.N, eveRSA.E)
+	if !eveRSA.Verify(crypto.SHA256, msg, rsaSig) {
+		return fmt.Errorf("Signature not valid under Eve's RSA key")
+	}
+	log.Printf("Signature valid under Eve's RSA key")
+
+	return nil
+}
+
+func ecdsaBiasedNonces() error {
+	or := oracle.ECDSABiasedSigner{Curve: ec.P256(), Bits: 8, Rand: random}
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error retrieving public key: %v", err)
+	}
+	log.Printf("Public key: (%x, %x)", pub.Q.X, pub.Q.Y)
+
+	msgs := make([]analysis.ECDSASignedMessage, 36)
+	for i := range msgs {
+		msg := []byte(fmt.Sprintf("Message number %d", i))
+		sig, err := or.Sign(msg)
+		if err != nil {
+			return fmt.Errorf("Error signing message: %v", err)
+		}
+
+		msgs[i] = analysis.ECDSASignedMessage{Msg: msg, Signature: sig}
+	}
+	log.Printf("Collected %d signatures with nonces whose lowest %d bits are zero", len(msgs), or.Bits)
+
+	priv, err := analysis.ECDSAKeyFromBiasedNonces(pub, crypto.SHA256, msgs, or.Bits)
+	if err != nil {
+		return fmt.Errorf("Error recovering private key: %v", err)
+	}
+	log.Printf("Recovered private key %x", priv.D)
+
+	return nil
+}
+
+func gcmNonceReuse() error {
+	or := oracle.GCMFixedNonce{Rand: random}
+	aad := []byte("Content-Type: text/plain")
+	known := []byte("Transfer 100 dollars from Alice to Bob")
+
+	msgs := make([]analysis.GCMMessage, 0)
+	for _, msg := range [][]byte{known, []byte("Transfer 5 dollars from Bob to Carol")} {
+		ctxt, tag, err := or.Encrypt(msg, aad)
+		if err != nil {
+			return fmt.Errorf("Error encrypting message: %v", err)
+		}
+
+		msgs = append(msgs, analysis.GCMMessage{AAD: aad, Ciphertext: ctxt, Tag: tag})
+	}
+	log.Printf("Collected %d messages encrypted under the same nonce", len(msgs))
+
+	candidates, err := analysis.GCMRecoverAuthenticationKeyFrom(random, msgs)
+	if err != nil {
+		return fmt.Errorf("Error recovering authentication key: %v", err)
+	}
+	log.Printf("Found %d candidates for H: %v", len(candidates), candidates)
+
+	forged, err := analysis.GCMRewriteCiphertext(msgs[0].Ciphertext, known, []byte("Transfer 999 dollars from Alice to Eve"))
+	if err != nil {
+		return fmt.Errorf("Error rewriting ciphertext: %v", err)
+	}
+
+	h, tag, msg, err := analysis.GCMNonceReuseForgeryFrom(random, msgs, aad, forged, &or)
+	if err != nil {
+		return fmt.Errorf("Error forging message: %v", err)
+	}
+	log.Printf("Recovered H = %v", h)
+	log.Printf("Forged tag %x accepted for message: %s", tag, msg)
+
+	return nil
+}
+
+func gcmTruncatedMAC() error {
+	// The challenge suggests 32 bit tags, which takes about 2^16 queries
+	// for the first forgery alone. 24 bit tags show the same attack in
+	// reasonable time.
+	or := oracle.GCMTruncatedTag{TagSize: 3, Rand: random}
+
+	msg := make([]byte, ((1<<17)-1)*cipher.AESBlockSize)
+	if _, err := io.ReadFull(randutil.Reader(random), msg); err != nil {
+		return fmt.Errorf("Error generating message: %v", err)
+	}
+
+	nonce, ctxt, tag, err := or.Encrypt(msg, nil)
+	if err != nil {
+		return fmt.Errorf("Error encrypting message: %v", err)
+	}
+	log.Printf("Encrypted message of %d blocks with %d bit tag %x", len(ctxt)/cipher.AESBlockSize, len(tag)*8, tag)
+
+	h, queries, err := analysis.GCMTruncatedMACAttackFrom(random, analysis.GCMMessage{Nonce: nonce, Ciphertext: ctxt, Tag: tag}, &or)
+	if err != nil {
+		return fmt.Errorf("Error recovering authentication key: %v", err)
+	}
+	log.Printf("Recovered H = %v with %d oracle queries", h, queries)
+
+	forged := []byte("Attack at dawn")
+	forgedTag := analysis.GCMForgeTag(h, analysis.GCMMessage{Ciphertext: ctxt, Tag: tag}, nil, forged)
+	ok, err := or.Verify(nonce, forged, nil, forgedTag)
+	if err != nil {
+		return fmt.Errorf("Error verifying forgery: %v", err)
+	}
+	if !ok {
+		return fmt.Errorf("Forged tag %x for %q rejected", forgedTag, forged)
+	}
+	log.Printf("Forged tag %x for %q accepted", forgedTag, forged)
+
+	return nil
+}
@@ -3,143 +3,155 @@ package main
 import (
 	"encoding/base64"
 	"encoding/hex"
-	"log"
+	"fmt"
 
 	"github.com/Lavode/cryptopals/analysis"
 	"github.com/Lavode/cryptopals/bitwise"
 	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/data"
 )
 
-func hexToBase64() {
-	header(1, "Convert hex to base64")
+func init() {
+	register(
+		Challenge{Number: 1, Set: 1, Title: "Convert hex to base64", Solve: hexToBase64},
+		Challenge{Number: 2, Set: 1, Title: "Fixed XOR", Solve: fixedXor},
+		Challenge{Number: 3, Set: 1, Title: "Single-byte XOR cipher", Solve: singleByteXor},
+		Challenge{Number: 4, Set: 1, Title: "Detect single-character XOR", Solve: detectSingleByteXor},
+		Challenge{Number: 5, Set: 1, Title: "Implementing repeating-key XOR", Solve: repeatingKeyXor},
+		Challenge{Number: 6, Set: 1, Title: "Break repeating-key XOR", Solve: breakRepeatingKeyXor},
+		Challenge{Number: 7, Set: 1, Title: "AES in ECB mode", Solve: decryptAesECB},
+		Challenge{Number: 8, Set: 1, Title: "Detect AES in ECB mode", Solve: detectAesEcb},
+	)
+}
 
+func hexToBase64() (Solution, error) {
 	in := "49276d206b696c6c696e6720796f757220627261696e206c696b65206120706f69736f6e6f7573206d757368726f6f6d"
 	bytes, err := hex.DecodeString(in)
 	if err != nil {
-		log.Fatalf("Error decoding hex string: %v", err)
+		return Solution{}, fmt.Errorf("Error decoding hex string: %v", err)
 	}
 
 	out := base64.StdEncoding.EncodeToString(bytes)
 
-	log.Printf("Converted hex value %s (= %s) to base64: %s", in, bytes, out)
+	return Solution{Plaintext: bytes, Output: out}, nil
 }
 
-func fixedXor() {
-	header(2, "Fixed XOR")
-
+func fixedXor() (Solution, error) {
 	a := "1c0111001f010100061a024b53535009181c"
 	b := "686974207468652062756c6c277320657965"
 
 	aBytes, err := hex.DecodeString(a)
 	if err != nil {
-		log.Fatalf("Error decoding hex: %v", err)
+		return Solution{}, fmt.Errorf("Error decoding hex: %v", err)
 	}
 
 	bBytes, err := hex.DecodeString(b)
 	if err != nil {
-		log.Fatalf("Error decoding hex: %v", err)
+		return Solution{}, fmt.Errorf("Error decoding hex: %v", err)
 	}
 
 	xor := bitwise.Xor(aBytes, bBytes)
-	xorEnc := hex.EncodeToString(xor)
-	log.Printf("%s XOR %s = %s", a, b, xorEnc)
-}
 
-func singleByteXor() {
-	header(3, "Single-byte XOR cipher")
+	return Solution{Plaintext: xor, Key: bBytes, Output: hex.EncodeToString(xor)}, nil
+}
 
+func singleByteXor() (Solution, error) {
 	ctxt := "1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736"
 	ctxtBytes, err := hex.DecodeString(ctxt)
 	if err != nil {
-		log.Fatalf("Error decoding hex: %v", err)
+		return Solution{}, fmt.Errorf("Error decoding hex: %v", err)
 	}
 
-	msg, key, dist := analysis.SingleByteXor(ctxtBytes)
-	log.Printf("Most likely key: %x, distance = %f\nMessage: %s", key, dist, msg)
-}
+	msg, key, _ := analysis.SingleByteXor(ctxtBytes)
 
-func detectSingleByteXor() {
-	header(4, "Detect single-character XOR")
+	return Solution{Plaintext: msg, Key: []byte{key}}, nil
+}
 
-	ctxts, err := GetLines(4, Hex)
+func detectSingleByteXor() (Solution, error) {
+	ctxts, err := data.GetLines(4, data.Hex)
 	if err != nil {
-		log.Fatal(err)
+		return Solution{}, err
 	}
 
 	var bestDist float64 = 2
 	var bestKey byte
 	var bestMsg []byte
-	for _, ctxt := range ctxts {
+	bestLine := -1
+	for i, ctxt := range ctxts {
 		msg, key, dist := analysis.SingleByteXor(ctxt)
 		if dist < bestDist {
 			bestDist = dist
 			bestKey = key
 			bestMsg = msg
+			bestLine = i
 		}
 	}
 
-	log.Printf("Most likely Key = %x, distance = %f\nPlaintext = %s", bestKey, bestDist, bestMsg)
-}
+	if bestLine == -1 {
+		return Solution{}, fmt.Errorf("No line decrypted to English text")
+	}
 
-func repeatingKeyXor() {
-	header(5, "Implementing repeating-key XOR")
+	return Solution{
+		Plaintext: bestMsg,
+		Key:       []byte{bestKey},
+		Output:    fmt.Sprintf("Line %d", bestLine+1),
+	}, nil
+}
 
+func repeatingKeyXor() (Solution, error) {
 	input := []byte("Burning 'em, if you ain't quick and nimble\nI go crazy when I hear a cymbal")
 	key := []byte("ICE")
 
 	out := bitwise.Xor(input, key)
-	log.Printf("Encoded %s to %x", input, out)
-}
 
-func breakRepeatingKeyXor() {
-	header(6, "Break repeating-key XOR")
+	return Solution{Plaintext: input, Key: key, Output: hex.EncodeToString(out)}, nil
+}
 
-	ctxt, err := GetData(6, Base64)
+func breakRepeatingKeyXor() (Solution, error) {
+	ctxt, err := data.GetData(6, data.Base64)
 	if err != nil {
-		log.Fatal(err)
+		return Solution{}, err
 	}
 
-	msg, key, distance := analysis.RepeatingByteXor(ctxt)
-	log.Printf("Recovered key = %x, distance = %f, message = %s", key, distance, msg)
-}
+	msg, key, _ := analysis.RepeatingByteXor(ctxt)
 
-func decryptAesECB() {
-	header(7, "AES in ECB mode")
+	return Solution{Plaintext: msg, Key: key}, nil
+}
 
+func decryptAesECB() (Solution, error) {
 	key := []byte("YELLOW SUBMARINE")
 
-	ctxt, err := GetData(7, Base64)
+	ctxt, err := data.GetData(7, data.Base64)
 	if err != nil {
-		log.Fatal(err)
+		return Solution{}, err
 	}
 
 	aes := cipher.AESECB{Key: key}
 	msg, err := aes.Decrypt(ctxt)
 
 	if err != nil {
-		log.Fatalf("Error decrypting AES ciphertext: %v", err)
+		return Solution{}, fmt.Errorf("Error decrypting AES ciphertext: %v", err)
 	}
 
-	log.Printf("Decrypted message to:\n%s", msg)
+	return Solution{Plaintext: msg, Key: key}, nil
 }
 
-func detectAesEcb() {
-	header(8, "Detect AES in ECB mode")
-
-	ctxts, err := GetLines(8, Hex)
+func detectAesEcb() (Solution, error) {
+	ctxts, err := data.GetLines(8, data.Hex)
 	if err != nil {
-		log.Fatal(err)
+		return Solution{}, err
 	}
 
-	for _, ctxt := range ctxts {
+	detected := make([]string, 0)
+	for i, ctxt := range ctxts {
 		if analysis.DetectECB(ctxt) {
-			log.Printf("Ciphertext %x is likely ECB encryption", ctxt)
+			detected = append(detected, fmt.Sprintf("Line %d: %x", i+1, ctxt))
 		}
-
 	}
 
-}
+	if len(detected) != 1 {
+		return Solution{}, fmt.Errorf("Expected exactly one ECB ciphertext, found %d", len(detected))
+	}
 
-func header(id int, name string) {
-	log.Printf("==== Challenge %d: %s ====", id, name)
+	return Solution{Output: detected[0]}, nil
 }
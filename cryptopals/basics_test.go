@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// vanillaIce is the start of the plaintext of several challenges.
+const vanillaIce = "I'm back and I'm ringin' the bell \nA rockin' on the mike while the fly girls yell \n"
+
+func TestHexToBase64(t *testing.T) {
+	solution, err := hexToBase64()
+	assert.Nil(t, err)
+	assert.Equal(t, "SSdtIGtpbGxpbmcgeW91ciBicmFpbiBsaWtlIGEgcG9pc29ub3VzIG11c2hyb29t", solution.Output)
+	assert.Equal(t, "I'm killing your brain like a poisonous mushroom", string(solution.Plaintext))
+}
+
+func TestFixedXor(t *testing.T) {
+	solution, err := fixedXor()
+	assert.Nil(t, err)
+	assert.Equal(t, "746865206b696420646f6e277420706c6179", solution.Output)
+	assert.Equal(t, "the kid don't play", string(solution.Plaintext))
+}
+
+func TestSingleByteXor(t *testing.T) {
+	solution, err := singleByteXor()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("X"), solution.Key)
+	assert.Equal(t, "Cooking MC's like a pound of bacon", string(solution.Plaintext))
+}
+
+func TestDetectSingleByteXor(t *testing.T) {
+	solution, err := detectSingleByteXor()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("5"), solution.Key)
+	assert.Equal(t, "Now that the party is jumping\n", string(solution.Plaintext))
+	assert.Equal(t, "Line 171", solution.Output)
+}
+
+func TestRepeatingKeyXor(t *testing.T) {
+	solution, err := repeatingKeyXor()
+	assert.Nil(t, err)
+	assert.Equal(t,
+		"0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c2a26226324272765272a282b2f20430a652e2c652a3124333a653e2b2027630c692b20283165286326302e27282f",
+		solution.Output,
+	)
+}
+
+func TestBreakRepeatingKeyXor(t *testing.T) {
+	solution, err := breakRepeatingKeyXor()
+	assert.Nil(t, err)
+	assert.Equal(t, "Terminator X: Bring the noise", string(solution.Key))
+	assert.Equal(t, vanillaIce, string(solution.Plaintext[:len(vanillaIce)]))
+}
+
+func TestDecryptAesECB(t *testing.T) {
+	solution, err := decryptAesECB()
+	assert.Nil(t, err)
+	assert.Equal(t, vanillaIce, string(solution.Plaintext[:len(vanillaIce)]))
+}
+
+func TestDetectAesEcb(t *testing.T) {
+	solution, err := detectAesEcb()
+	assert.Nil(t, err)
+	assert.Equal(t, "Line 133: d880619740a8a19b7840a8a31c810a3d", solution.Output[:42])
+}
@@ -1,29 +1,36 @@
 package main
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/Lavode/cryptopals/analysis"
 	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/data"
 	"github.com/Lavode/cryptopals/oracle"
 	"github.com/Lavode/cryptopals/padding"
 )
 
-func pkcs7Padding() {
-	header(9, "Implement PKCS#7 padding")
+func init() {
+	register(
+		Challenge{Number: 9, Set: 2, Title: "Implement PKCS#7 padding", Solve: pkcs7Padding},
+		Challenge{Number: 10, Set: 2, Title: "Implement CBC mode", Solve: cbcDecrypt},
+		Challenge{Number: 11, Set: 2, Title: "An ECB/CBC detection oracle", Solve: ecbCbcOracle},
+		Challenge{Number: 12, Set: 2, Title: "Byte-at-a-time ECB decryption (Simple)", Solve: ecbByteAtATime},
+		Challenge{Number: 13, Set: 2, Title: "ECB cut-and-paste", Solve: ecbCutAndPaste},
+	)
+}
 
+func pkcs7Padding() (Solution, error) {
 	msg := []byte("YELLOW SUBMARINE")
 	padded := padding.PKCS7Pad(msg, 20)
 
-	log.Printf("Message %x padded to %x", msg, padded)
+	return Solution{Plaintext: padded, Output: fmt.Sprintf("%q", padded)}, nil
 }
 
-func cbcDecrypt() {
-	header(10, "Implement CBC mode")
-
-	ctxt, err := GetData(10, Base64)
+func cbcDecrypt() (Solution, error) {
+	ctxt, err := data.GetData(10, data.Base64)
 	if err != nil {
-		log.Fatal(err)
+		return Solution{}, err
 	}
 
 	key := []byte("YELLOW SUBMARINE")
@@ -33,15 +40,13 @@ func cbcDecrypt() {
 	aes := cipher.AESCBC{Key: key, IV: iv}
 	msg, err := aes.Decrypt(ctxt)
 	if err != nil {
-		log.Fatalf("Error decrypting AES-CBC ciphertext: %v", err)
+		return Solution{}, fmt.Errorf("Error decrypting AES-CBC ciphertext: %v", err)
 	}
 
-	log.Printf("Decrypted AES-CBC ciphertext: %s", msg)
+	return Solution{Plaintext: msg, Key: key}, nil
 }
 
-func ecbCbcOracle() {
-	header(11, "An ECB/CBC detection oracle")
-
+func ecbCbcOracle() (Solution, error) {
 	// We might 'lose' up to (nearly) one block to the random prefix, one
 	// to the random postfix.
 	// Thus if we supply four blocks' worth of zero bytes, we are
@@ -49,54 +54,40 @@ func ecbCbcOracle() {
 	// allows to easily detect the usage of ECB.
 	msg := make([]byte, 64)
 
-	log.Println("Starting ECB/CBC detection challenge")
-	oracle := oracle.ECBOrCBC{}
+	oracle := oracle.ECBOrCBC{Rand: random}
 
 	attempts := 50
-	correctGuesses := 0
 	for i := 0; i < attempts; i++ {
 		ctxt, err, wasECB := oracle.Encrypt(msg)
 		if err != nil {
-			log.Fatalf("Error querying oracle: %v", err)
+			return Solution{}, fmt.Errorf("Error querying oracle: %v", err)
 		}
 
 		guessECB := analysis.DetectECB(ctxt.Bytes)
-		correct := guessECB == wasECB
-		if correct {
-			correctGuesses++
+		if guessECB != wasECB {
+			return Solution{}, fmt.Errorf("Attempt %d: Guessed ECB = %t, but was ECB = %t", i, guessECB, wasECB)
 		}
-
-		log.Printf(
-			"Attempt %d: Was ECB: %t, Our guess: %t, Correct: %t",
-			i,
-			wasECB,
-			guessECB,
-			correct,
-		)
 	}
-	log.Printf("%d / %d attempts correct", attempts, correctGuesses)
 
+	return Solution{Output: fmt.Sprintf("%d / %d guesses correct", attempts, attempts), Queries: attempts}, nil
 }
 
-func ecbByteAtATime() {
-	header(12, "Byte-at-a-time ECB decryption (Simple)")
-
+func ecbByteAtATime() (Solution, error) {
 	// 'Secret' payload we intend to decrypt
-	payload, err := GetData(12, Base64)
+	payload, err := data.GetData(12, data.Base64)
 	if err != nil {
-		log.Fatal(err)
+		return Solution{}, err
 	}
 
-	oracle := oracle.ECBInfix{Postfix: payload, PrefixLength: 0}
+	oracle := oracle.ECBInfix{Postfix: payload, PrefixLength: 0, Rand: random}
 
 	blockSize, err := analysis.DetectBlockSize(&oracle)
 	if err != nil {
-		log.Fatalf("Error deducing block size: %v", err)
+		return Solution{}, fmt.Errorf("Error deducing block size: %v", err)
 	}
-	log.Printf("Deduced block size: %dB", blockSize)
 
 	if blockSize != cipher.AESBlockSize {
-		log.Fatalf("Deduced unsupported block size: %dB; must be 16B", blockSize)
+		return Solution{}, fmt.Errorf("Deduced unsupported block size: %dB; must be 16B", blockSize)
 	}
 
 	// With four blocks' worth of zero bytes we're guaranteed to have at
@@ -104,28 +95,26 @@ func ecbByteAtATime() {
 	msg := make([]byte, 4*blockSize)
 	ctxt, err := oracle.Encrypt(msg)
 	if err != nil {
-		log.Fatalf("Error querying oracle: %v", err)
+		return Solution{}, fmt.Errorf("Error querying oracle: %v", err)
 	}
 
 	usesECB := analysis.DetectECB(ctxt.Bytes)
 	if !usesECB {
-		log.Fatalf("Oracle seems to not use ECB mode")
+		return Solution{}, fmt.Errorf("Oracle seems to not use ECB mode")
 	}
-	log.Printf("Oracle seems to be using ECB mode")
 
 	postfix, err := analysis.DecryptECBPostfix(&oracle)
 	if err != nil {
-		log.Fatalf("Error decrypting ECB postfix: %v", err)
+		return Solution{}, fmt.Errorf("Error decrypting ECB postfix: %v", err)
 	}
-	log.Printf("Decrypted ECB postfix to: %s", postfix)
-}
 
-func ecbCutAndPaste() {
-	header(13, "ECB cut-and-paste")
+	return Solution{Plaintext: postfix, Queries: oracle.Queries}, nil
+}
 
+func ecbCutAndPaste() (Solution, error) {
 	ctxt := make([]byte, 3*cipher.AESBlockSize)
 
-	or := oracle.Profile{}
+	or := oracle.Profile{Rand: random}
 
 	// Recall the structure of the encrypted message:
 	// email=%s&uid=10&role=user
@@ -136,7 +125,7 @@ func ecbCutAndPaste() {
 	// bytes.
 	mailCtxt, err := or.Encrypt("johny@doe.com")
 	if err != nil {
-		log.Fatalf("Error querying encryption oracle: %v", err)
+		return Solution{}, fmt.Errorf("Error querying encryption oracle: %v", err)
 	}
 	// We only care about the first two blocks
 	copy(ctxt[:2*cipher.AESBlockSize], mailCtxt.Bytes[0:2*cipher.AESBlockSize])
@@ -149,15 +138,20 @@ func ecbCutAndPaste() {
 	email := "1234567890admin\x0B\x0B\x0B\x0B\x0B\x0B\x0B\x0B\x0B\x0B\x0B"
 	roleCtxt, err := or.Encrypt(email)
 	if err != nil {
-		log.Fatalf("Error querying encryption oracle: %v", err)
+		return Solution{}, fmt.Errorf("Error querying encryption oracle: %v", err)
 	}
 	// We only care about the second block
 	copy(ctxt[2*cipher.AESBlockSize:], roleCtxt.Bytes[1*cipher.AESBlockSize:])
 
 	prof, err := or.Decrypt(ctxt)
 	if err != nil {
-		log.Fatalf("Error querying decryption oracle: %v", err)
+		return Solution{}, fmt.Errorf("Error querying decryption oracle: %v", err)
+	}
+
+	if prof.Role != "admin" {
+		return Solution{}, fmt.Errorf("Forged profile has role %q rather than admin", prof.Role)
 	}
 
-	log.Printf("Got profile: %+v", prof)
+	// Two encryption queries, one decryption query
+	return Solution{Plaintext: []byte(prof.String()), Queries: 3}, nil
 }
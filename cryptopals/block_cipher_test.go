@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPkcs7Padding(t *testing.T) {
+	solution, err := pkcs7Padding()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("YELLOW SUBMARINE\x04\x04\x04\x04"), solution.Plaintext)
+}
+
+func TestCbcDecrypt(t *testing.T) {
+	solution, err := cbcDecrypt()
+	assert.Nil(t, err)
+	assert.Equal(t, vanillaIce, string(solution.Plaintext[:len(vanillaIce)]))
+}
+
+func TestEcbCbcOracle(t *testing.T) {
+	solution, err := ecbCbcOracle()
+	assert.Nil(t, err)
+	assert.Equal(t, 50, solution.Queries)
+}
+
+func TestEcbByteAtATime(t *testing.T) {
+	solution, err := ecbByteAtATime()
+	assert.Nil(t, err)
+	assert.Equal(t,
+		"Rollin' in my 5.0\nWith my rag-top down so my hair can blow\nThe girlies on standby waving just to say hi\nDid you stop? No, I just drove by\n",
+		string(solution.Plaintext),
+	)
+	assert.Greater(t, solution.Queries, 0)
+}
+
+func TestEcbCutAndPaste(t *testing.T) {
+	solution, err := ecbCutAndPaste()
+	assert.Nil(t, err)
+	assert.Equal(t, "email=johny@doe.com&uid=10&role=admin", string(solution.Plaintext))
+	assert.Equal(t, 3, solution.Queries)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/Lavode/cryptopals/analysis"
+	"github.com/Lavode/cryptopals/md4"
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/Lavode/cryptopals/toyhash"
+)
+
+func init() {
+	register(
+		Challenge{Number: 52, Set: 7, Title: "Iterated Hash Function Multicollisions", Solve: withoutSolution(iteratedHashMulticollisions)},
+		Challenge{Number: 53, Set: 7, Title: "Kelsey and Schneier's Expandable Messages", Solve: withoutSolution(kelseySchneierSecondPreimage)},
+		Challenge{Number: 54, Set: 7, Title: "Kelsey and Kohno's Nostradamus Attack", Solve: withoutSolution(nostradamusAttack)},
+		Challenge{Number: 55, Set: 7, Title: "MD4 Collisions", Solve: withoutSolution(md4Collisions)},
+	)
+}
+
+func iteratedHashMulticollisions() error {
+	f, err := toyhash.New(16, 0xb00b)
+	if err != nil {
+		return fmt.Errorf("Error instantiating cheap hash: %v", err)
+	}
+	g, err := toyhash.New(32, 0xdeadbeef)
+	if err != nil {
+		return fmt.Errorf("Error instantiating expensive hash: %v", err)
+	}
+
+	mc, err := analysis.JouxMulticollisionFrom(random, f, f.IV, 8)
+	if err != nil {
+		return fmt.Errorf("Error generating multicollision: %v", err)
+	}
+	log.Printf("Generated %d colliding messages with %d calls to f", mc.Count(), f.Calls)
+	f.Calls = 0
+
+	a, b, err := analysis.ConcatenatedHashCollisionFrom(random, f, g)
+	if err != nil {
+		return fmt.Errorf("Error finding collision: %v", err)
+	}
+
+	log.Printf("Found collision f(m) || g(m) = %04x || %08x", f.Sum(a), g.Sum(a))
+	log.Printf("m1 = %x", a)
+	log.Printf("m2 = %x", b)
+	log.Printf("Calls to f: %d, calls to g: %d", f.Calls, g.Calls)
+
+	return nil
+}
+
+func kelseySchneierSecondPreimage() error {
+	h, err := toyhash.New(24, 0xc0ffee)
+	if err != nil {
+		return fmt.Errorf("Error instantiating hash: %v", err)
+	}
+
+	msg := make([]byte, (1<<16)*toyhash.BlockSize)
+	_, err = io.ReadFull(randutil.Reader(random), msg)
+	if err != nil {
+		return fmt.Errorf("Error generating message: %v", err)
+	}
+	log.Printf("Hash of %d byte message: %06x", len(msg), h.Sum(msg))
+	h.Calls = 0
+
+	forged, err := analysis.SecondPreimageFrom(random, h, msg)
+	if err != nil {
+		return fmt.Errorf("Error finding second preimage: %v", err)
+	}
+	calls := h.Calls
+
+	common := 0
+	for forged[len(forged)-1-common] == msg[len(msg)-1-common] {
+		common++
+	}
+
+	log.Printf("Hash of %d byte second preimage: %06x", len(forged), h.Sum(forged))
+	log.Printf("Second preimage shares the last %d bytes with the message", common)
+	log.Printf("Calls to compression function: %d", calls)
+
+	return nil
+}
+
+func nostradamusAttack() error {
+	h, err := toyhash.New(20, 0xf00d)
+	if err != nil {
+		return fmt.Errorf("Error instantiating hash: %v", err)
+	}
+
+	prediction, err := analysis.NostradamusPredict(h, 8, 4)
+	if err != nil {
+		return fmt.Errorf("Error building prediction: %v", err)
+	}
+	log.Printf("Predicted hash of the season's results: %05x (%d calls to compression function)", prediction.Hash, h.Calls)
+
+	results := []byte("Final results: Basel 2:1 Zurich, Bern 0:3 Geneva")
+
+	h.Calls = 0
+	msg, err := prediction.Herd(h, results)
+	if err != nil {
+		return fmt.Errorf("Error herding results into prediction: %v", err)
+	}
+	log.Printf("Herded results with %d calls to compression function", h.Calls)
+	log.Printf("Message %q has hash %05x", msg, h.Sum(msg))
+
+	return nil
+}
+
+func md4Collisions() error {
+	m1, m2, trials, err := analysis.MD4CollisionFrom(random)
+	if err != nil {
+		return fmt.Errorf("Error finding MD4 collision: %v", err)
+	}
+
+	log.Printf("Found collision after %d messages", trials)
+	log.Printf("MD4(%x) = %x", m1, md4.Sum(m1))
+	log.Printf("MD4(%x) = %x", m2, md4.Sum(m2))
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+
+	"github.com/Lavode/cryptopals/analysis"
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+func init() {
+	register(
+		Challenge{Number: 49, Set: 7, Title: "CBC-MAC Message Forgery", Solve: withoutSolution(cbcMACForgery)},
+		Challenge{Number: 50, Set: 7, Title: "Hashing with CBC-MAC", Solve: withoutSolution(cbcMACHashCollision)},
+		Challenge{Number: 51, Set: 7, Title: "Compression Ratio Side-Channel Attacks", Solve: withoutSolution(compressionRatioSideChannel)},
+		Challenge{Number: 56, Set: 7, Title: "RC4 Single-Byte Biases", Solve: withoutSolution(rc4SingleByteBiases)},
+	)
+}
+
+func cbcMACForgery() error {
+	attacker := 42
+	victim := 17
+	server := oracle.TransferServer{AttackerAccount: attacker, Rand: random}
+
+	request, err := analysis.ForgeTransferV1(&server, victim, 1000000)
+	if err != nil {
+		return fmt.Errorf("Error forging version 1 request: %v", err)
+	}
+
+	transfer, err := server.HandleV1(request)
+	if err != nil {
+		return fmt.Errorf("Server rejected forged version 1 request: %v", err)
+	}
+	log.Printf("Forged version 1 request %q, server executed %+v", request, transfer)
+
+	target := oracle.Transfer{From: victim, To: attacker, Amount: 1000000}
+	if transfer != target {
+		return fmt.Errorf("Server executed %+v rather than %+v", transfer, target)
+	}
+
+	request, err = analysis.ForgeTransferV2(&server, victim, 1000000)
+	if err != nil {
+		return fmt.Errorf("Error forging version 2 request: %v", err)
+	}
+
+	transfers, err := server.HandleV2(request)
+	if err != nil {
+		return fmt.Errorf("Server rejected forged version 2 request: %v", err)
+	}
+	log.Printf("Forged version 2 request %q, server executed %+v", request, transfers)
+
+	for _, transfer := range transfers {
+		if transfer == target {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Server did not execute %+v", target)
+}
+
+func cbcMACHashCollision() error {
+	original := []byte("alert('MZA who was that?');\n")
+	target, err := cipher.CBCMACHash(original)
+	if err != nil {
+		return fmt.Errorf("Error hashing original snippet: %v", err)
+	}
+	log.Printf("Hash of original snippet: %x", target)
+
+	forged, err := analysis.CBCMACHashCollisionFrom(
+		random,
+		target,
+		original,
+		[]byte("alert('Ayo, the Wu is back!');//"),
+		analysis.JavaScriptCommentByte,
+	)
+	if err != nil {
+		return fmt.Errorf("Error forging snippet: %v", err)
+	}
+
+	hash, err := cipher.CBCMACHash(forged)
+	if err != nil {
+		return fmt.Errorf("Error hashing forged snippet: %v", err)
+	}
+	log.Printf("Forged snippet %q with hash %x", forged, hash)
+
+	return nil
+}
+
+func compressionRatioSideChannel() error {
+	sessionID := []byte("TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE=")
+
+	ciphers := []oracle.CompressionCipher{oracle.StreamCipher, oracle.BlockCipher}
+	names := []string{"stream cipher", "block cipher"}
+	for i, c := range ciphers {
+		name := names[i]
+		or := oracle.CompressionRatio{SessionID: sessionID, Cipher: c, Rand: random}
+
+		recovered, err := analysis.CompressionRatioRecover(&or, []byte("sessionid="), []byte(analysis.Base64Alphabet), '\n')
+		if err != nil {
+			return fmt.Errorf("Error recovering session ID with %s: %v", name, err)
+		}
+
+		log.Printf("Recovered session ID with %s: %s", name, recovered)
+	}
+
+	return nil
+}
+
+func rc4SingleByteBiases() error {
+	cookie, err := base64.StdEncoding.DecodeString("QkUgU1VSRSBUTyBEUklOSyBZT1VSIE9WQUxUSU5F")
+	if err != nil {
+		return fmt.Errorf("Error decoding cookie: %v", err)
+	}
+	or := oracle.RC4Cookie{Cookie: cookie, Rand: random}
+
+	samples := 1 << 24
+	log.Printf("Collecting %d ciphertexts per request length, this will take a while", samples)
+
+	recovered, err := analysis.RC4BiasRecover(&or, analysis.RC4Biases, samples, 0)
+	if err != nil {
+		return fmt.Errorf("Error recovering cookie: %v", err)
+	}
+
+	log.Printf("Recovered cookie: %q", recovered)
+
+	return nil
+}
@@ -1,56 +1,186 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
+
+	"github.com/Lavode/cryptopals/data"
 )
 
 func main() {
-	if len(os.Args) != 2 {
+	if len(os.Args) < 2 {
 		usage()
 	}
 
-	challenge, err := strconv.Atoi(os.Args[1])
+	// A single challenge number is shorthand for `run <n>`
+	if _, err := strconv.Atoi(os.Args[1]); err == nil {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	switch os.Args[1] {
+	case "list":
+		os.Exit(listCommand(os.Args[2:]))
+	case "run":
+		os.Exit(runCommand(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+// listCommand lists all implemented challenges.
+func listCommand(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Output challenges as JSON")
+	if _, err := parseInterspersed(flags, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	challenges := allChallenges()
+
+	if *asJSON {
+		type entry struct {
+			Number int    `json:"number"`
+			Set    int    `json:"set"`
+			Title  string `json:"title"`
+		}
+
+		entries := make([]entry, len(challenges))
+		for i, c := range challenges {
+			entries[i] = entry{Number: c.Number, Set: c.Set, Title: c.Title}
+		}
+
+		return writeJSON(entries)
+	}
+
+	for _, c := range challenges {
+		fmt.Printf("%2d  (set %d)  %s\n", c.Number, c.Set, c.Title)
+	}
+
+	return 0
+}
+
+// runCommand runs the selected challenges, and returns a non-zero exit code
+// if any of them failed.
+func runCommand(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	set := flags.Int("set", 0, "Run all challenges of the given set")
+	all := flags.Bool("all", false, "Run all challenges")
+	asJSON := flags.Bool("json", false, "Output results as JSON")
+	flags.StringVar(&data.Directory, "data-dir", "", "Load data files from this directory in preference to the embedded ones")
+	flags.Func("seed", "Seed the randomness of oracles and attacks, to replay a previous run", func(s string) error {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid seed: %s", s)
+		}
+
+		seed = &n
+		return nil
+	})
+
+	numbers, err := parseInterspersed(flags, args)
 	if err != nil {
-		fmt.Printf("Invalid challenge\n")
-		os.Exit(1)
-	}
-
-	switch challenge {
-	case 1:
-		hexToBase64()
-	case 2:
-		fixedXor()
-	case 3:
-		singleByteXor()
-	case 4:
-		detectSingleByteXor()
-	case 5:
-		repeatingKeyXor()
-	case 6:
-		breakRepeatingKeyXor()
-	case 7:
-		decryptAesECB()
-	case 8:
-		detectAesEcb()
-	case 9:
-		pkcs7Padding()
-	case 10:
-		cbcDecrypt()
-	case 11:
-		ecbCbcOracle()
-	case 12:
-		ecbByteAtATime()
-	case 13:
-		ecbCutAndPaste()
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var challenges []Challenge
+	switch {
+	case *all:
+		challenges = allChallenges()
+	case *set != 0:
+		challenges = challengesInSet(*set)
+		if len(challenges) == 0 {
+			fmt.Fprintf(os.Stderr, "No challenges of set %d implemented\n", *set)
+			return 1
+		}
+	case len(numbers) > 0:
+		for _, arg := range numbers {
+			number, err := strconv.Atoi(arg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid challenge: %s\n", arg)
+				return 2
+			}
+
+			c, err := lookupChallenge(number)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+
+			challenges = append(challenges, c)
+		}
 	default:
-		fmt.Println("Challenge outside of allowed range")
+		usage()
+	}
+
+	results := make([]Result, len(challenges))
+	failed := 0
+	for i, c := range challenges {
+		results[i] = c.run()
+		if !results[i].Passed {
+			failed++
+		}
+	}
+
+	if *asJSON {
+		if code := writeJSON(results); code != 0 {
+			return code
+		}
+	} else if len(results) > 1 {
+		fmt.Printf("%d / %d challenges passed\n", len(results)-failed, len(results))
+	}
+
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// parseInterspersed parses flags which may be mixed with positional
+// arguments, such as `run 1 --json 2`, and returns the positional ones.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return []string{}, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
 }
 
+func writeJSON(v interface{}) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
 func usage() {
-	fmt.Printf("Usage: ./cryptopals <challenge>\n")
-	fmt.Printf("Example: ./cryptopals 3\n")
+	fmt.Printf("Usage:\n")
+	fmt.Printf("  ./cryptopals list [--json]\n")
+	fmt.Printf("  ./cryptopals run [--json] <challenge>...\n")
+	fmt.Printf("  ./cryptopals run [--json] --set <set>\n")
+	fmt.Printf("  ./cryptopals run [--json] --all\n")
+	fmt.Printf("Challenge data is embedded, but may be overridden with --data-dir <dir>.\n")
+	fmt.Printf("Oracles and attacks may be seeded with --seed <n> to replay a run.\n")
+	fmt.Printf("Example: ./cryptopals run 3 4 5\n")
 	os.Exit(1)
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// Challenge describes a challenge of the cryptopals crypto challenges, along
+// with the function solving it.
+type Challenge struct {
+	Number int
+	Set    int
+	Title  string
+	// Solve solves the challenge, and returns its solution. It returns an
+	// error if the challenge could not be solved.
+	Solve func() (Solution, error)
+}
+
+// Solution is the outcome of solving a challenge. Fields which do not apply
+// to a challenge are left empty.
+type Solution struct {
+	// Plaintext is the recovered or constructed message.
+	Plaintext []byte
+	// Key is the recovered key.
+	Key []byte
+	// Output is any other result, such as an encoded value or a
+	// ciphertext.
+	Output string
+	// Queries is the number of oracle queries made.
+	Queries int
+}
+
+// Result is the outcome of running a challenge's solver.
+type Result struct {
+	Number   int     `json:"number"`
+	Set      int     `json:"set"`
+	Title    string  `json:"title"`
+	Passed   bool    `json:"passed"`
+	Error    string  `json:"error,omitempty"`
+	Queries  int     `json:"queries,omitempty"`
+	Duration float64 `json:"duration_seconds"`
+	Seed     *int64  `json:"seed,omitempty"`
+}
+
+var registry = make(map[int]Challenge)
+
+// seed, if set, seeds the randomness of the oracles and attacks of every
+// challenge, so that a run can be replayed exactly.
+var seed *int64
+
+// random is the source of randomness which solvers hand to their oracles and
+// attacks. It is nil, and thus a CSPRNG, unless a seed is set, in which case
+// it is re-seeded before each challenge.
+var random io.Reader
+
+// register adds challenges to the registry. It panics if a challenge is
+// registered twice, as this is a programming error.
+func register(challenges ...Challenge) {
+	for _, c := range challenges {
+		if _, ok := registry[c.Number]; ok {
+			panic(fmt.Sprintf("Challenge %d registered twice", c.Number))
+		}
+
+		registry[c.Number] = c
+	}
+}
+
+// allChallenges returns all registered challenges, ordered by their number.
+func allChallenges() []Challenge {
+	out := make([]Challenge, 0, len(registry))
+	for _, c := range registry {
+		out = append(out, c)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+
+	return out
+}
+
+// challengesInSet returns the registered challenges of the given set,
+// ordered by their number.
+func challengesInSet(set int) []Challenge {
+	out := make([]Challenge, 0)
+	for _, c := range allChallenges() {
+		if c.Set == set {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// lookupChallenge returns the challenge with the given number.
+func lookupChallenge(number int) (Challenge, error) {
+	c, ok := registry[number]
+	if !ok {
+		return Challenge{}, fmt.Errorf("Challenge %d not implemented", number)
+	}
+
+	return c, nil
+}
+
+// run runs the challenge's solver, and reports its outcome.
+func (c Challenge) run() Result {
+	header(c.Number, c.Title)
+
+	random = nil
+	if seed != nil {
+		log.Printf("Seeding randomness with %d", *seed)
+		random = randutil.NewSeeded(*seed)
+	}
+
+	start := time.Now()
+	solution, err := c.Solve()
+	duration := time.Since(start)
+
+	result := Result{
+		Number:   c.Number,
+		Set:      c.Set,
+		Title:    c.Title,
+		Passed:   err == nil,
+		Queries:  solution.Queries,
+		Duration: duration.Seconds(),
+		Seed:     seed,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		log.Printf("Challenge %d failed after %v: %v", c.Number, duration, err)
+	} else {
+		solution.log()
+		log.Printf("Challenge %d passed in %v", c.Number, duration)
+	}
+
+	return result
+}
+
+// withoutSolution adapts a solver which only logs its results, rather than
+// returning them.
+func withoutSolution(solve func() error) func() (Solution, error) {
+	return func() (Solution, error) {
+		return Solution{}, solve()
+	}
+}
+
+func (s Solution) log() {
+	if len(s.Key) > 0 {
+		log.Printf("Key: %q", s.Key)
+	}
+
+	if len(s.Plaintext) > 0 {
+		log.Printf("Plaintext:\n%s", s.Plaintext)
+	}
+
+	if s.Output != "" {
+		log.Printf("Output: %s", s.Output)
+	}
+
+	if s.Queries > 0 {
+		log.Printf("Oracle queries: %d", s.Queries)
+	}
+}
+
+func header(id int, name string) {
+	log.Printf("==== Challenge %d: %s ====", id, name)
+}
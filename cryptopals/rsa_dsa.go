@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/analysis"
+	"github.com/Lavode/cryptopals/dsa"
+	"github.com/Lavode/cryptopals/oracle"
+)
+
+func init() {
+	register(
+		Challenge{Number: 43, Set: 6, Title: "DSA key recovery from nonce", Solve: withoutSolution(dsaKeyFromNonce)},
+		Challenge{Number: 44, Set: 6, Title: "DSA nonce recovery from repeated nonce", Solve: withoutSolution(dsaRepeatedNonce)},
+		Challenge{Number: 45, Set: 6, Title: "DSA parameter tampering", Solve: withoutSolution(dsaParameterTampering)},
+		Challenge{Number: 46, Set: 6, Title: "RSA parity oracle", Solve: withoutSolution(rsaParityOracle)},
+		Challenge{Number: 47, Set: 6, Title: "Bleichenbacher's PKCS 1.5 Padding Oracle (Simple Case)", Solve: withoutSolution(bleichenbacherSimple)},
+		Challenge{Number: 48, Set: 6, Title: "Bleichenbacher's PKCS 1.5 Padding Oracle (Complete Case)", Solve: withoutSolution(bleichenbacherComplete)},
+	)
+}
+
+func dsaKeyFromNonce() error {
+	y, _ := new(big.Int).SetString(
+		"84ad4719d044495496a3201c8ff484feb45b962e7302e56a392aee4"+
+			"abab3e4bdebf2955b4736012f21a08084056b19bcd7fee56048e004"+
+			"e44984e2f411788efdc837a0d2e5abb7b555039fd243ac01f0fb2ed"+
+			"1dec568280ce678e931868d23eb095fde9d3779191b8c0299d6e07b"+
+			"bb283e6633451e535c45513b2d33c99ea17",
+		16,
+	)
+	r, _ := new(big.Int).SetString("548099063082341131477253921760299949438196259240", 10)
+	s, _ := new(big.Int).SetString("857042759984254168557880549501802188789837994940", 10)
+
+	pub := dsa.PublicKey{Parameters: dsa.DefaultParameters(), Y: y}
+	msg := []byte("For those that envy a MC it can be hazardous to your health\nSo be friendly, a matter of life and death, just like a etch-a-sketch\n")
+
+	// The nonce was chosen from [0, 2^16]
+	priv, err := analysis.DSAKeyFromSmallNonce(
+		pub, crypto.SHA1, msg, dsa.Signature{R: r, S: s},
+		big.NewInt(0), big.NewInt(1<<16),
+	)
+	if err != nil {
+		return fmt.Errorf("Error recovering private key: %v", err)
+	}
+
+	fingerprint := sha1.Sum([]byte(priv.X.Text(16)))
+	log.Printf("Recovered private key x = %x, SHA-1(hex(x)) = %x", priv.X, fingerprint)
+
+	return nil
+}
+
+func dsaRepeatedNonce() error {
+	priv, err := dsa.GenerateKeyFrom(random, dsa.DefaultParameters())
+	if err != nil {
+		return fmt.Errorf("Error generating DSA key: %v", err)
+	}
+
+	// A careless signer who picks nonces from a tiny pool will
+	// eventually use one twice.
+	texts := []string{
+		"Listen for me, you better listen for me now. ",
+		"Pure black, looking clear like a mirror, ",
+		"Dash it, more like mean and hard ",
+		"Wait a minute, I didn't mean to say that, ",
+		"And your skin is a bolt of lightning ",
+	}
+	nonces := []int64{31337, 4242, 1337, 4242, 9001}
+
+	msgs := make([]analysis.DSASignedMessage, len(texts))
+	for i, text := range texts {
+		sig, err := priv.SignWithNonce(crypto.SHA1, []byte(text), big.NewInt(nonces[i]))
+		if err != nil {
+			return fmt.Errorf("Error signing message: %v", err)
+		}
+
+		msgs[i] = analysis.DSASignedMessage{Msg: []byte(text), Signature: sig}
+	}
+
+	log.Printf("Signatures with repeated nonce: %v", analysis.FindRepeatedDSANonces(msgs))
+
+	recovered, err := analysis.DSAKeyFromRepeatedNonce(priv.PublicKey, crypto.SHA1, msgs)
+	if err != nil {
+		return fmt.Errorf("Error recovering private key: %v", err)
+	}
+
+	if recovered.X.Cmp(priv.X) != 0 {
+		return fmt.Errorf("Recovered private key x = %x does not match actual one", recovered.X)
+	}
+	log.Printf("Recovered private key x = %x", recovered.X)
+
+	return nil
+}
+
+func dsaParameterTampering() error {
+	priv, err := dsa.GenerateKeyFrom(random, dsa.DefaultParameters())
+	if err != nil {
+		return fmt.Errorf("Error generating DSA key: %v", err)
+	}
+	verifier := oracle.DSAVerifier{}
+	msgs := []string{"Hello, world", "Goodbye, world"}
+
+	// g = 0: A signature with r = 0 verifies for any message.
+	pub := priv.PublicKey
+	pub.G = big.NewInt(0)
+	sig := analysis.DSAZeroSignature()
+	for _, msg := range msgs {
+		if !verifier.Verify(pub, []byte(msg), sig) {
+			return fmt.Errorf("g = 0: Signature %+v rejected for '%s'", sig, msg)
+		}
+		log.Printf("g = 0: Signature %+v valid for '%s'", sig, msg)
+	}
+
+	// g = p + 1: A magic signature verifies for any message.
+	pub.G = new(big.Int).Add(pub.P, big.NewInt(1))
+	sig, err = analysis.DSAMagicSignature(pub, big.NewInt(1337))
+	if err != nil {
+		return fmt.Errorf("Error forging magic signature: %v", err)
+	}
+	for _, msg := range msgs {
+		if !verifier.Verify(pub, []byte(msg), sig) {
+			return fmt.Errorf("g = p + 1: Signature %+v rejected for '%s'", sig, msg)
+		}
+		log.Printf("g = p + 1: Signature %+v valid for '%s'", sig, msg)
+	}
+
+	verifier.ValidateParameters = true
+	if verifier.Verify(pub, []byte(msgs[0]), sig) {
+		return fmt.Errorf("g = p + 1: Signature accepted despite parameter validation")
+	}
+	log.Printf("g = p + 1: Signature rejected with parameter validation")
+
+	return nil
+}
+
+func rsaParityOracle() error {
+	msg, err := base64.StdEncoding.DecodeString("VGhhdCdzIHdoeSBJIGZvdW5kIHlvdSBkb24ndCBwbGF5IGFyb3VuZCB3aXRoIHRoZSBGdW5reSBDb2xkIE1lZGluYQ==")
+	if err != nil {
+		return fmt.Errorf("Error decoding base64: %v", err)
+	}
+
+	or := oracle.RSAParity{Bits: 1024, Rand: random}
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error generating RSA key: %v", err)
+	}
+
+	ctxt, err := pub.Encrypt(new(big.Int).SetBytes(msg))
+	if err != nil {
+		return fmt.Errorf("Error encrypting message: %v", err)
+	}
+
+	recovered, err := analysis.RSAParityDecrypt(pub, ctxt, &or, func(partial []byte) {
+		log.Printf("%q", partial)
+	})
+	if err != nil {
+		return fmt.Errorf("Error decrypting ciphertext: %v", err)
+	}
+
+	log.Printf("Decrypted message: %s", recovered)
+
+	return nil
+}
+
+func bleichenbacherSimple() error {
+	return bleichenbacher(256)
+}
+
+func bleichenbacherComplete() error {
+	return bleichenbacher(768)
+}
+
+func bleichenbacher(bits int) error {
+	or := oracle.RSAPKCS1v15{Bits: bits, Rand: random}
+	pub, err := or.PublicKey()
+	if err != nil {
+		return fmt.Errorf("Error generating RSA key: %v", err)
+	}
+
+	ctxt, err := or.Encrypt([]byte("kick it, CC"))
+	if err != nil {
+		return fmt.Errorf("Error encrypting message: %v", err)
+	}
+
+	msg, state, err := analysis.Bleichenbacher98From(random, pub, ctxt, &or, func(state analysis.BleichenbacherState) bool {
+		log.Printf("Round %d: %d interval(s) left after %d queries", state.Round, len(state.M), state.Queries)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("Error decrypting ciphertext: %v", err)
+	}
+
+	log.Printf("Decrypted message after %d queries: %s", state.Queries, msg)
+
+	return nil
+}
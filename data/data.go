@@ -0,0 +1,226 @@
+// Package data provides the data files of the cryptopals challenges.
+//
+// The files are embedded into the binary, so they can be loaded regardless of
+// the working directory. Files in an override directory, if set, take
+// precedence over the embedded ones.
+package data
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Lavode/cryptopals/sliceutil"
+)
+
+//go:embed *.txt
+var embedded embed.FS
+
+// Directory is a directory from which data files are loaded in preference
+// to the embedded ones, such as one holding modified fixtures. Files missing
+// from it are still loaded from the embedded ones. It is ignored if empty.
+var Directory string
+
+// DataEncoding specifies the encoding of data in a file.
+type DataEncoding int
+
+const (
+	// Base64 refers to binary data encoded with standard Base64 encoding
+	// defined in RFC4648.
+	Base64 DataEncoding = iota
+	// Hex refers to binary data encoded as a hexadecimal string.
+	Hex
+	// Plain refers to unencoded binary data.
+	Plain
+	// Detect causes the encoding to be detected based on the contents of
+	// the file, as done by DetectEncoding().
+	Detect
+)
+
+// String returns the name of the encoding.
+func (enc DataEncoding) String() string {
+	switch enc {
+	case Base64:
+		return "base64"
+	case Hex:
+		return "hex"
+	case Plain:
+		return "plain"
+	case Detect:
+		return "detect"
+	default:
+		return fmt.Sprintf("DataEncoding(%d)", int(enc))
+	}
+}
+
+// GetLines loads data stored in a file, splitting it by Unix-style ASCII
+// newlines (that is the 0xA byte). It decodes each line based on the
+// specified encoding, returning the resulting binary data.
+//
+// A trailing newline at the end of the file does not yield an empty line.
+func GetLines(challenge int, encoding DataEncoding) ([][]byte, error) {
+	encData, err := read(challenge)
+	if err != nil {
+		return [][]byte{}, err
+	}
+
+	if encoding == Detect {
+		encoding = detect(encData)
+	}
+
+	encData = bytes.TrimSuffix(encData, []byte{0xA})
+	encLines := sliceutil.Split(encData, 0xA) // Newline
+
+	lines := make([][]byte, len(encLines))
+	for i, encLine := range encLines {
+		line, err := decode(encLine, encoding)
+		if err != nil {
+			return [][]byte{}, fmt.Errorf("Error decoding line %d of challenge %d: %v", i+1, challenge, err)
+		}
+
+		lines[i] = line
+	}
+
+	return lines, nil
+}
+
+// GetData loads data stored in a file. It decodes the data based on the
+// specified encoding, and returns the resulting binary data.
+//
+// Newlines are ignored when decoding Base64 or hex, so encoded data may be
+// split across lines.
+func GetData(challenge int, encoding DataEncoding) ([]byte, error) {
+	encData, err := read(challenge)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if encoding == Detect {
+		encoding = detect(encData)
+	}
+
+	if encoding == Hex {
+		encData = bytes.ReplaceAll(encData, []byte{0xA}, []byte{})
+	}
+
+	return decode(encData, encoding)
+}
+
+// DetectEncoding detects the encoding of the challenge's data file.
+//
+// Data is considered hex-encoded if every line is of even length and
+// consists of hex digits, and Base64-encoded if it consists of characters of
+// the Base64 alphabet and decodes as such. Everything else is considered
+// plain.
+func DetectEncoding(challenge int) (DataEncoding, error) {
+	encData, err := read(challenge)
+	if err != nil {
+		return Plain, err
+	}
+
+	return detect(encData), nil
+}
+
+func detect(encData []byte) DataEncoding {
+	if len(encData) == 0 {
+		return Plain
+	}
+
+	lines := sliceutil.Split(bytes.TrimSuffix(encData, []byte{0xA}), 0xA)
+
+	isHex := true
+	for _, line := range lines {
+		if len(line)%2 != 0 || !onlyContains(line, "0123456789abcdefABCDEF") {
+			isHex = false
+			break
+		}
+	}
+	if isHex {
+		return Hex
+	}
+
+	alphabet := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=\n"
+	if onlyContains(encData, alphabet) {
+		if _, err := decodeBase64(encData); err == nil {
+			return Base64
+		}
+	}
+
+	return Plain
+}
+
+func onlyContains(data []byte, chars string) bool {
+	for _, b := range data {
+		if !bytes.ContainsRune([]byte(chars), rune(b)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func decode(data []byte, encoding DataEncoding) ([]byte, error) {
+	switch encoding {
+	case Base64:
+		return decodeBase64(data)
+	case Hex:
+		return decodeHex(data)
+	case Plain:
+		return data, nil
+	default:
+		return []byte{}, fmt.Errorf("Invalid data encoding: %v", encoding)
+	}
+}
+
+// read reads the challenge's data file from the override directory if it
+// exists there, and from the embedded files otherwise.
+func read(challenge int) ([]byte, error) {
+	file := fmt.Sprintf("%d.txt", challenge)
+
+	if Directory != "" {
+		path := filepath.Join(Directory, file)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return data, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return []byte{}, fmt.Errorf("Error reading from %s: %v", path, err)
+		}
+	}
+
+	data, err := embedded.ReadFile(file)
+	if err != nil {
+		return []byte{}, fmt.Errorf("No data for challenge %d: %v", challenge, err)
+	}
+
+	return data, nil
+}
+
+func decodeBase64(encData []byte) ([]byte, error) {
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(encData)))
+	n, err := base64.StdEncoding.Decode(data, encData)
+	if err != nil {
+		return data, fmt.Errorf("Error decoding base64: %v", err)
+	}
+	data = data[:n]
+
+	return data, nil
+}
+
+func decodeHex(encData []byte) ([]byte, error) {
+	data := make([]byte, hex.DecodedLen(len(encData)))
+	n, err := hex.Decode(data, encData)
+	if err != nil {
+		return data, fmt.Errorf("Error decoding hex: %v", err)
+	}
+	data = data[:n]
+
+	return data, nil
+}
@@ -0,0 +1,73 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	expected := map[int]DataEncoding{
+		4:  Hex,
+		6:  Base64,
+		7:  Base64,
+		8:  Hex,
+		10: Base64,
+		12: Base64,
+	}
+
+	for challenge, enc := range expected {
+		actual, err := DetectEncoding(challenge)
+		assert.Nil(t, err)
+		assert.Equal(t, enc, actual, "Challenge %d", challenge)
+	}
+
+	assert.Equal(t, Hex, detect([]byte("00ff\nABcd\n")))
+	assert.Equal(t, Base64, detect([]byte("SGVsbG8=\n")))
+	assert.Equal(t, Plain, detect([]byte("Hello world\n")))
+	assert.Equal(t, Plain, detect([]byte{}))
+}
+
+func TestGetLines(t *testing.T) {
+	lines, err := GetLines(4, Hex)
+	assert.Nil(t, err)
+	assert.Len(t, lines, 327)
+	assert.Len(t, lines[0], 30)
+
+	detected, err := GetLines(4, Detect)
+	assert.Nil(t, err)
+	assert.Equal(t, lines, detected)
+}
+
+func TestGetData(t *testing.T) {
+	data, err := GetData(6, Base64)
+	assert.Nil(t, err)
+	assert.Len(t, data, 2876)
+
+	detected, err := GetData(6, Detect)
+	assert.Nil(t, err)
+	assert.Equal(t, data, detected)
+
+	_, err = GetData(1, Plain)
+	assert.NotNil(t, err)
+}
+
+func TestDirectory(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "4.txt"), []byte("00ff\n1234\n"), 0644)
+	assert.Nil(t, err)
+
+	Directory = dir
+	defer func() { Directory = "" }()
+
+	lines, err := GetLines(4, Detect)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{{0x00, 0xff}, {0x12, 0x34}}, lines)
+
+	// Files missing from the directory are loaded from the embedded ones
+	data, err := GetData(6, Base64)
+	assert.Nil(t, err)
+	assert.Len(t, data, 2876)
+}
@@ -0,0 +1,184 @@
+package dh
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// Parameters are the public domain parameters of Diffie-Hellman.
+//
+// P is the modulus of the group, Q the prime order of the subgroup in which
+// key exchanges take place, and G a generator of said subgroup.
+type Parameters struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+}
+
+// SubgroupParameters returns the domain parameters used in the cryptopals
+// small subgroup challenges.
+//
+// They consist of a 512-bit modulus P and a 128-bit subgroup order Q. The
+// cofactor (P - 1) / Q has plenty of small prime factors, so the group has
+// many small subgroups besides the one generated by G.
+//
+// These are the parameters exactly as given by challenge 57, rather than a
+// 1024-bit set, as the challenge does not provide one. Any other group would
+// need an equally smooth cofactor to be of use for the attack, and thus not be
+// any more realistic.
+func SubgroupParameters() Parameters {
+	p, _ := new(big.Int).SetString(
+		"719977399739191103060999931777394127432276433342869892173633964392834"+
+			"64537000853588029739004855929104754800897261407081024749574299035"+
+			"31369589969318716771",
+		10,
+	)
+	g, _ := new(big.Int).SetString(
+		"456535639709574065543685450348382683213610614163956348773243819534"+
+			"369043760611782831804241823818489621235232911860810008318753503340"+
+			"2010599512641674644143",
+		10,
+	)
+	q, _ := new(big.Int).SetString("236234353446506858198510045061214171961", 10)
+
+	return Parameters{P: p, Q: q, G: g}
+}
+
+// KangarooParameters returns the domain parameters used in the cryptopals
+// kangaroo challenge.
+//
+// They consist of a 512-bit modulus P and a 128-bit subgroup order Q. Unlike
+// with SubgroupParameters(), the small prime factors of the cofactor
+// (P - 1) / Q only make up about 89 bits, which does not suffice to recover a
+// private key with small subgroup confinement alone.
+func KangarooParameters() Parameters {
+	p, _ := new(big.Int).SetString(
+		"114703748749252756581166635072321614020866502584538962745349916768"+
+			"989992626415815191010747406423698482332942398515192123418443373471"+
+			"19899874391456329785623",
+		10,
+	)
+	g, _ := new(big.Int).SetString(
+		"622952335333961296978159266084741085889881358738459939978290179936"+
+			"063635566740258555167783009058567397963466103140082647486611657350"+
+			"811560630587013183357",
+		10,
+	)
+	q, _ := new(big.Int).SetString("335062023296420808191071248367701059461", 10)
+
+	return Parameters{P: p, Q: q, G: g}
+}
+
+// Validate checks that the domain parameters are well-formed.
+//
+// That is, that P and Q are (probable) primes, that Q divides P - 1, and that
+// G is a generator of the subgroup of order Q.
+func (params Parameters) Validate() error {
+	if params.P == nil || params.Q == nil || params.G == nil {
+		return fmt.Errorf("Incomplete parameters")
+	}
+
+	if !params.P.ProbablyPrime(20) {
+		return fmt.Errorf("Modulus p is not prime")
+	}
+
+	if !params.Q.ProbablyPrime(20) {
+		return fmt.Errorf("Subgroup order q is not prime")
+	}
+
+	pMinusOne := new(big.Int).Sub(params.P, big.NewInt(1))
+	if new(big.Int).Mod(pMinusOne, params.Q).Sign() != 0 {
+		return fmt.Errorf("Subgroup order q does not divide p - 1")
+	}
+
+	return params.ValidateElement(params.G)
+}
+
+// ValidateElement checks that y is an element of the subgroup of order Q,
+// other than the identity. This must be done for any public key received from
+// a peer.
+func (params Parameters) ValidateElement(y *big.Int) error {
+	if y == nil || y.Cmp(big.NewInt(1)) <= 0 || y.Cmp(params.P) >= 0 {
+		return fmt.Errorf("Element not in range (1, p)")
+	}
+
+	if new(big.Int).Exp(y, params.Q, params.P).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("Element not in subgroup of order q")
+	}
+
+	return nil
+}
+
+// Cofactor returns (P - 1) / Q.
+func (params Parameters) Cofactor() *big.Int {
+	j := new(big.Int).Sub(params.P, big.NewInt(1))
+	return j.Div(j, params.Q)
+}
+
+// PublicKey is a Diffie-Hellman public key, consisting of the domain
+// parameters and Y = G^X mod P.
+type PublicKey struct {
+	Parameters
+	Y *big.Int
+}
+
+// PrivateKey is a Diffie-Hellman private key, consisting of the public key
+// and the secret exponent X.
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+// GenerateKey generates a new key pair for the given domain parameters.
+//
+// The private exponent is chosen uniformly at random from [1, Q).
+func GenerateKey(params Parameters) (PrivateKey, error) {
+	return GenerateKeyFrom(nil, params)
+}
+
+// GenerateKeyFrom generates a new key pair as GenerateKey() does, reading
+// from the given source of randomness. If r is nil, a CSPRNG is used.
+func GenerateKeyFrom(r io.Reader, params Parameters) (PrivateKey, error) {
+	max := new(big.Int).Sub(params.Q, big.NewInt(1))
+	x, err := rand.Int(randutil.Reader(r), max)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("Error generating private key: %v", err)
+	}
+	x.Add(x, big.NewInt(1))
+
+	return NewPrivateKey(params, x), nil
+}
+
+// NewPrivateKey derives the key pair belonging to the private exponent x.
+func NewPrivateKey(params Parameters, x *big.Int) PrivateKey {
+	y := new(big.Int).Exp(params.G, x, params.P)
+
+	return PrivateKey{
+		PublicKey: PublicKey{Parameters: params, Y: y},
+		X:         new(big.Int).Set(x),
+	}
+}
+
+// SharedSecret calculates the shared secret peer^X mod P with the peer's
+// public key.
+//
+// The peer's public key is not validated, which must be done separately with
+// ValidateElement().
+func (priv *PrivateKey) SharedSecret(peer *big.Int) *big.Int {
+	return new(big.Int).Exp(peer, priv.X, priv.P)
+}
+
+// MAC calculates the HMAC-SHA256 of the message, keyed with the big-endian
+// encoding of the shared secret.
+func MAC(secret *big.Int, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret.Bytes())
+	mac.Write(msg)
+
+	return mac.Sum(nil)
+}
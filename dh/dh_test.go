@@ -0,0 +1,77 @@
+package dh
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubgroupParameters(t *testing.T) {
+	params := SubgroupParameters()
+	assert.Nil(t, params.Validate())
+
+	assert.Equal(t, 512, params.P.BitLen())
+	assert.Equal(t, 128, params.Q.BitLen())
+
+	j, _ := new(big.Int).SetString("30477252323177606811760882179058908038824640750610513771646768011063128035873508507547741559514324673960576895059570", 10)
+	assert.Equal(t, j, params.Cofactor())
+}
+
+func TestKangarooParameters(t *testing.T) {
+	params := KangarooParameters()
+	assert.Nil(t, params.Validate())
+
+	assert.Equal(t, 512, params.P.BitLen())
+	assert.Equal(t, 128, params.Q.BitLen())
+
+	j, _ := new(big.Int).SetString("34233586850807404623475048381328686211071196701374230492615844865929237417097514638999377942356150481334217896204702", 10)
+	assert.Equal(t, j, params.Cofactor())
+}
+
+func TestValidate(t *testing.T) {
+	params := SubgroupParameters()
+
+	broken := params
+	broken.G = big.NewInt(1)
+	assert.Error(t, broken.Validate())
+
+	broken = params
+	broken.Q = big.NewInt(7)
+	assert.Error(t, broken.Validate())
+
+	broken = params
+	broken.P = new(big.Int).Add(params.P, big.NewInt(2))
+	assert.Error(t, broken.Validate())
+}
+
+func TestValidateElement(t *testing.T) {
+	params := SubgroupParameters()
+
+	priv, err := GenerateKey(params)
+	assert.Nil(t, err)
+	assert.Nil(t, params.ValidateElement(priv.Y))
+
+	assert.Error(t, params.ValidateElement(big.NewInt(0)))
+	assert.Error(t, params.ValidateElement(big.NewInt(1)))
+	assert.Error(t, params.ValidateElement(params.P))
+
+	// p - 1 is of order 2
+	assert.Error(t, params.ValidateElement(new(big.Int).Sub(params.P, big.NewInt(1))))
+}
+
+func TestSharedSecret(t *testing.T) {
+	params := SubgroupParameters()
+
+	alice, err := GenerateKey(params)
+	assert.Nil(t, err)
+	bob, err := GenerateKey(params)
+	assert.Nil(t, err)
+
+	assert.Equal(t, alice.SharedSecret(bob.Y), bob.SharedSecret(alice.Y))
+	assert.NotEqual(t, alice.SharedSecret(bob.Y), bob.SharedSecret(bob.Y))
+
+	msg := []byte("crazy flamboyant for the rap enjoyment")
+	assert.Equal(t, MAC(alice.SharedSecret(bob.Y), msg), MAC(bob.SharedSecret(alice.Y), msg))
+	assert.Equal(t, 32, len(MAC(big.NewInt(42), msg)))
+}
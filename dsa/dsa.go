@@ -0,0 +1,295 @@
+package dsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/randutil"
+
+	// Registers the hash functions supported for signatures
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+)
+
+// Parameters are the public domain parameters of DSA.
+//
+// P is the modulus of the group, Q the prime order of the subgroup in which
+// all computations take place, and G a generator of said subgroup.
+type Parameters struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+}
+
+// DefaultParameters returns the domain parameters used throughout the
+// cryptopals DSA challenges.
+//
+// They consist of a 1024-bit modulus P and a 160-bit subgroup order Q.
+func DefaultParameters() Parameters {
+	p, _ := new(big.Int).SetString(
+		"800000000000000089e1855218a0e7dac38136ffafa72eda7"+
+			"859f2171e25e65eac698c1702578b07dc2a1076da241c76c6"+
+			"2d374d8389ea5aeffd3226a0530cc565f3bf6b50929139ebe"+
+			"ac04f48c3c84afb796d61e5a4f9a8fda812ab59494232c7d2"+
+			"b4deb50aa18ee9e132bfa85ac4374d7f9091abc3d015efc87"+
+			"1a584471bb1",
+		16,
+	)
+	q, _ := new(big.Int).SetString("f4f47f05794b256174bba6e9b396a7707e563c5b", 16)
+	g, _ := new(big.Int).SetString(
+		"5958c9d3898b224b12672c0b98e06c60df923cb8bc999d119"+
+			"458fef538b8fa4046c8db53039db620c094c9fa077ef389b5"+
+			"322a559946a71903f990f1f7e0e025e2d7f7cf494aff1a047"+
+			"0f5b64c36b625a097f1651fe775323556fe00b3608c887892"+
+			"878480e99041be601a62166ca6894bdd41a7054ec89f756ba"+
+			"9fc95302291",
+		16,
+	)
+
+	return Parameters{P: p, Q: q, G: g}
+}
+
+// Validate checks that the domain parameters are well-formed.
+//
+// That is, that P and Q are (probable) primes, that Q divides P - 1, and that
+// G is a generator of the subgroup of order Q. Notably this rejects
+// degenerate generators such as 0, 1 or P + 1.
+func (params Parameters) Validate() error {
+	if params.P == nil || params.Q == nil || params.G == nil {
+		return fmt.Errorf("Incomplete parameters")
+	}
+
+	if !params.P.ProbablyPrime(20) {
+		return fmt.Errorf("Modulus p is not prime")
+	}
+
+	if !params.Q.ProbablyPrime(20) {
+		return fmt.Errorf("Subgroup order q is not prime")
+	}
+
+	pMinusOne := new(big.Int).Sub(params.P, big.NewInt(1))
+	if new(big.Int).Mod(pMinusOne, params.Q).Sign() != 0 {
+		return fmt.Errorf("Subgroup order q does not divide p - 1")
+	}
+
+	if params.G.Cmp(big.NewInt(1)) <= 0 || params.G.Cmp(params.P) >= 0 {
+		return fmt.Errorf("Generator g not in range (1, p)")
+	}
+
+	if new(big.Int).Exp(params.G, params.Q, params.P).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("Generator g does not generate subgroup of order q")
+	}
+
+	return nil
+}
+
+// PublicKey is a DSA public key, consisting of the domain parameters and
+// Y = G^X mod P.
+type PublicKey struct {
+	Parameters
+	Y *big.Int
+}
+
+// PrivateKey is a DSA private key, consisting of the public key and the
+// secret exponent X.
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+// Signature is a DSA signature.
+type Signature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// GenerateKey generates a new key pair for the given domain parameters.
+//
+// The private exponent is chosen uniformly at random from [1, Q).
+func GenerateKey(params Parameters) (PrivateKey, error) {
+	return GenerateKeyFrom(nil, params)
+}
+
+// GenerateKeyFrom generates a new key pair as GenerateKey() does, reading
+// from the given source of randomness. If r is nil, a CSPRNG is used.
+func GenerateKeyFrom(r io.Reader, params Parameters) (PrivateKey, error) {
+	x, err := randomExponent(r, params.Q)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("Error generating private key: %v", err)
+	}
+
+	return NewPrivateKey(params, x), nil
+}
+
+// NewPrivateKey derives the key pair belonging to the private exponent x.
+func NewPrivateKey(params Parameters, x *big.Int) PrivateKey {
+	y := new(big.Int).Exp(params.G, x, params.P)
+
+	return PrivateKey{
+		PublicKey: PublicKey{Parameters: params, Y: y},
+		X:         new(big.Int).Set(x),
+	}
+}
+
+// Sign signs the message with a nonce chosen uniformly at random.
+//
+// The message is hashed with the given hash function, which must be
+// available in the binary, such as crypto.SHA1 or crypto.SHA256.
+func (priv *PrivateKey) Sign(hash crypto.Hash, msg []byte) (Signature, error) {
+	return priv.SignFrom(nil, hash, msg)
+}
+
+// SignFrom signs the message as Sign() does, reading the nonce from the given
+// source of randomness. If r is nil, a CSPRNG is used.
+func (priv *PrivateKey) SignFrom(r io.Reader, hash crypto.Hash, msg []byte) (Signature, error) {
+	for {
+		k, err := randomExponent(r, priv.Q)
+		if err != nil {
+			return Signature{}, fmt.Errorf("Error generating nonce: %v", err)
+		}
+
+		sig, err := priv.SignWithNonce(hash, msg, k)
+		if err == errDegenerateSignature {
+			// Vanishingly unlikely with a proper nonce, but
+			// retrying is all there is to it.
+			continue
+		}
+
+		return sig, err
+	}
+}
+
+// errDegenerateSignature is returned if either component of a signature
+// turned out to be zero.
+var errDegenerateSignature = fmt.Errorf("Degenerate signature, r or s is zero")
+
+// SignWithNonce signs the message using the caller-supplied nonce k.
+//
+// The nonce must be kept secret and must never be reused for two different
+// messages, as either allows recovery of the private key. This method exists
+// to demonstrate exactly that, so use Sign() instead.
+//
+// An error is returned if the resulting signature is degenerate, that is if
+// either r or s is zero.
+func (priv *PrivateKey) SignWithNonce(hash crypto.Hash, msg []byte, k *big.Int) (Signature, error) {
+	h, err := Digest(hash, msg, priv.Q)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	kInv := new(big.Int).ModInverse(k, priv.Q)
+	if kInv == nil {
+		return Signature{}, fmt.Errorf("Nonce %v is not invertible modulo q", k)
+	}
+
+	// r = (g^k mod p) mod q
+	r := new(big.Int).Exp(priv.G, k, priv.P)
+	r.Mod(r, priv.Q)
+
+	// s = k^-1 (H(m) + x * r) mod q
+	s := new(big.Int).Mul(priv.X, r)
+	s.Add(s, h)
+	s.Mul(s, kInv)
+	s.Mod(s, priv.Q)
+
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return Signature{}, errDegenerateSignature
+	}
+
+	return Signature{R: r, S: s}, nil
+}
+
+// Verify checks whether the signature is valid for the given message.
+//
+// Signatures with either component outside of (0, q) are rejected.
+func (pub *PublicKey) Verify(hash crypto.Hash, msg []byte, sig Signature) bool {
+	if sig.R == nil || sig.S == nil {
+		return false
+	}
+
+	if sig.R.Sign() <= 0 || sig.R.Cmp(pub.Q) >= 0 {
+		return false
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(pub.Q) >= 0 {
+		return false
+	}
+
+	h, err := Digest(hash, msg, pub.Q)
+	if err != nil {
+		return false
+	}
+
+	return sig.R.Cmp(pub.V(h, sig)) == 0
+}
+
+// V calculates the value v which a verifier compares against the r component
+// of the signature, that is:
+//
+//	w = s^-1 mod q
+//	u1 = H(m) * w mod q
+//	u2 = r * w mod q
+//	v = (g^u1 * y^u2 mod p) mod q
+//
+// No validation of the signature's components takes place. If s is not
+// invertible, v is set to -1 which will never match r.
+func (pub *PublicKey) V(h *big.Int, sig Signature) *big.Int {
+	w := new(big.Int).ModInverse(sig.S, pub.Q)
+	if w == nil {
+		return big.NewInt(-1)
+	}
+
+	u1 := new(big.Int).Mul(h, w)
+	u1.Mod(u1, pub.Q)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, pub.Q)
+
+	v := new(big.Int).Exp(pub.G, u1, pub.P)
+	v.Mul(v, new(big.Int).Exp(pub.Y, u2, pub.P))
+	v.Mod(v, pub.P)
+	v.Mod(v, pub.Q)
+
+	return v
+}
+
+// Digest hashes the message and converts the hash to an integer as specified
+// in FIPS 186-4.
+//
+// If the hash is longer than the subgroup order q, only its leftmost bits are
+// used.
+func Digest(hash crypto.Hash, msg []byte, q *big.Int) (*big.Int, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("Hash function %v not available", hash)
+	}
+
+	hasher := hash.New()
+	hasher.Write(msg)
+	sum := hasher.Sum(nil)
+
+	qBytes := (q.BitLen() + 7) / 8
+	if len(sum) > qBytes {
+		sum = sum[:qBytes]
+	}
+
+	h := new(big.Int).SetBytes(sum)
+	if excess := len(sum)*8 - q.BitLen(); excess > 0 {
+		h.Rsh(h, uint(excess))
+	}
+
+	return h, nil
+}
+
+// randomExponent returns an integer chosen uniformly at random from [1, q),
+// reading from r.
+func randomExponent(r io.Reader, q *big.Int) (*big.Int, error) {
+	max := new(big.Int).Sub(q, big.NewInt(1))
+
+	x, err := rand.Int(randutil.Reader(r), max)
+	if err != nil {
+		return nil, err
+	}
+
+	return x.Add(x, big.NewInt(1)), nil
+}
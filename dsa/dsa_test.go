@@ -0,0 +1,118 @@
+package dsa
+
+import (
+	"crypto"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultParameters(t *testing.T) {
+	params := DefaultParameters()
+
+	assert.True(t, params.P.ProbablyPrime(20))
+	assert.True(t, params.Q.ProbablyPrime(20))
+
+	// q | p - 1
+	pMinusOne := new(big.Int).Sub(params.P, big.NewInt(1))
+	assert.Equal(t, 0, new(big.Int).Mod(pMinusOne, params.Q).Sign())
+
+	// g generates a subgroup of order q
+	assert.Equal(t, 0, new(big.Int).Exp(params.G, params.Q, params.P).Cmp(big.NewInt(1)))
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, DefaultParameters().Validate())
+
+	params := DefaultParameters()
+	params.G = big.NewInt(0)
+	assert.Error(t, params.Validate())
+
+	params.G = new(big.Int).Add(params.P, big.NewInt(1))
+	assert.Error(t, params.Validate())
+
+	params = DefaultParameters()
+	params.Q = new(big.Int).Add(params.Q, big.NewInt(2))
+	assert.Error(t, params.Validate())
+}
+
+func TestSignAndVerify(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters())
+	assert.Nil(t, err)
+
+	msg := []byte("Hello world")
+
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		sig, err := priv.Sign(hash, msg)
+		assert.Nil(t, err)
+		assert.True(t, priv.Verify(hash, msg, sig))
+
+		assert.False(t, priv.Verify(hash, []byte("Hello world!"), sig))
+
+		tampered := Signature{R: sig.R, S: new(big.Int).Add(sig.S, big.NewInt(1))}
+		assert.False(t, priv.Verify(hash, msg, tampered))
+	}
+}
+
+func TestSignFrom(t *testing.T) {
+	priv1, err := GenerateKeyFrom(randutil.NewSeeded(42), DefaultParameters())
+	assert.Nil(t, err)
+	priv2, err := GenerateKeyFrom(randutil.NewSeeded(42), DefaultParameters())
+	assert.Nil(t, err)
+	assert.Equal(t, priv1.X, priv2.X)
+
+	msg := []byte("Hello world")
+	sig1, err := priv1.SignFrom(randutil.NewSeeded(1337), crypto.SHA256, msg)
+	assert.Nil(t, err)
+	sig2, err := priv2.SignFrom(randutil.NewSeeded(1337), crypto.SHA256, msg)
+	assert.Nil(t, err)
+	assert.Equal(t, sig1, sig2)
+	assert.True(t, priv1.Verify(crypto.SHA256, msg, sig1))
+}
+
+func TestSignWithNonce(t *testing.T) {
+	priv := NewPrivateKey(DefaultParameters(), big.NewInt(1337))
+	msg := []byte("Hello world")
+
+	a, err := priv.SignWithNonce(crypto.SHA256, msg, big.NewInt(42))
+	assert.Nil(t, err)
+	b, err := priv.SignWithNonce(crypto.SHA256, msg, big.NewInt(42))
+	assert.Nil(t, err)
+
+	// Signing is deterministic in the nonce
+	assert.Equal(t, a, b)
+	assert.True(t, priv.Verify(crypto.SHA256, msg, a))
+}
+
+func TestVerifyChallengeSignature(t *testing.T) {
+	y, _ := new(big.Int).SetString(
+		"84ad4719d044495496a3201c8ff484feb45b962e7302e56a392aee4"+
+			"abab3e4bdebf2955b4736012f21a08084056b19bcd7fee56048e004"+
+			"e44984e2f411788efdc837a0d2e5abb7b555039fd243ac01f0fb2ed"+
+			"1dec568280ce678e931868d23eb095fde9d3779191b8c0299d6e07b"+
+			"bb283e6633451e535c45513b2d33c99ea17",
+		16,
+	)
+	r, _ := new(big.Int).SetString("548099063082341131477253921760299949438196259240", 10)
+	s, _ := new(big.Int).SetString("857042759984254168557880549501802188789837994940", 10)
+
+	pub := PublicKey{Parameters: DefaultParameters(), Y: y}
+	msg := []byte("For those that envy a MC it can be hazardous to your health\nSo be friendly, a matter of life and death, just like a etch-a-sketch\n")
+
+	h, err := Digest(crypto.SHA1, msg, pub.Q)
+	assert.Nil(t, err)
+	assert.Equal(t, "d2d0714f014a9784047eaeccf956520045c45265", h.Text(16))
+
+	assert.True(t, pub.Verify(crypto.SHA1, msg, Signature{R: r, S: s}))
+}
+
+func TestDigestTruncatesToSubgroupOrder(t *testing.T) {
+	// 160-bit q, so SHA-256 must be truncated to its leftmost 160 bits.
+	q := DefaultParameters().Q
+
+	h, err := Digest(crypto.SHA256, []byte("abc"), q)
+	assert.Nil(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a3", h.Text(16))
+}
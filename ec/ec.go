@@ -0,0 +1,440 @@
+package ec
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// Point is a point on an elliptic curve in affine coordinates.
+//
+// The point at infinity, the identity of the curve's group, has no affine
+// coordinates. It is represented by nil coordinates, as returned by
+// Infinity().
+type Point struct {
+	X *big.Int
+	Y *big.Int
+}
+
+// Infinity returns the point at infinity.
+func Infinity() Point {
+	return Point{}
+}
+
+// IsInfinity returns whether the point is the point at infinity.
+func (p Point) IsInfinity() bool {
+	return p.X == nil || p.Y == nil
+}
+
+// Equal returns whether the two points are equal.
+func (p Point) Equal(q Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() == q.IsInfinity()
+	}
+
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+// ProjectivePoint is a point on an elliptic curve in homogeneous projective
+// coordinates (X : Y : Z), which correspond to the affine point (X/Z, Y/Z).
+// Points with Z = 0 are the point at infinity.
+//
+// Arithmetic in projective coordinates requires no modular inversions, which
+// makes it considerably faster for scalar multiplication.
+type ProjectivePoint struct {
+	X *big.Int
+	Y *big.Int
+	Z *big.Int
+}
+
+// Curve is an elliptic curve in short Weierstrass form:
+//
+//	y^2 = x^3 + A*x + B
+//
+// over the prime field GF(P), with a base point G of prime order N.
+//
+// For curves which are only used for their group structure, such as curves
+// an attacker picks, G and N may be unset.
+type Curve struct {
+	P *big.Int
+	A *big.Int
+	B *big.Int
+	G Point
+	N *big.Int
+	// Order is the number of points on the curve, if known.
+	Order *big.Int
+}
+
+// ToyCurve returns the curve used throughout the cryptopals elliptic curve
+// challenges:
+//
+//	y^2 = x^3 - 95051*x + 11279326
+//
+// over GF(233970423115425145524320034830162017933). Its order is 8 times the
+// order of the base point (182, 85518893674295321206118380980485522083).
+func ToyCurve() Curve {
+	p, _ := new(big.Int).SetString("233970423115425145524320034830162017933", 10)
+	gy, _ := new(big.Int).SetString("85518893674295321206118380980485522083", 10)
+	n, _ := new(big.Int).SetString("29246302889428143187362802287225875743", 10)
+	order, _ := new(big.Int).SetString("233970423115425145498902418297807005944", 10)
+
+	return Curve{
+		P:     p,
+		A:     new(big.Int).Mod(big.NewInt(-95051), p),
+		B:     big.NewInt(11279326),
+		G:     Point{X: big.NewInt(182), Y: gy},
+		N:     n,
+		Order: order,
+	}
+}
+
+// P256 returns the NIST P-256 curve, as specified in FIPS 186-4:
+//
+//	y^2 = x^3 - 3*x + b
+//
+// over GF(2^256 - 2^224 + 2^192 + 2^96 - 1). Its order is prime, and thus
+// equal to the order of the base point.
+func P256() Curve {
+	p, _ := new(big.Int).SetString("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff", 16)
+	n, _ := new(big.Int).SetString("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 16)
+	b, _ := new(big.Int).SetString("5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b", 16)
+	gx, _ := new(big.Int).SetString("6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296", 16)
+	gy, _ := new(big.Int).SetString("4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5", 16)
+
+	return Curve{
+		P:     p,
+		A:     new(big.Int).Sub(p, big.NewInt(3)),
+		B:     b,
+		G:     Point{X: gx, Y: gy},
+		N:     n,
+		Order: new(big.Int).Set(n),
+	}
+}
+
+// ToyInvalidCurves returns curves which only differ from ToyCurve() in B, and
+// whose orders have many small prime factors.
+//
+// As B is not used by the formulas for point addition, an implementation of
+// ToyCurve() which does not check that points are on it will happily work on
+// these curves instead.
+func ToyInvalidCurves() []Curve {
+	toy := ToyCurve()
+
+	bs := []int64{210, 504, 727}
+	orders := []string{
+		"233970423115425145550826547352470124412",
+		"233970423115425145544350131142039591210",
+		"233970423115425145545378039958152057148",
+	}
+
+	curves := make([]Curve, len(bs))
+	for i := range curves {
+		order, _ := new(big.Int).SetString(orders[i], 10)
+		curves[i] = Curve{P: toy.P, A: toy.A, B: big.NewInt(bs[i]), Order: order}
+	}
+
+	return curves
+}
+
+// IsOnCurve returns whether the point satisfies the curve equation. The point
+// at infinity is on every curve.
+func (c Curve) IsOnCurve(p Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+
+	if p.X.Sign() < 0 || p.X.Cmp(c.P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(c.P) >= 0 {
+		return false
+	}
+
+	lhs := new(big.Int).Mul(p.Y, p.Y)
+	lhs.Mod(lhs, c.P)
+
+	return lhs.Cmp(c.rhs(p.X)) == 0
+}
+
+// Validate checks that the point is a valid public key, that is a point on
+// the curve other than the point at infinity, and in the subgroup generated
+// by G.
+func (c Curve) Validate(p Point) error {
+	if p.IsInfinity() {
+		return fmt.Errorf("Point at infinity")
+	}
+
+	if !c.IsOnCurve(p) {
+		return fmt.Errorf("Point not on curve")
+	}
+
+	if c.N != nil && !c.ScalarMult(p, c.N).IsInfinity() {
+		return fmt.Errorf("Point not in subgroup of order n")
+	}
+
+	return nil
+}
+
+// RandomPoint returns a random point on the curve, other than the point at
+// infinity.
+func (c Curve) RandomPoint() (Point, error) {
+	return c.RandomPointFrom(nil)
+}
+
+// RandomPointFrom returns a random point as RandomPoint() does, reading from
+// the given source of randomness. If r is nil, a CSPRNG is used.
+func (c Curve) RandomPointFrom(r io.Reader) (Point, error) {
+	for {
+		x, err := rand.Int(randutil.Reader(r), c.P)
+		if err != nil {
+			return Point{}, fmt.Errorf("Error generating random point: %v", err)
+		}
+
+		y := new(big.Int).ModSqrt(c.rhs(x), c.P)
+		if y != nil {
+			return Point{X: x, Y: y}, nil
+		}
+	}
+}
+
+// Neg returns the inverse of the point.
+func (c Curve) Neg(p Point) Point {
+	if p.IsInfinity() {
+		return p
+	}
+
+	y := new(big.Int).Neg(p.Y)
+	return Point{X: new(big.Int).Set(p.X), Y: y.Mod(y, c.P)}
+}
+
+// Add adds two points in affine coordinates.
+func (c Curve) Add(p, q Point) Point {
+	if p.IsInfinity() {
+		return q
+	}
+	if q.IsInfinity() {
+		return p
+	}
+
+	if p.X.Cmp(q.X) == 0 {
+		sum := new(big.Int).Add(p.Y, q.Y)
+		if sum.Mod(sum, c.P).Sign() == 0 {
+			return Infinity()
+		}
+
+		return c.Double(p)
+	}
+
+	// lambda = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(q.Y, p.Y)
+	den := new(big.Int).Sub(q.X, p.X)
+	den.Mod(den, c.P)
+	lambda := num.Mul(num, den.ModInverse(den, c.P))
+	lambda.Mod(lambda, c.P)
+
+	return c.addWithSlope(p, q, lambda)
+}
+
+// Double doubles a point in affine coordinates.
+func (c Curve) Double(p Point) Point {
+	if p.IsInfinity() || p.Y.Sign() == 0 {
+		return Infinity()
+	}
+
+	// lambda = (3 * x^2 + a) / (2 * y)
+	num := new(big.Int).Mul(p.X, p.X)
+	num.Mul(num, big.NewInt(3))
+	num.Add(num, c.A)
+	den := new(big.Int).Lsh(p.Y, 1)
+	den.Mod(den, c.P)
+	lambda := num.Mul(num, den.ModInverse(den, c.P))
+	lambda.Mod(lambda, c.P)
+
+	return c.addWithSlope(p, p, lambda)
+}
+
+// addWithSlope calculates p + q given the slope of the line through them.
+func (c Curve) addWithSlope(p, q Point, lambda *big.Int) Point {
+	// x3 = lambda^2 - x1 - x2
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, p.X)
+	x.Sub(x, q.X)
+	x.Mod(x, c.P)
+
+	// y3 = lambda * (x1 - x3) - y1
+	y := new(big.Int).Sub(p.X, x)
+	y.Mul(y, lambda)
+	y.Sub(y, p.Y)
+	y.Mod(y, c.P)
+
+	return Point{X: x, Y: y}
+}
+
+// ToProjective converts a point to projective coordinates.
+func (c Curve) ToProjective(p Point) ProjectivePoint {
+	if p.IsInfinity() {
+		return ProjectivePoint{X: big.NewInt(0), Y: big.NewInt(1), Z: big.NewInt(0)}
+	}
+
+	return ProjectivePoint{X: new(big.Int).Set(p.X), Y: new(big.Int).Set(p.Y), Z: big.NewInt(1)}
+}
+
+// ToAffine converts a point to affine coordinates.
+func (c Curve) ToAffine(p ProjectivePoint) Point {
+	if p.Z.Sign() == 0 {
+		return Infinity()
+	}
+
+	zInv := new(big.Int).ModInverse(p.Z, c.P)
+	x := new(big.Int).Mul(p.X, zInv)
+	y := new(big.Int).Mul(p.Y, zInv)
+
+	return Point{X: x.Mod(x, c.P), Y: y.Mod(y, c.P)}
+}
+
+// AddProjective adds two points in projective coordinates.
+func (c Curve) AddProjective(p, q ProjectivePoint) ProjectivePoint {
+	if p.Z.Sign() == 0 {
+		return q
+	}
+	if q.Z.Sign() == 0 {
+		return p
+	}
+
+	y1z2 := c.mul(p.Y, q.Z)
+	x1z2 := c.mul(p.X, q.Z)
+	z1z2 := c.mul(p.Z, q.Z)
+
+	// u = y2 * z1 - y1 * z2, v = x2 * z1 - x1 * z2
+	u := c.sub(c.mul(q.Y, p.Z), y1z2)
+	v := c.sub(c.mul(q.X, p.Z), x1z2)
+
+	if v.Sign() == 0 {
+		if u.Sign() == 0 {
+			return c.DoubleProjective(p)
+		}
+
+		return c.ToProjective(Infinity())
+	}
+
+	uu := c.mul(u, u)
+	vv := c.mul(v, v)
+	vvv := c.mul(v, vv)
+	r := c.mul(vv, x1z2)
+
+	// a = u^2 * z1 * z2 - v^3 - 2 * r
+	a := c.sub(c.sub(c.mul(uu, z1z2), vvv), c.add(r, r))
+
+	return ProjectivePoint{
+		X: c.mul(v, a),
+		Y: c.sub(c.mul(u, c.sub(r, a)), c.mul(vvv, y1z2)),
+		Z: c.mul(vvv, z1z2),
+	}
+}
+
+// DoubleProjective doubles a point in projective coordinates.
+func (c Curve) DoubleProjective(p ProjectivePoint) ProjectivePoint {
+	if p.Z.Sign() == 0 || p.Y.Sign() == 0 {
+		return c.ToProjective(Infinity())
+	}
+
+	xx := c.mul(p.X, p.X)
+	zz := c.mul(p.Z, p.Z)
+
+	// w = a * z^2 + 3 * x^2, s = 2 * y * z
+	w := c.add(c.mul(c.A, zz), c.mul(big.NewInt(3), xx))
+	s := c.mul(big.NewInt(2), c.mul(p.Y, p.Z))
+	ss := c.mul(s, s)
+	sss := c.mul(s, ss)
+	r := c.mul(p.Y, s)
+	rr := c.mul(r, r)
+
+	// b = (x + r)^2 - x^2 - r^2, h = w^2 - 2 * b
+	xr := c.add(p.X, r)
+	b := c.sub(c.sub(c.mul(xr, xr), xx), rr)
+	h := c.sub(c.mul(w, w), c.add(b, b))
+
+	return ProjectivePoint{
+		X: c.mul(h, s),
+		Y: c.sub(c.mul(w, c.sub(b, h)), c.add(rr, rr)),
+		Z: sss,
+	}
+}
+
+// ScalarMult calculates k * p, using double-and-add in projective
+// coordinates. Negative scalars multiply the inverse of the point.
+func (c Curve) ScalarMult(p Point, k *big.Int) Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(c.Neg(p), new(big.Int).Neg(k))
+	}
+
+	base := c.ToProjective(p)
+	acc := c.ToProjective(Infinity())
+
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = c.DoubleProjective(acc)
+		if k.Bit(i) == 1 {
+			acc = c.AddProjective(acc, base)
+		}
+	}
+
+	return c.ToAffine(acc)
+}
+
+// ScalarBaseMult calculates k * G.
+func (c Curve) ScalarBaseMult(k *big.Int) Point {
+	return c.ScalarMult(c.G, k)
+}
+
+// Marshal encodes the point in the uncompressed format of SEC 1, that is as
+// 0x04 || x || y with both coordinates as big-endian integers of the length
+// of P. The point at infinity is encoded as a single 0x00 byte.
+func (c Curve) Marshal(p Point) []byte {
+	if p.IsInfinity() {
+		return []byte{0x00}
+	}
+
+	size := (c.P.BitLen() + 7) / 8
+	out := make([]byte, 1+2*size)
+	out[0] = 0x04
+	p.X.FillBytes(out[1 : 1+size])
+	p.Y.FillBytes(out[1+size:])
+
+	return out
+}
+
+// rhs calculates x^3 + A*x + B mod P.
+func (c Curve) rhs(x *big.Int) *big.Int {
+	out := new(big.Int).Mul(x, x)
+	out.Add(out, c.A)
+	out.Mul(out, x)
+	out.Add(out, c.B)
+
+	return out.Mod(out, c.P)
+}
+
+func (c Curve) mul(x, y *big.Int) *big.Int {
+	out := new(big.Int).Mul(x, y)
+	return out.Mod(out, c.P)
+}
+
+func (c Curve) add(x, y *big.Int) *big.Int {
+	out := new(big.Int).Add(x, y)
+	return out.Mod(out, c.P)
+}
+
+func (c Curve) sub(x, y *big.Int) *big.Int {
+	out := new(big.Int).Sub(x, y)
+	return out.Mod(out, c.P)
+}
+
+// MAC calculates the HMAC-SHA256 of the message, keyed with the encoding of
+// a shared secret, such as the one returned by Curve.Marshal().
+func MAC(secret []byte, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(msg)
+
+	return mac.Sum(nil)
+}
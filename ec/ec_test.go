@@ -0,0 +1,267 @@
+package ec
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToyCurve(t *testing.T) {
+	c := ToyCurve()
+
+	assert.True(t, c.IsOnCurve(c.G))
+	assert.Nil(t, c.Validate(c.G))
+	assert.True(t, c.ScalarBaseMult(c.N).IsInfinity())
+	assert.Equal(t, c.Order, new(big.Int).Mul(c.N, big.NewInt(8)))
+}
+
+func TestToyInvalidCurves(t *testing.T) {
+	toy := ToyCurve()
+
+	for _, c := range ToyInvalidCurves() {
+		assert.Equal(t, toy.A, c.A)
+
+		p, err := c.RandomPoint()
+		assert.Nil(t, err)
+		assert.True(t, c.IsOnCurve(p))
+		assert.False(t, toy.IsOnCurve(p))
+		assert.Error(t, toy.Validate(p))
+
+		assert.True(t, c.ScalarMult(p, c.Order).IsInfinity())
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := ToyCurve()
+	g := c.G
+
+	assert.True(t, c.Add(g, Infinity()).Equal(g))
+	assert.True(t, c.Add(Infinity(), g).Equal(g))
+	assert.True(t, c.Add(g, c.Neg(g)).IsInfinity())
+	assert.True(t, c.Add(g, g).Equal(c.Double(g)))
+
+	g2 := c.Double(g)
+	g3 := c.Add(g2, g)
+	assert.True(t, c.IsOnCurve(g2))
+	assert.True(t, c.IsOnCurve(g3))
+	assert.True(t, c.Add(g, g2).Equal(g3))
+	assert.True(t, c.ScalarBaseMult(big.NewInt(3)).Equal(g3))
+}
+
+func TestProjective(t *testing.T) {
+	c := ToyCurve()
+
+	p, err := c.RandomPoint()
+	assert.Nil(t, err)
+	q, err := c.RandomPoint()
+	assert.Nil(t, err)
+
+	pp := c.ToProjective(p)
+	qp := c.ToProjective(q)
+	assert.True(t, c.ToAffine(pp).Equal(p))
+
+	assert.True(t, c.ToAffine(c.AddProjective(pp, qp)).Equal(c.Add(p, q)))
+	assert.True(t, c.ToAffine(c.DoubleProjective(pp)).Equal(c.Double(p)))
+	assert.True(t, c.ToAffine(c.AddProjective(pp, pp)).Equal(c.Double(p)))
+	assert.True(t, c.ToAffine(c.AddProjective(pp, c.ToProjective(c.Neg(p)))).IsInfinity())
+
+	infinity := c.ToProjective(Infinity())
+	assert.True(t, c.ToAffine(c.AddProjective(pp, infinity)).Equal(p))
+	assert.True(t, c.ToAffine(c.DoubleProjective(infinity)).IsInfinity())
+
+	// Scaled representations of the same point
+	scaled := ProjectivePoint{X: c.mul(p.X, big.NewInt(5)), Y: c.mul(p.Y, big.NewInt(5)), Z: big.NewInt(5)}
+	assert.True(t, c.ToAffine(c.AddProjective(scaled, qp)).Equal(c.Add(p, q)))
+}
+
+func TestScalarMult(t *testing.T) {
+	c := ToyCurve()
+
+	a, err := rand.Int(rand.Reader, c.N)
+	assert.Nil(t, err)
+	b, err := rand.Int(rand.Reader, c.N)
+	assert.Nil(t, err)
+
+	sum := new(big.Int).Add(a, b)
+	assert.True(t, c.ScalarBaseMult(sum).Equal(c.Add(c.ScalarBaseMult(a), c.ScalarBaseMult(b))))
+
+	prod := new(big.Int).Mul(a, b)
+	assert.True(t, c.ScalarBaseMult(prod).Equal(c.ScalarMult(c.ScalarBaseMult(a), b)))
+
+	assert.True(t, c.ScalarBaseMult(big.NewInt(0)).IsInfinity())
+	assert.True(t, c.ScalarBaseMult(big.NewInt(1)).Equal(c.G))
+	assert.True(t, c.ScalarBaseMult(big.NewInt(-1)).Equal(c.Neg(c.G)))
+	assert.True(t, c.ScalarBaseMult(new(big.Int).Add(c.N, big.NewInt(1))).Equal(c.G))
+}
+
+func TestValidate(t *testing.T) {
+	c := ToyCurve()
+
+	assert.Error(t, c.Validate(Infinity()))
+	assert.Error(t, c.Validate(Point{X: big.NewInt(182), Y: big.NewInt(1)}))
+	assert.Error(t, c.Validate(Point{X: c.G.X, Y: new(big.Int).Add(c.G.Y, c.P)}))
+
+	// Points of order 2 are on the curve, but not in the subgroup
+	p, err := c.RandomPoint()
+	assert.Nil(t, err)
+	small := c.ScalarMult(p, c.N)
+	if !small.IsInfinity() {
+		assert.True(t, c.IsOnCurve(small))
+		assert.Error(t, c.Validate(small))
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	c := ToyCurve()
+
+	assert.Equal(t, []byte{0x00}, c.Marshal(Infinity()))
+
+	out := c.Marshal(c.G)
+	assert.Equal(t, 1+2*16, len(out))
+	assert.Equal(t, byte(0x04), out[0])
+	assert.Equal(t, byte(182), out[16])
+}
+
+func TestECDH(t *testing.T) {
+	c := ToyCurve()
+
+	alice, err := GenerateKey(c)
+	assert.Nil(t, err)
+	bob, err := GenerateKey(c)
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.Validate(alice.Q))
+	assert.True(t, alice.SharedSecret(bob.Q).Equal(bob.SharedSecret(alice.Q)))
+	assert.True(t, NewPrivateKey(c, alice.D).Q.Equal(alice.Q))
+
+	msg := []byte("crazy flamboyant for the rap enjoyment")
+	assert.Equal(t, 32, len(MAC(c.Marshal(alice.SharedSecret(bob.Q)), msg)))
+}
+
+func TestToyMontgomeryCurve(t *testing.T) {
+	c := ToyMontgomeryCurve()
+	w := c.Weierstrass()
+	toy := ToyCurve()
+
+	assert.Equal(t, toy.A, w.A)
+	assert.Equal(t, toy.B, w.B)
+	assert.True(t, w.G.Equal(toy.G))
+	assert.True(t, c.FromWeierstrass(toy.G).Equal(c.G))
+
+	assert.True(t, c.IsOnCurve(c.G.X))
+	assert.False(t, c.IsOnTwist(c.G.X))
+	assert.Equal(t, 0, c.Ladder(c.G.X, c.N).Sign())
+}
+
+func TestLadder(t *testing.T) {
+	c := ToyMontgomeryCurve()
+	w := c.Weierstrass()
+
+	for i := 0; i < 10; i++ {
+		k, err := rand.Int(rand.Reader, c.N)
+		assert.Nil(t, err)
+
+		expected := c.FromWeierstrass(w.ScalarBaseMult(k))
+		assert.Equal(t, expected.X, c.Ladder(c.G.X, k))
+	}
+
+	g2 := c.Ladder(c.G.X, big.NewInt(2))
+	g3 := c.Ladder(c.G.X, big.NewInt(3))
+	assert.Equal(t, g3, c.DifferentialAdd(g2, c.G.X, c.G.X))
+}
+
+func TestLift(t *testing.T) {
+	c := ToyMontgomeryCurve()
+
+	p, err := c.Lift(c.G.X)
+	assert.Nil(t, err)
+	assert.True(t, p.Equal(c.G) || p.Equal(Point{X: c.G.X, Y: new(big.Int).Sub(c.P, c.G.Y)}))
+
+	u, err := c.RandomTwistPoint()
+	assert.Nil(t, err)
+	assert.True(t, c.IsOnTwist(u))
+	_, err = c.Lift(u)
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, c.Ladder(u, c.TwistOrder).Sign())
+}
+
+func TestMontgomerySharedSecret(t *testing.T) {
+	c := ToyMontgomeryCurve()
+
+	alice, err := GenerateMontgomeryKey(c)
+	assert.Nil(t, err)
+	bob, err := GenerateMontgomeryKey(c)
+	assert.Nil(t, err)
+
+	assert.Equal(t, alice.SharedSecret(bob.U), bob.SharedSecret(alice.U))
+	assert.Equal(t, c.Ladder(c.G.X, new(big.Int).Sub(c.N, alice.D)), alice.U)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	priv, err := GenerateKey(ToyCurve())
+	assert.Nil(t, err)
+
+	msg := []byte("Hello world")
+
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		sig, err := priv.Sign(hash, msg)
+		assert.Nil(t, err)
+		assert.True(t, priv.Verify(hash, msg, sig))
+
+		assert.False(t, priv.Verify(hash, []byte("Hello world!"), sig))
+
+		tampered := Signature{R: sig.R, S: new(big.Int).Add(sig.S, big.NewInt(1))}
+		assert.False(t, priv.Verify(hash, msg, tampered))
+	}
+}
+
+func TestSignFrom(t *testing.T) {
+	priv, err := GenerateKeyFrom(randutil.NewSeeded(42), ToyCurve())
+	assert.Nil(t, err)
+
+	msg := []byte("Hello world")
+	a, err := priv.SignFrom(randutil.NewSeeded(1337), crypto.SHA256, msg)
+	assert.Nil(t, err)
+	b, err := priv.SignFrom(randutil.NewSeeded(1337), crypto.SHA256, msg)
+	assert.Nil(t, err)
+
+	assert.Equal(t, a, b)
+	assert.True(t, priv.Verify(crypto.SHA256, msg, a))
+}
+
+func TestSignWithNonce(t *testing.T) {
+	priv := NewPrivateKey(ToyCurve(), big.NewInt(1337))
+	msg := []byte("Hello world")
+
+	a, err := priv.SignWithNonce(crypto.SHA256, msg, big.NewInt(42))
+	assert.Nil(t, err)
+	b, err := priv.SignWithNonce(crypto.SHA256, msg, big.NewInt(42))
+	assert.Nil(t, err)
+
+	assert.Equal(t, a, b)
+	assert.True(t, priv.Verify(crypto.SHA256, msg, a))
+
+	// r only depends on the nonce
+	r := priv.ScalarBaseMult(big.NewInt(42)).X
+	assert.Equal(t, new(big.Int).Mod(r, priv.N), a.R)
+}
+
+func TestP256(t *testing.T) {
+	c := P256()
+
+	assert.True(t, c.IsOnCurve(c.G))
+	assert.Nil(t, c.Validate(c.G))
+	assert.True(t, c.ScalarBaseMult(c.N).IsInfinity())
+
+	// Compare against the standard library
+	k, err := rand.Int(rand.Reader, c.N)
+	assert.Nil(t, err)
+	x, y := elliptic.P256().ScalarBaseMult(k.Bytes())
+	assert.True(t, c.ScalarBaseMult(k).Equal(Point{X: x, Y: y}))
+}
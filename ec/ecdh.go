@@ -0,0 +1,114 @@
+package ec
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// PublicKey is an elliptic curve public key, consisting of the curve and the
+// point Q = D * G.
+type PublicKey struct {
+	Curve
+	Q Point
+}
+
+// PrivateKey is an elliptic curve private key, consisting of the public key
+// and the secret scalar D.
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// GenerateKey generates a new key pair on the given curve.
+//
+// The private scalar is chosen uniformly at random from [1, N).
+func GenerateKey(c Curve) (PrivateKey, error) {
+	return GenerateKeyFrom(nil, c)
+}
+
+// GenerateKeyFrom generates a new key pair as GenerateKey() does, reading
+// from the given source of randomness. If r is nil, a CSPRNG is used.
+func GenerateKeyFrom(r io.Reader, c Curve) (PrivateKey, error) {
+	d, err := rand.Int(randutil.Reader(r), new(big.Int).Sub(c.N, big.NewInt(1)))
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("Error generating private key: %v", err)
+	}
+	d.Add(d, big.NewInt(1))
+
+	return NewPrivateKey(c, d), nil
+}
+
+// NewPrivateKey derives the key pair belonging to the private scalar d.
+func NewPrivateKey(c Curve, d *big.Int) PrivateKey {
+	return PrivateKey{
+		PublicKey: PublicKey{Curve: c, Q: c.ScalarBaseMult(d)},
+		D:         new(big.Int).Set(d),
+	}
+}
+
+// SharedSecret calculates the ECDH shared secret D * peer with the peer's
+// public key.
+//
+// The peer's public key is not validated, which must be done separately with
+// Curve.Validate().
+func (priv *PrivateKey) SharedSecret(peer Point) Point {
+	return priv.ScalarMult(peer, priv.D)
+}
+
+// MontgomeryPublicKey is a public key on a Montgomery curve, consisting of
+// the curve and the u-coordinate U of D * G.
+type MontgomeryPublicKey struct {
+	MontgomeryCurve
+	U *big.Int
+}
+
+// MontgomeryPrivateKey is a private key on a Montgomery curve, consisting of
+// the public key and the secret scalar D.
+type MontgomeryPrivateKey struct {
+	MontgomeryPublicKey
+	D *big.Int
+}
+
+// GenerateMontgomeryKey generates a new key pair on the given Montgomery
+// curve.
+//
+// The private scalar is chosen uniformly at random from [1, N).
+func GenerateMontgomeryKey(c MontgomeryCurve) (MontgomeryPrivateKey, error) {
+	return GenerateMontgomeryKeyFrom(nil, c)
+}
+
+// GenerateMontgomeryKeyFrom generates a new key pair as
+// GenerateMontgomeryKey() does, reading from the given source of randomness.
+// If r is nil, a CSPRNG is used.
+func GenerateMontgomeryKeyFrom(r io.Reader, c MontgomeryCurve) (MontgomeryPrivateKey, error) {
+	d, err := rand.Int(randutil.Reader(r), new(big.Int).Sub(c.N, big.NewInt(1)))
+	if err != nil {
+		return MontgomeryPrivateKey{}, fmt.Errorf("Error generating private key: %v", err)
+	}
+	d.Add(d, big.NewInt(1))
+
+	return NewMontgomeryPrivateKey(c, d), nil
+}
+
+// NewMontgomeryPrivateKey derives the key pair belonging to the private
+// scalar d.
+func NewMontgomeryPrivateKey(c MontgomeryCurve, d *big.Int) MontgomeryPrivateKey {
+	return MontgomeryPrivateKey{
+		MontgomeryPublicKey: MontgomeryPublicKey{MontgomeryCurve: c, U: c.Ladder(c.G.X, d)},
+		D:                   new(big.Int).Set(d),
+	}
+}
+
+// SharedSecret calculates the u-coordinate of the ECDH shared secret D * peer
+// with the u-coordinate of the peer's public key, using only the Montgomery
+// ladder.
+//
+// The peer's public key is not validated, which must be done separately with
+// MontgomeryCurve.IsOnCurve().
+func (priv *MontgomeryPrivateKey) SharedSecret(peer *big.Int) *big.Int {
+	return priv.Ladder(peer, priv.D)
+}
@@ -0,0 +1,138 @@
+package ec
+
+import (
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dsa"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// Signature is an ECDSA signature.
+type Signature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// errDegenerateSignature is returned if either component of a signature
+// turned out to be zero.
+var errDegenerateSignature = fmt.Errorf("Degenerate signature, r or s is zero")
+
+// Sign signs the message with ECDSA, using a nonce chosen uniformly at
+// random.
+//
+// The message is hashed with the given hash function, which must be
+// available in the binary, such as crypto.SHA1 or crypto.SHA256, and
+// converted to an integer as by dsa.Digest().
+func (priv *PrivateKey) Sign(hash crypto.Hash, msg []byte) (Signature, error) {
+	return priv.SignFrom(nil, hash, msg)
+}
+
+// SignFrom signs the message as Sign() does, reading the nonce from the given
+// source of randomness. If r is nil, a CSPRNG is used.
+func (priv *PrivateKey) SignFrom(r io.Reader, hash crypto.Hash, msg []byte) (Signature, error) {
+	for {
+		k, err := rand.Int(randutil.Reader(r), new(big.Int).Sub(priv.N, big.NewInt(1)))
+		if err != nil {
+			return Signature{}, fmt.Errorf("Error generating nonce: %v", err)
+		}
+		k.Add(k, big.NewInt(1))
+
+		sig, err := priv.SignWithNonce(hash, msg, k)
+		if err == errDegenerateSignature {
+			continue
+		}
+
+		return sig, err
+	}
+}
+
+// SignWithNonce signs the message with ECDSA, using the caller-supplied nonce
+// k.
+//
+// The nonce must be kept secret and must never be reused, nor be biased in
+// any way, as this allows recovery of the private key. Use Sign() instead.
+//
+// An error is returned if the resulting signature is degenerate, that is if
+// either r or s is zero.
+func (priv *PrivateKey) SignWithNonce(hash crypto.Hash, msg []byte, k *big.Int) (Signature, error) {
+	h, err := dsa.Digest(hash, msg, priv.N)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	kInv := new(big.Int).ModInverse(k, priv.N)
+	if kInv == nil {
+		return Signature{}, fmt.Errorf("Nonce %v is not invertible modulo n", k)
+	}
+
+	// r = x(k * G) mod n
+	r := new(big.Int).Mod(priv.ScalarBaseMult(k).X, priv.N)
+
+	// s = k^-1 (H(m) + d * r) mod n
+	s := new(big.Int).Mul(priv.D, r)
+	s.Add(s, h)
+	s.Mul(s, kInv)
+	s.Mod(s, priv.N)
+
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return Signature{}, errDegenerateSignature
+	}
+
+	return Signature{R: r, S: s}, nil
+}
+
+// Verify checks whether the ECDSA signature is valid for the given message.
+//
+// Signatures with either component outside of (0, n) are rejected.
+func (pub *PublicKey) Verify(hash crypto.Hash, msg []byte, sig Signature) bool {
+	if sig.R == nil || sig.S == nil {
+		return false
+	}
+
+	if sig.R.Sign() <= 0 || sig.R.Cmp(pub.N) >= 0 {
+		return false
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(pub.N) >= 0 {
+		return false
+	}
+
+	h, err := dsa.Digest(hash, msg, pub.N)
+	if err != nil {
+		return false
+	}
+
+	point := pub.R(h, sig)
+	if point.IsInfinity() {
+		return false
+	}
+
+	return new(big.Int).Mod(point.X, pub.N).Cmp(sig.R) == 0
+}
+
+// R calculates the point whose x-coordinate a verifier compares against the
+// r component of the signature, that is:
+//
+//	w = s^-1 mod n
+//	u1 = H(m) * w mod n
+//	u2 = r * w mod n
+//	R = u1 * G + u2 * Q
+//
+// No validation of the signature's components takes place. If s is not
+// invertible, the point at infinity is returned.
+func (pub *PublicKey) R(h *big.Int, sig Signature) Point {
+	w := new(big.Int).ModInverse(sig.S, pub.N)
+	if w == nil {
+		return Infinity()
+	}
+
+	u1 := new(big.Int).Mul(h, w)
+	u1.Mod(u1, pub.N)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, pub.N)
+
+	return pub.Add(pub.ScalarBaseMult(u1), pub.ScalarMult(pub.Q, u2))
+}
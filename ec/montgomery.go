@@ -0,0 +1,253 @@
+package ec
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// MontgomeryCurve is an elliptic curve in Montgomery form:
+//
+//	B*v^2 = u^3 + A*u^2 + u
+//
+// over the prime field GF(P), with a base point G of prime order N.
+//
+// Arithmetic on Montgomery curves is usually done on the u-coordinate alone,
+// using the Montgomery ladder. As -P and P share their u-coordinate, the
+// ladder can only calculate k * P up to its sign.
+//
+// Every u in GF(P) is either the u-coordinate of a point on the curve, or of
+// a point on its quadratic twist, the curve with B replaced by a non-square.
+// The ladder does not depend on B, so it works on the twist just as well.
+type MontgomeryCurve struct {
+	P *big.Int
+	A *big.Int
+	B *big.Int
+	G Point
+	N *big.Int
+	// Order is the number of points on the curve.
+	Order *big.Int
+	// TwistOrder is the number of points on the curve's quadratic twist,
+	// which is 2*P + 2 - Order.
+	TwistOrder *big.Int
+}
+
+// ToyMontgomeryCurve returns the Montgomery form of ToyCurve():
+//
+//	v^2 = u^3 + 534*u^2 + u
+//
+// which maps to it by x = u + 178. Its base point (4,
+// 85518893674295321206118380980485522083) maps to the one of ToyCurve().
+func ToyMontgomeryCurve() MontgomeryCurve {
+	toy := ToyCurve()
+
+	twist := new(big.Int).Lsh(toy.P, 1)
+	twist.Add(twist, big.NewInt(2))
+	twist.Sub(twist, toy.Order)
+
+	return MontgomeryCurve{
+		P:          toy.P,
+		A:          big.NewInt(534),
+		B:          big.NewInt(1),
+		G:          Point{X: big.NewInt(4), Y: new(big.Int).Set(toy.G.Y)},
+		N:          toy.N,
+		Order:      toy.Order,
+		TwistOrder: twist,
+	}
+}
+
+// IsOnCurve returns whether u is the u-coordinate of a point on the curve.
+//
+// Points with v = 0, which are of order two, lie on both the curve and its
+// twist.
+func (c MontgomeryCurve) IsOnCurve(u *big.Int) bool {
+	return big.Jacobi(c.rhs(u), c.P) >= 0
+}
+
+// IsOnTwist returns whether u is the u-coordinate of a point on the curve's
+// quadratic twist.
+func (c MontgomeryCurve) IsOnTwist(u *big.Int) bool {
+	return big.Jacobi(c.rhs(u), c.P) <= 0
+}
+
+// RandomTwistPoint returns the u-coordinate of a random point on the curve's
+// quadratic twist, which is not on the curve itself.
+func (c MontgomeryCurve) RandomTwistPoint() (*big.Int, error) {
+	return c.RandomTwistPointFrom(nil)
+}
+
+// RandomTwistPointFrom returns a random point on the twist as
+// RandomTwistPoint() does, reading from the given source of randomness. If r
+// is nil, a CSPRNG is used.
+func (c MontgomeryCurve) RandomTwistPointFrom(r io.Reader) (*big.Int, error) {
+	for {
+		u, err := rand.Int(randutil.Reader(r), c.P)
+		if err != nil {
+			return nil, fmt.Errorf("Error generating random point: %v", err)
+		}
+
+		if !c.IsOnCurve(u) {
+			return u, nil
+		}
+	}
+}
+
+// Lift returns one of the two points on the curve with u-coordinate u, or an
+// error if there is none. The other one is its inverse.
+func (c MontgomeryCurve) Lift(u *big.Int) (Point, error) {
+	v := new(big.Int).ModSqrt(c.rhs(u), c.P)
+	if v == nil {
+		return Point{}, fmt.Errorf("No point with u-coordinate %v on curve", u)
+	}
+
+	return Point{X: new(big.Int).Set(u), Y: v}, nil
+}
+
+// Ladder calculates the u-coordinate of k * P, where u is the u-coordinate of
+// P, using the Montgomery ladder in projective coordinates.
+//
+// The point at infinity has no u-coordinate, so 0 is returned in its place.
+// This is also the u-coordinate of the point (0, 0) of order two.
+func (c MontgomeryCurve) Ladder(u *big.Int, k *big.Int) *big.Int {
+	u2, w2 := big.NewInt(1), big.NewInt(0)
+	u3, w3 := new(big.Int).Mod(u, c.P), big.NewInt(1)
+
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		b := k.Bit(i)
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+
+		// Differential addition, with the difference being P
+		t1 := c.sub(c.mul(u2, u3), c.mul(w2, w3))
+		t2 := c.sub(c.mul(u2, w3), c.mul(w2, u3))
+		u3, w3 = c.mul(t1, t1), c.mul(u, c.mul(t2, t2))
+
+		// Doubling
+		uu := c.mul(u2, u2)
+		ww := c.mul(w2, w2)
+		uw := c.mul(u2, w2)
+		t1 = c.sub(uu, ww)
+		t2 = c.add(c.add(uu, c.mul(c.A, uw)), ww)
+		u2, w2 = c.mul(t1, t1), c.mul(c.mul(big.NewInt(4), uw), t2)
+
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+	}
+
+	return c.affine(u2, w2)
+}
+
+// DifferentialAdd calculates the u-coordinate of P + Q, given the
+// u-coordinates of P, Q and P - Q. This requires P != ±Q, and P - Q not to
+// be of order two.
+func (c MontgomeryCurve) DifferentialAdd(p, q, diff *big.Int) *big.Int {
+	t1 := c.sub(c.mul(p, q), big.NewInt(1))
+	t2 := c.sub(p, q)
+
+	return c.affine(c.mul(t1, t1), c.mul(diff, c.mul(t2, t2)))
+}
+
+// Weierstrass returns the curve in short Weierstrass form which this curve
+// is isomorphic to, as given by ToWeierstrass().
+func (c MontgomeryCurve) Weierstrass() Curve {
+	// a = (3 - A^2) / (3*B^2)
+	a := c.sub(big.NewInt(3), c.mul(c.A, c.A))
+	a = c.mul(a, c.inverse(c.mul(big.NewInt(3), c.mul(c.B, c.B))))
+
+	// b = (2*A^3 - 9*A) / (27*B^3)
+	b := c.mul(big.NewInt(2), c.mul(c.A, c.mul(c.A, c.A)))
+	b = c.sub(b, c.mul(big.NewInt(9), c.A))
+	b = c.mul(b, c.inverse(c.mul(big.NewInt(27), c.mul(c.B, c.mul(c.B, c.B)))))
+
+	return Curve{
+		P:     c.P,
+		A:     a,
+		B:     b,
+		G:     c.ToWeierstrass(c.G),
+		N:     c.N,
+		Order: c.Order,
+	}
+}
+
+// ToWeierstrass maps a point on this curve to the curve returned by
+// Weierstrass(), using:
+//
+//	(x, y) = (u/B + A/(3*B), v/B)
+func (c MontgomeryCurve) ToWeierstrass(p Point) Point {
+	if p.IsInfinity() {
+		return Infinity()
+	}
+
+	invB := c.inverse(c.B)
+	x := c.add(c.mul(p.X, invB), c.mul(c.A, c.inverse(c.mul(big.NewInt(3), c.B))))
+
+	return Point{X: x, Y: c.mul(p.Y, invB)}
+}
+
+// FromWeierstrass maps a point on the curve returned by Weierstrass() to this
+// curve, using:
+//
+//	(u, v) = (B*x - A/3, B*y)
+func (c MontgomeryCurve) FromWeierstrass(p Point) Point {
+	if p.IsInfinity() {
+		return Infinity()
+	}
+
+	u := c.sub(c.mul(c.B, p.X), c.mul(c.A, c.inverse(big.NewInt(3))))
+
+	return Point{X: u, Y: c.mul(c.B, p.Y)}
+}
+
+// Marshal encodes the u-coordinate as big-endian integer of the length of P.
+func (c MontgomeryCurve) Marshal(u *big.Int) []byte {
+	out := make([]byte, (c.P.BitLen()+7)/8)
+	u.FillBytes(out)
+
+	return out
+}
+
+// rhs calculates (u^3 + A*u^2 + u) / B mod P.
+func (c MontgomeryCurve) rhs(u *big.Int) *big.Int {
+	out := new(big.Int).Add(u, c.A)
+	out.Mul(out, u)
+	out.Add(out, big.NewInt(1))
+	out.Mul(out, u)
+
+	return c.mul(out, c.inverse(c.B))
+}
+
+// affine converts the projective u-coordinate (u : w) to an affine one,
+// mapping the point at infinity to 0.
+func (c MontgomeryCurve) affine(u, w *big.Int) *big.Int {
+	if w.Sign() == 0 {
+		return big.NewInt(0)
+	}
+
+	return c.mul(u, c.inverse(w))
+}
+
+func (c MontgomeryCurve) inverse(x *big.Int) *big.Int {
+	return new(big.Int).ModInverse(x, c.P)
+}
+
+func (c MontgomeryCurve) mul(x, y *big.Int) *big.Int {
+	out := new(big.Int).Mul(x, y)
+	return out.Mod(out, c.P)
+}
+
+func (c MontgomeryCurve) add(x, y *big.Int) *big.Int {
+	out := new(big.Int).Add(x, y)
+	return out.Mod(out, c.P)
+}
+
+func (c MontgomeryCurve) sub(x, y *big.Int) *big.Int {
+	out := new(big.Int).Sub(x, y)
+	return out.Mod(out, c.P)
+}
@@ -0,0 +1,148 @@
+// Package gf128 implements arithmetic in the finite field GF(2^128) as used
+// by GCM, that is modulo the polynomial:
+//
+//	x^128 + x^7 + x^2 + x + 1
+package gf128
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// Size is the length of the encoding of an element in bytes.
+const Size = 16
+
+// r is the reduction polynomial without its x^128 term, in GCM's bit order.
+const r = 0xe1 << 56
+
+// Element is an element of GF(2^128), that is a polynomial of degree less
+// than 128 over GF(2).
+//
+// It uses GCM's bit order, where the most significant bit of the first byte
+// of the encoding is the coefficient of x^0. Hi holds the first eight bytes
+// of the encoding as big-endian integer, and Lo the remaining ones. As such
+// the most significant bit of Hi is the coefficient of x^0, and the least
+// significant bit of Lo the one of x^127.
+type Element struct {
+	Hi uint64
+	Lo uint64
+}
+
+// Zero returns the additive identity.
+func Zero() Element {
+	return Element{}
+}
+
+// One returns the multiplicative identity.
+func One() Element {
+	return Element{Hi: 1 << 63}
+}
+
+// FromBytes decodes an element from its 16 byte encoding. Shorter inputs are
+// padded with zero bytes on the right, as GCM does for partial blocks.
+//
+// It panics if the input is longer than 16 bytes.
+func FromBytes(b []byte) Element {
+	if len(b) > Size {
+		panic(fmt.Sprintf("Expected at most %d bytes, got %d", Size, len(b)))
+	}
+
+	var block [Size]byte
+	copy(block[:], b)
+
+	return Element{
+		Hi: binary.BigEndian.Uint64(block[:8]),
+		Lo: binary.BigEndian.Uint64(block[8:]),
+	}
+}
+
+// Bytes returns the 16 byte encoding of the element.
+func (a Element) Bytes() []byte {
+	out := make([]byte, Size)
+	binary.BigEndian.PutUint64(out[:8], a.Hi)
+	binary.BigEndian.PutUint64(out[8:], a.Lo)
+
+	return out
+}
+
+// String returns the hex encoding of the element.
+func (a Element) String() string {
+	return fmt.Sprintf("%016x%016x", a.Hi, a.Lo)
+}
+
+// IsZero returns whether the element is zero.
+func (a Element) IsZero() bool {
+	return a.Hi == 0 && a.Lo == 0
+}
+
+// Add returns a + b, which in a field of characteristic two is the same as
+// a - b, and simply the XOR of the two.
+func (a Element) Add(b Element) Element {
+	return Element{Hi: a.Hi ^ b.Hi, Lo: a.Lo ^ b.Lo}
+}
+
+// Mul returns a * b, using the shift-and-add algorithm of NIST SP 800-38D.
+// It uses masks rather than branches, so it runs in constant time.
+func (a Element) Mul(b Element) Element {
+	var z Element
+	v := b
+
+	for _, word := range [2]uint64{a.Hi, a.Lo} {
+		for i := 63; i >= 0; i-- {
+			// All ones if the bit is set, zero otherwise
+			mask := -((word >> i) & 1)
+			z.Hi ^= v.Hi & mask
+			z.Lo ^= v.Lo & mask
+
+			// v = v * x, that is a right shift in GCM's bit order
+			carry := -(v.Lo & 1)
+			v.Lo = (v.Lo >> 1) | (v.Hi << 63)
+			v.Hi = (v.Hi >> 1) ^ (r & carry)
+		}
+	}
+
+	return z
+}
+
+// Square returns a * a.
+func (a Element) Square() Element {
+	return a.Mul(a)
+}
+
+// Exp returns a^k, using square-and-multiply. The exponent must not be
+// negative.
+func (a Element) Exp(k *big.Int) Element {
+	out := One()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		out = out.Square()
+		if k.Bit(i) == 1 {
+			out = out.Mul(a)
+		}
+	}
+
+	return out
+}
+
+// Inverse returns the multiplicative inverse of a, as a^(2^128 - 2).
+//
+// Zero has no inverse, in which case zero is returned.
+func (a Element) Inverse() Element {
+	k := new(big.Int).Lsh(big.NewInt(1), 128)
+	k.Sub(k, big.NewInt(2))
+
+	return a.Exp(k)
+}
+
+// Sqrt returns the unique square root of a, as a^(2^127).
+//
+// Squaring is the Frobenius automorphism in a field of characteristic two,
+// so it is a bijection, and a^(2^128) = a.
+func (a Element) Sqrt() Element {
+	out := a
+	for i := 0; i < 127; i++ {
+		out = out.Square()
+	}
+
+	return out
+}
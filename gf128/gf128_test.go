@@ -0,0 +1,74 @@
+package gf128
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomElement(t *testing.T) Element {
+	b := make([]byte, Size)
+	_, err := rand.Read(b)
+	assert.Nil(t, err)
+
+	return FromBytes(b)
+}
+
+func TestBytes(t *testing.T) {
+	b, _ := hex.DecodeString("0388dace60b6a392f328c2b971b2fe78")
+	a := FromBytes(b)
+	assert.Equal(t, b, a.Bytes())
+	assert.Equal(t, "0388dace60b6a392f328c2b971b2fe78", a.String())
+
+	// Short inputs are padded on the right
+	assert.Equal(t, "ab000000000000000000000000000000", FromBytes([]byte{0xab}).String())
+
+	assert.Panics(t, func() { FromBytes(make([]byte, Size+1)) })
+}
+
+func TestMul(t *testing.T) {
+	// H * C_1 of GCM test case 2
+	h := FromBytes(mustDecodeHex("66e94bd4ef8a2c3b884cfa59ca342b2e"))
+	c := FromBytes(mustDecodeHex("0388dace60b6a392f328c2b971b2fe78"))
+	assert.Equal(t, "5e2ec746917062882c85b0685353deb7", c.Mul(h).String())
+
+	a := randomElement(t)
+	b := randomElement(t)
+	assert.Equal(t, a, a.Mul(One()))
+	assert.Equal(t, Zero(), a.Mul(Zero()))
+	assert.Equal(t, a.Mul(b), b.Mul(a))
+	assert.Equal(t, a.Square(), a.Mul(a))
+
+	// x * x^127 = x^128 = x^7 + x^2 + x + 1
+	x := Element{Hi: 1 << 62}
+	x127 := Element{Lo: 1}
+	assert.Equal(t, "e1000000000000000000000000000000", x.Mul(x127).String())
+}
+
+func TestExpAndInverse(t *testing.T) {
+	a := randomElement(t)
+
+	assert.Equal(t, One(), a.Exp(big.NewInt(0)))
+	assert.Equal(t, a, a.Exp(big.NewInt(1)))
+	assert.Equal(t, a.Mul(a).Mul(a), a.Exp(big.NewInt(3)))
+
+	// a^(2^128) = a
+	k := new(big.Int).Lsh(big.NewInt(1), 128)
+	assert.Equal(t, a, a.Exp(k))
+
+	assert.Equal(t, One(), a.Mul(a.Inverse()))
+	assert.Equal(t, One(), One().Inverse())
+	assert.True(t, Zero().Inverse().IsZero())
+}
+
+func mustDecodeHex(s string) []byte {
+	out, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return out
+}
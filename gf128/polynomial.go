@@ -0,0 +1,478 @@
+package gf128
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// Polynomial is a polynomial with coefficients in GF(2^128). The coefficient
+// of x^i is stored at index i.
+//
+// Polynomials are normalized such that the leading coefficient is non-zero,
+// the zero polynomial being the empty slice. Operations return new
+// polynomials and leave their inputs untouched.
+type Polynomial []Element
+
+// Factor is a factor of a polynomial, along with its multiplicity.
+type Factor struct {
+	Polynomial   Polynomial
+	Multiplicity int
+}
+
+// NewPolynomial returns the polynomial with the given coefficients, starting
+// with the one of x^0.
+func NewPolynomial(coefficients ...Element) Polynomial {
+	p := make(Polynomial, len(coefficients))
+	copy(p, coefficients)
+
+	return p.normalize()
+}
+
+// X returns the polynomial x.
+func X() Polynomial {
+	return Polynomial{Zero(), One()}
+}
+
+// Degree returns the degree of the polynomial, which is -1 for the zero
+// polynomial.
+func (p Polynomial) Degree() int {
+	return len(p) - 1
+}
+
+// IsZero returns whether p is the zero polynomial.
+func (p Polynomial) IsZero() bool {
+	return len(p) == 0
+}
+
+// IsOne returns whether p is the constant polynomial 1.
+func (p Polynomial) IsOne() bool {
+	return len(p) == 1 && p[0] == One()
+}
+
+// Equal returns whether the two polynomials are equal.
+func (p Polynomial) Equal(q Polynomial) bool {
+	if len(p) != len(q) {
+		return false
+	}
+
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns a human-readable representation of the polynomial.
+func (p Polynomial) String() string {
+	if p.IsZero() {
+		return "0"
+	}
+
+	terms := make([]string, 0, len(p))
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].IsZero() {
+			continue
+		}
+
+		terms = append(terms, fmt.Sprintf("%v*x^%d", p[i], i))
+	}
+
+	return strings.Join(terms, " + ")
+}
+
+// Eval evaluates the polynomial at x, using Horner's method.
+func (p Polynomial) Eval(x Element) Element {
+	out := Zero()
+	for i := len(p) - 1; i >= 0; i-- {
+		out = out.Mul(x).Add(p[i])
+	}
+
+	return out
+}
+
+// Add returns p + q, which in a field of characteristic two is the same as
+// p - q.
+func (p Polynomial) Add(q Polynomial) Polynomial {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+
+	out := make(Polynomial, len(p))
+	copy(out, p)
+	for i := range q {
+		out[i] = out[i].Add(q[i])
+	}
+
+	return out.normalize()
+}
+
+// Scale returns c * p.
+func (p Polynomial) Scale(c Element) Polynomial {
+	out := make(Polynomial, len(p))
+	for i := range p {
+		out[i] = p[i].Mul(c)
+	}
+
+	return out.normalize()
+}
+
+// Mul returns p * q.
+func (p Polynomial) Mul(q Polynomial) Polynomial {
+	if p.IsZero() || q.IsZero() {
+		return Polynomial{}
+	}
+
+	out := make(Polynomial, len(p)+len(q)-1)
+	for i := range p {
+		if p[i].IsZero() {
+			continue
+		}
+
+		for j := range q {
+			out[i+j] = out[i+j].Add(p[i].Mul(q[j]))
+		}
+	}
+
+	return out.normalize()
+}
+
+// DivMod returns the quotient and remainder of the polynomial long division
+// of p by q, such that p = quo * q + rem and deg(rem) < deg(q).
+//
+// It panics if q is the zero polynomial.
+func (p Polynomial) DivMod(q Polynomial) (quo Polynomial, rem Polynomial) {
+	if q.IsZero() {
+		panic("Division by zero polynomial")
+	}
+
+	if p.Degree() < q.Degree() {
+		return Polynomial{}, NewPolynomial(p...)
+	}
+
+	rem = NewPolynomial(p...)
+	quo = make(Polynomial, p.Degree()-q.Degree()+1)
+	leadInverse := q[q.Degree()].Inverse()
+
+	for rem.Degree() >= q.Degree() {
+		shift := rem.Degree() - q.Degree()
+		c := rem[rem.Degree()].Mul(leadInverse)
+		quo[shift] = c
+
+		for i := range q {
+			rem[i+shift] = rem[i+shift].Add(q[i].Mul(c))
+		}
+		rem = rem.normalize()
+	}
+
+	return quo.normalize(), rem
+}
+
+// Mod returns p mod q.
+func (p Polynomial) Mod(q Polynomial) Polynomial {
+	_, rem := p.DivMod(q)
+	return rem
+}
+
+// Div returns the quotient of p divided by q, discarding any remainder.
+func (p Polynomial) Div(q Polynomial) Polynomial {
+	quo, _ := p.DivMod(q)
+	return quo
+}
+
+// Monic returns p divided by its leading coefficient. The zero polynomial is
+// returned as is.
+func (p Polynomial) Monic() Polynomial {
+	if p.IsZero() {
+		return Polynomial{}
+	}
+
+	return p.Scale(p[p.Degree()].Inverse())
+}
+
+// GCD returns the monic greatest common divisor of p and q, found with the
+// Euclidean algorithm.
+func GCD(p, q Polynomial) Polynomial {
+	for !q.IsZero() {
+		p, q = q, p.Mod(q)
+	}
+
+	return p.Monic()
+}
+
+// Derivative returns the formal derivative of p.
+//
+// As i * a = a for odd i and 0 for even i in characteristic two, only the
+// odd-degree terms survive.
+func (p Polynomial) Derivative() Polynomial {
+	if len(p) <= 1 {
+		return Polynomial{}
+	}
+
+	out := make(Polynomial, len(p)-1)
+	for i := 1; i < len(p); i += 2 {
+		out[i-1] = p[i]
+	}
+
+	return out.normalize()
+}
+
+// Sqrt returns the square root of a polynomial whose odd-degree coefficients
+// are all zero, as is the case if its derivative is zero.
+//
+// As squaring is linear in characteristic two, the square root of
+// sum(a_i * x^(2i)) is sum(sqrt(a_i) * x^i).
+func (p Polynomial) Sqrt() Polynomial {
+	out := make(Polynomial, (len(p)+1)/2)
+	for i := range out {
+		out[i] = p[2*i].Sqrt()
+	}
+
+	return out.normalize()
+}
+
+// ExpMod returns p^k mod m, using square-and-multiply. The exponent must not
+// be negative.
+func (p Polynomial) ExpMod(k *big.Int, m Polynomial) Polynomial {
+	base := p.Mod(m)
+	out := NewPolynomial(One()).Mod(m)
+
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		out = out.Mul(out).Mod(m)
+		if k.Bit(i) == 1 {
+			out = out.Mul(base).Mod(m)
+		}
+	}
+
+	return out
+}
+
+// Factor factors p into monic irreducible polynomials, along with their
+// multiplicities.
+//
+// It uses square-free factorization, then distinct-degree factorization of
+// each square-free part, and finally the Cantor-Zassenhaus algorithm to split
+// the products of irreducible factors of equal degree. The constant leading
+// coefficient of p is dropped. It panics if p is the zero polynomial.
+func (p Polynomial) Factor() ([]Factor, error) {
+	return p.FactorFrom(nil)
+}
+
+// FactorFrom factors p as Factor() does, reading from the given source of
+// randomness. If r is nil, a CSPRNG is used.
+func (p Polynomial) FactorFrom(r io.Reader) ([]Factor, error) {
+	factors := make([]Factor, 0)
+
+	for _, sff := range p.SquareFreeFactors() {
+		for _, ddf := range sff.Polynomial.DistinctDegreeFactors() {
+			edf, err := ddf.Polynomial.EqualDegreeFactorsFrom(r, ddf.Multiplicity)
+			if err != nil {
+				return []Factor{}, err
+			}
+
+			for _, f := range edf {
+				factors = append(factors, Factor{Polynomial: f, Multiplicity: sff.Multiplicity})
+			}
+		}
+	}
+
+	return factors, nil
+}
+
+// Roots returns the distinct roots of p in GF(2^128).
+//
+// These are the roots of its irreducible factors of degree one. As such only
+// the product of those, as found by distinct-degree factorization, is split
+// into its factors. It panics if p is the zero polynomial.
+func (p Polynomial) Roots() ([]Element, error) {
+	return p.RootsFrom(nil)
+}
+
+// RootsFrom returns the distinct roots of p as Roots() does, reading from the
+// given source of randomness. If r is nil, a CSPRNG is used.
+func (p Polynomial) RootsFrom(r io.Reader) ([]Element, error) {
+	roots := make([]Element, 0)
+	seen := make(map[Element]bool)
+
+	for _, sff := range p.SquareFreeFactors() {
+		for _, ddf := range sff.Polynomial.DistinctDegreeFactors() {
+			if ddf.Multiplicity != 1 {
+				continue
+			}
+
+			linear, err := ddf.Polynomial.EqualDegreeFactorsFrom(r, 1)
+			if err != nil {
+				return []Element{}, err
+			}
+
+			// x + a has the root a
+			for _, f := range linear {
+				if !seen[f[0]] {
+					seen[f[0]] = true
+					roots = append(roots, f[0])
+				}
+			}
+		}
+	}
+
+	return roots, nil
+}
+
+// SquareFreeFactors returns a factorization of p into monic, square-free and
+// pairwise coprime polynomials, along with their multiplicities.
+//
+// Factors of multiplicity i are extracted by repeatedly dividing by
+// gcd(p, p'). Factors whose multiplicity is a multiple of the characteristic
+// vanish in the derivative though, and leave a remainder which is a perfect
+// square, whose square root is factored recursively. It panics if p is the
+// zero polynomial.
+func (p Polynomial) SquareFreeFactors() []Factor {
+	if p.IsZero() {
+		panic("Cannot factor zero polynomial")
+	}
+
+	factors := make([]Factor, 0)
+	f := p.Monic()
+	if f.Degree() == 0 {
+		return factors
+	}
+
+	c := GCD(f, f.Derivative())
+	w := f.Div(c)
+
+	for i := 1; !w.IsOne(); i++ {
+		y := GCD(w, c)
+		fac := w.Div(y)
+		if !fac.IsOne() {
+			factors = append(factors, Factor{Polynomial: fac, Multiplicity: i})
+		}
+
+		w = y
+		c = c.Div(y)
+	}
+
+	if !c.IsOne() {
+		for _, fac := range c.Sqrt().SquareFreeFactors() {
+			factors = append(factors, Factor{Polynomial: fac.Polynomial, Multiplicity: 2 * fac.Multiplicity})
+		}
+	}
+
+	return factors
+}
+
+// DistinctDegreeFactors splits the monic square-free polynomial p into
+// products of irreducible factors of equal degree. The Multiplicity field of
+// each returned factor holds this degree.
+//
+// It relies on x^(q^i) - x being the product of all monic irreducible
+// polynomials whose degree divides i, with q = 2^128 the size of the field.
+// Having removed all factors of degree less than i, the gcd of p with it
+// thus yields the product of those of degree i.
+func (p Polynomial) DistinctDegreeFactors() []Factor {
+	factors := make([]Factor, 0)
+	f := p.Monic()
+	h := X().Mod(f)
+
+	for i := 1; f.Degree() >= 2*i; i++ {
+		// h = h^q mod f, as 128 squarings
+		for j := 0; j < 128; j++ {
+			h = h.Mul(h).Mod(f)
+		}
+
+		g := GCD(f, h.Add(X()))
+		if !g.IsOne() {
+			factors = append(factors, Factor{Polynomial: g, Multiplicity: i})
+			f = f.Div(g)
+			h = h.Mod(f)
+		}
+	}
+
+	if f.Degree() > 0 {
+		factors = append(factors, Factor{Polynomial: f, Multiplicity: f.Degree()})
+	}
+
+	return factors
+}
+
+// EqualDegreeFactors splits the monic square-free polynomial p, all of whose
+// irreducible factors are of degree d, into those factors using the
+// Cantor-Zassenhaus algorithm.
+//
+// In characteristic two, the usual exponent (q^d - 1) / 2 is replaced by the
+// trace map T(h) = h + h^2 + h^4 + ... + h^(2^(128d - 1)). For a random h, T(h)
+// is zero modulo about half of the irreducible factors of p, and one modulo
+// the others, so gcd(p, T(h)) is a non-trivial factor with good probability.
+func (p Polynomial) EqualDegreeFactors(d int) ([]Polynomial, error) {
+	return p.EqualDegreeFactorsFrom(nil, d)
+}
+
+// EqualDegreeFactorsFrom splits p as EqualDegreeFactors() does, reading the
+// random polynomials from the given source of randomness. If r is nil, a
+// CSPRNG is used.
+func (p Polynomial) EqualDegreeFactorsFrom(r io.Reader, d int) ([]Polynomial, error) {
+	f := p.Monic()
+	if f.Degree() <= d {
+		return []Polynomial{f}, nil
+	}
+
+	for {
+		h, err := randomPolynomial(r, f.Degree())
+		if err != nil {
+			return []Polynomial{}, err
+		}
+
+		trace := h
+		square := h
+		for i := 1; i < 128*d; i++ {
+			square = square.Mul(square).Mod(f)
+			trace = trace.Add(square)
+		}
+
+		g := GCD(f, trace)
+		if g.Degree() <= 0 || g.Degree() == f.Degree() {
+			continue
+		}
+
+		left, err := g.EqualDegreeFactorsFrom(r, d)
+		if err != nil {
+			return []Polynomial{}, err
+		}
+
+		right, err := f.Div(g).EqualDegreeFactorsFrom(r, d)
+		if err != nil {
+			return []Polynomial{}, err
+		}
+
+		return append(left, right...), nil
+	}
+}
+
+// randomPolynomial returns a uniformly random polynomial of degree less than
+// n, reading from r.
+func randomPolynomial(r io.Reader, n int) (Polynomial, error) {
+	buf := make([]byte, n*Size)
+	if _, err := io.ReadFull(randutil.Reader(r), buf); err != nil {
+		return Polynomial{}, fmt.Errorf("Error generating random polynomial: %v", err)
+	}
+
+	p := make(Polynomial, n)
+	for i := range p {
+		p[i] = FromBytes(buf[i*Size : (i+1)*Size])
+	}
+
+	return p.normalize(), nil
+}
+
+// normalize strips zero leading coefficients.
+func (p Polynomial) normalize() Polynomial {
+	for len(p) > 0 && p[len(p)-1].IsZero() {
+		p = p[:len(p)-1]
+	}
+
+	return p
+}
@@ -0,0 +1,137 @@
+package gf128
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func linear(a Element) Polynomial {
+	return NewPolynomial(a, One())
+}
+
+func TestPolynomialArithmetic(t *testing.T) {
+	a := randomElement(t)
+	b := randomElement(t)
+	c := randomElement(t)
+
+	p := NewPolynomial(a, b, c, Zero(), Zero())
+	assert.Equal(t, 2, p.Degree())
+	assert.Equal(t, -1, NewPolynomial().Degree())
+	assert.True(t, p.Add(p).IsZero())
+
+	// p(x) = a + b*x + c*x^2
+	x := randomElement(t)
+	assert.Equal(t, a.Add(b.Mul(x)).Add(c.Mul(x).Mul(x)), p.Eval(x))
+
+	q := NewPolynomial(b, One())
+	product := p.Mul(q)
+	assert.Equal(t, 3, product.Degree())
+	assert.Equal(t, p.Eval(x).Mul(q.Eval(x)), product.Eval(x))
+
+	quo, rem := product.Add(NewPolynomial(a)).DivMod(q)
+	assert.True(t, quo.Equal(p))
+	assert.True(t, rem.Equal(NewPolynomial(a)))
+
+	assert.Panics(t, func() { p.DivMod(Polynomial{}) })
+
+	// (x + a)(x + b) and (x + a)(x + c) share the factor x + a
+	assert.True(t, GCD(linear(a).Mul(linear(b)), linear(a).Mul(linear(c)).Scale(b)).Equal(linear(a)))
+
+	// (x + a)^2 = x^2 + a^2 in characteristic two
+	square := linear(a).Mul(linear(a))
+	assert.True(t, square.Derivative().IsZero())
+	assert.True(t, square.Sqrt().Equal(linear(a)))
+	assert.Equal(t, a, a.Square().Sqrt())
+
+	assert.True(t, q.ExpMod(big.NewInt(5), p).Equal(q.Mul(q).Mul(q).Mul(q).Mul(q).Mod(p)))
+}
+
+func TestSquareFreeFactors(t *testing.T) {
+	a := randomElement(t)
+	b := randomElement(t)
+	c := randomElement(t)
+
+	// (x + a) * (x + b)^2 * (x + c)^3
+	p := linear(a).Mul(linear(b)).Mul(linear(b)).Mul(linear(c)).Mul(linear(c)).Mul(linear(c)).Scale(a)
+
+	factors := p.SquareFreeFactors()
+	multiplicities := make(map[int]Polynomial)
+	for _, f := range factors {
+		multiplicities[f.Multiplicity] = f.Polynomial
+	}
+
+	assert.Equal(t, 3, len(multiplicities))
+	assert.True(t, multiplicities[1].Equal(linear(a)))
+	assert.True(t, multiplicities[2].Equal(linear(b)))
+	assert.True(t, multiplicities[3].Equal(linear(c)))
+}
+
+func TestFactor(t *testing.T) {
+	roots := []Element{randomElement(t), randomElement(t), randomElement(t)}
+
+	// x^2 + x + g is irreducible iff g has trace one, which holds for
+	// about half of all g.
+	var irreducible Polynomial
+	for {
+		irreducible = NewPolynomial(randomElement(t), One(), One())
+		if len(irreducible.DistinctDegreeFactors()) == 1 && irreducible.DistinctDegreeFactors()[0].Multiplicity == 2 {
+			break
+		}
+	}
+
+	p := linear(roots[0]).Mul(linear(roots[1])).Mul(linear(roots[1])).Mul(linear(roots[2])).Mul(irreducible)
+
+	factors, err := p.Factor()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(factors))
+
+	product := NewPolynomial(One())
+	for _, f := range factors {
+		for i := 0; i < f.Multiplicity; i++ {
+			product = product.Mul(f.Polynomial)
+		}
+	}
+	assert.True(t, product.Equal(p))
+
+	found, err := p.Roots()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, roots, found)
+}
+
+func TestEqualDegreeFactors(t *testing.T) {
+	roots := make([]Element, 6)
+	p := NewPolynomial(One())
+	for i := range roots {
+		roots[i] = randomElement(t)
+		p = p.Mul(linear(roots[i]))
+	}
+
+	factors, err := p.EqualDegreeFactors(1)
+	assert.Nil(t, err)
+	assert.Equal(t, len(roots), len(factors))
+
+	found := make([]Element, len(factors))
+	for i, f := range factors {
+		assert.Equal(t, 1, f.Degree())
+		found[i] = f[0]
+	}
+	assert.ElementsMatch(t, roots, found)
+}
+
+func TestRootsFrom(t *testing.T) {
+	p := NewPolynomial(One())
+	for i := 0; i < 6; i++ {
+		p = p.Mul(linear(randomElement(t)))
+	}
+
+	// Factors are split off in an order depending on the random
+	// polynomials, which is reproducible with a seeded source.
+	a, err := p.RootsFrom(randutil.NewSeeded(42))
+	assert.Nil(t, err)
+	b, err := p.RootsFrom(randutil.NewSeeded(42))
+	assert.Nil(t, err)
+	assert.Equal(t, a, b)
+}
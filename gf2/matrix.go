@@ -0,0 +1,314 @@
+// Package gf2 implements linear algebra over GF(2), the field with two
+// elements, where addition is XOR and multiplication is AND.
+package gf2
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Matrix is a matrix over GF(2).
+//
+// Each row is stored as a bitset of 64 bit words, so adding rows - the core
+// operation of Gaussian elimination - processes 64 entries at a time.
+// Operations return new matrices and leave their inputs untouched, except
+// for SetBit().
+type Matrix struct {
+	rows  int
+	cols  int
+	words int
+	data  []uint64
+}
+
+// NewMatrix returns the zero matrix with the given number of rows and
+// columns.
+func NewMatrix(rows, cols int) *Matrix {
+	words := (cols + 63) / 64
+
+	return &Matrix{
+		rows:  rows,
+		cols:  cols,
+		words: words,
+		data:  make([]uint64, rows*words),
+	}
+}
+
+// Identity returns the n x n identity matrix.
+func Identity(n int) *Matrix {
+	m := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.SetBit(i, i, 1)
+	}
+
+	return m
+}
+
+// Rows returns the number of rows of the matrix.
+func (m *Matrix) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns of the matrix.
+func (m *Matrix) Cols() int {
+	return m.cols
+}
+
+// Bit returns the entry in row i and column j.
+func (m *Matrix) Bit(i, j int) uint {
+	m.check(i, j)
+	return uint(m.data[i*m.words+j/64]>>(j%64)) & 1
+}
+
+// SetBit sets the entry in row i and column j to the lowest bit of b.
+func (m *Matrix) SetBit(i, j int, b uint) {
+	m.check(i, j)
+
+	word := &m.data[i*m.words+j/64]
+	if b&1 == 1 {
+		*word |= 1 << (j % 64)
+	} else {
+		*word &^= 1 << (j % 64)
+	}
+}
+
+// Copy returns a deep copy of the matrix.
+func (m *Matrix) Copy() *Matrix {
+	out := NewMatrix(m.rows, m.cols)
+	copy(out.data, m.data)
+
+	return out
+}
+
+// Equal returns whether the two matrices have the same dimensions and
+// entries.
+func (m *Matrix) Equal(n *Matrix) bool {
+	if m.rows != n.rows || m.cols != n.cols {
+		return false
+	}
+
+	for i := range m.data {
+		if m.data[i] != n.data[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsZero returns whether all entries of the matrix are zero.
+func (m *Matrix) IsZero() bool {
+	for _, w := range m.data {
+		if w != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Add returns m + n, which must be of the same dimensions.
+func (m *Matrix) Add(n *Matrix) *Matrix {
+	if m.rows != n.rows || m.cols != n.cols {
+		panic(fmt.Sprintf("Cannot add %dx%d and %dx%d matrices", m.rows, m.cols, n.rows, n.cols))
+	}
+
+	out := m.Copy()
+	for i := range out.data {
+		out.data[i] ^= n.data[i]
+	}
+
+	return out
+}
+
+// Mul returns the product m * n. The number of columns of m must match the
+// number of rows of n.
+//
+// Row i of the product is the sum of those rows of n, for which the entry in
+// row i of m is set.
+func (m *Matrix) Mul(n *Matrix) *Matrix {
+	if m.cols != n.rows {
+		panic(fmt.Sprintf("Cannot multiply %dx%d and %dx%d matrices", m.rows, m.cols, n.rows, n.cols))
+	}
+
+	out := NewMatrix(m.rows, n.cols)
+	for i := 0; i < m.rows; i++ {
+		dst := out.row(i)
+		for w, word := range m.row(i) {
+			for word != 0 {
+				j := w*64 + bits.TrailingZeros64(word)
+				word &= word - 1
+
+				for k, x := range n.row(j) {
+					dst[k] ^= x
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// Transpose returns the transpose of the matrix.
+func (m *Matrix) Transpose() *Matrix {
+	out := NewMatrix(m.cols, m.rows)
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			if m.Bit(i, j) == 1 {
+				out.SetBit(j, i, 1)
+			}
+		}
+	}
+
+	return out
+}
+
+// SubRows returns the matrix consisting of rows [from, to) of m.
+func (m *Matrix) SubRows(from, to int) *Matrix {
+	if from < 0 || to > m.rows || from > to {
+		panic(fmt.Sprintf("Row range [%d, %d) out of bounds for %d rows", from, to, m.rows))
+	}
+
+	out := NewMatrix(to-from, m.cols)
+	copy(out.data, m.data[from*m.words:to*m.words])
+
+	return out
+}
+
+// AppendRows returns the matrix consisting of the rows of m, followed by the
+// rows of n. Both must have the same number of columns.
+func (m *Matrix) AppendRows(n *Matrix) *Matrix {
+	if m.cols != n.cols {
+		panic(fmt.Sprintf("Cannot stack matrices with %d and %d columns", m.cols, n.cols))
+	}
+
+	out := NewMatrix(m.rows+n.rows, m.cols)
+	copy(out.data, m.data)
+	copy(out.data[len(m.data):], n.data)
+
+	return out
+}
+
+// Column returns column j of the matrix, as a column vector.
+func (m *Matrix) Column(j int) *Matrix {
+	out := NewMatrix(m.rows, 1)
+	for i := 0; i < m.rows; i++ {
+		out.SetBit(i, 0, m.Bit(i, j))
+	}
+
+	return out
+}
+
+// ReducedRowEchelon brings the matrix into reduced row echelon form using
+// Gaussian elimination. It returns the reduced matrix, along with the column
+// of the pivot of each of its non-zero rows.
+func (m *Matrix) ReducedRowEchelon() (*Matrix, []int) {
+	out := m.Copy()
+	pivots := make([]int, 0)
+
+	r := 0
+	for j := 0; j < out.cols && r < out.rows; j++ {
+		pivot := -1
+		for i := r; i < out.rows; i++ {
+			if out.Bit(i, j) == 1 {
+				pivot = i
+				break
+			}
+		}
+		if pivot == -1 {
+			continue
+		}
+
+		out.swapRows(r, pivot)
+
+		src := out.row(r)
+		for i := 0; i < out.rows; i++ {
+			if i != r && out.Bit(i, j) == 1 {
+				dst := out.row(i)
+				// Columns before j are zero in the pivot row
+				for w := j / 64; w < out.words; w++ {
+					dst[w] ^= src[w]
+				}
+			}
+		}
+
+		pivots = append(pivots, j)
+		r++
+	}
+
+	return out, pivots
+}
+
+// Rank returns the rank of the matrix.
+func (m *Matrix) Rank() int {
+	_, pivots := m.ReducedRowEchelon()
+	return len(pivots)
+}
+
+// Kernel returns a basis of the kernel - or null space - of the matrix, that
+// is of all vectors x such that m * x = 0. The basis vectors are the columns
+// of the returned matrix, which has zero columns if the kernel is trivial.
+//
+// Each column without a pivot in the reduced row echelon form corresponds to
+// a free variable. Setting it to one, and all other free variables to zero,
+// determines the pivot variables and yields one basis vector.
+func (m *Matrix) Kernel() *Matrix {
+	reduced, pivots := m.ReducedRowEchelon()
+
+	isPivot := make([]bool, m.cols)
+	for _, p := range pivots {
+		isPivot[p] = true
+	}
+
+	free := make([]int, 0, m.cols-len(pivots))
+	for j := 0; j < m.cols; j++ {
+		if !isPivot[j] {
+			free = append(free, j)
+		}
+	}
+
+	out := NewMatrix(m.cols, len(free))
+	for k, f := range free {
+		out.SetBit(f, k, 1)
+		for r, p := range pivots {
+			out.SetBit(p, k, reduced.Bit(r, f))
+		}
+	}
+
+	return out
+}
+
+// String returns the matrix as rows of zeros and ones.
+func (m *Matrix) String() string {
+	var sb strings.Builder
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			fmt.Fprintf(&sb, "%d", m.Bit(i, j))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// row returns the words backing row i.
+func (m *Matrix) row(i int) []uint64 {
+	return m.data[i*m.words : (i+1)*m.words]
+}
+
+func (m *Matrix) swapRows(i, j int) {
+	if i == j {
+		return
+	}
+
+	a, b := m.row(i), m.row(j)
+	for w := range a {
+		a[w], b[w] = b[w], a[w]
+	}
+}
+
+func (m *Matrix) check(i, j int) {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(fmt.Sprintf("Entry (%d, %d) out of bounds for %dx%d matrix", i, j, m.rows, m.cols))
+	}
+}
@@ -0,0 +1,128 @@
+package gf2
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fromRows(rows ...string) *Matrix {
+	m := NewMatrix(len(rows), len(rows[0]))
+	for i, row := range rows {
+		for j, c := range row {
+			if c == '1' {
+				m.SetBit(i, j, 1)
+			}
+		}
+	}
+
+	return m
+}
+
+func randomMatrix(rows, cols int) *Matrix {
+	m := NewMatrix(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			m.SetBit(i, j, uint(rand.Intn(2)))
+		}
+	}
+
+	return m
+}
+
+func TestMatrixBits(t *testing.T) {
+	m := NewMatrix(3, 130)
+	assert.True(t, m.IsZero())
+
+	m.SetBit(2, 129, 1)
+	m.SetBit(0, 64, 1)
+	assert.Equal(t, uint(1), m.Bit(2, 129))
+	assert.Equal(t, uint(1), m.Bit(0, 64))
+	assert.Equal(t, uint(0), m.Bit(0, 63))
+	assert.False(t, m.IsZero())
+
+	m.SetBit(2, 129, 0)
+	assert.Equal(t, uint(0), m.Bit(2, 129))
+
+	assert.Panics(t, func() { m.Bit(3, 0) })
+	assert.Panics(t, func() { m.SetBit(0, 130, 1) })
+}
+
+func TestMatrixArithmetic(t *testing.T) {
+	a := fromRows(
+		"110",
+		"011",
+	)
+	b := fromRows(
+		"10",
+		"11",
+		"01",
+	)
+
+	assert.True(t, a.Mul(b).Equal(fromRows(
+		"01",
+		"10",
+	)))
+	assert.True(t, a.Transpose().Equal(fromRows(
+		"10",
+		"11",
+		"01",
+	)))
+	assert.True(t, a.Add(a).IsZero())
+	assert.True(t, a.Mul(Identity(3)).Equal(a))
+	assert.Panics(t, func() { a.Mul(a) })
+
+	m := randomMatrix(70, 150)
+	n := randomMatrix(150, 90)
+	o := randomMatrix(90, 20)
+	assert.True(t, m.Mul(n).Mul(o).Equal(m.Mul(n.Mul(o))))
+	assert.True(t, m.Mul(n).Transpose().Equal(n.Transpose().Mul(m.Transpose())))
+
+	stacked := m.SubRows(0, 30).AppendRows(m.SubRows(30, 70))
+	assert.True(t, stacked.Equal(m))
+	assert.Equal(t, uint(m.Bit(5, 7)), m.Column(7).Bit(5, 0))
+}
+
+func TestMatrixReducedRowEchelon(t *testing.T) {
+	m := fromRows(
+		"0110",
+		"1011",
+		"1101",
+	)
+
+	reduced, pivots := m.ReducedRowEchelon()
+	assert.True(t, reduced.Equal(fromRows(
+		"1011",
+		"0110",
+		"0000",
+	)))
+	assert.Equal(t, []int{0, 1}, pivots)
+	assert.Equal(t, 2, m.Rank())
+
+	assert.Equal(t, 100, Identity(100).Rank())
+	assert.Equal(t, 0, NewMatrix(5, 5).Rank())
+}
+
+func TestMatrixKernel(t *testing.T) {
+	m := fromRows(
+		"0110",
+		"1011",
+		"1101",
+	)
+
+	kernel := m.Kernel()
+	assert.Equal(t, 2, kernel.Cols())
+	assert.Equal(t, 2, kernel.Rank())
+	assert.True(t, m.Mul(kernel).IsZero())
+
+	assert.Equal(t, 0, Identity(10).Kernel().Cols())
+	assert.True(t, NewMatrix(0, 10).Kernel().Equal(Identity(10)))
+
+	// Rank-nullity theorem
+	m = randomMatrix(100, 200)
+	kernel = m.Kernel()
+	assert.Equal(t, 200, m.Rank()+kernel.Cols())
+	assert.True(t, m.Mul(kernel).IsZero())
+	assert.Equal(t, kernel.Cols(), kernel.Rank())
+}
@@ -2,7 +2,10 @@ module github.com/Lavode/cryptopals
 
 go 1.18
 
-require github.com/stretchr/testify v1.7.1
+require (
+	github.com/stretchr/testify v1.7.1
+	golang.org/x/crypto v0.9.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
@@ -0,0 +1,189 @@
+// Package lattice implements lattice basis reduction on exact rationals.
+package lattice
+
+import (
+	"math/big"
+)
+
+// Vector is a vector of rationals.
+type Vector []*big.Rat
+
+// NewVector returns a zero vector of the given dimension.
+func NewVector(n int) Vector {
+	v := make(Vector, n)
+	for i := range v {
+		v[i] = new(big.Rat)
+	}
+
+	return v
+}
+
+// Copy returns a deep copy of the vector.
+func (v Vector) Copy() Vector {
+	out := make(Vector, len(v))
+	for i := range v {
+		out[i] = new(big.Rat).Set(v[i])
+	}
+
+	return out
+}
+
+// Dot returns the inner product of the two vectors, which must be of the same
+// dimension.
+func (v Vector) Dot(w Vector) *big.Rat {
+	out := new(big.Rat)
+	tmp := new(big.Rat)
+	for i := range v {
+		out.Add(out, tmp.Mul(v[i], w[i]))
+	}
+
+	return out
+}
+
+// SubScaled sets v to v - c * w, and returns it.
+func (v Vector) SubScaled(w Vector, c *big.Rat) Vector {
+	tmp := new(big.Rat)
+	for i := range v {
+		v[i].Sub(v[i], tmp.Mul(c, w[i]))
+	}
+
+	return v
+}
+
+// DefaultDelta is the parameter of the Lovász condition which LLL() is
+// commonly used with.
+var DefaultDelta = big.NewRat(99, 100)
+
+// LLL reduces the basis of a lattice using the Lenstra-Lenstra-Lovász
+// algorithm, and returns the reduced basis. The basis vectors must be
+// linearly independent; they are not modified.
+//
+// The Gram-Schmidt coefficients mu and squared norms B of the orthogonalized
+// basis vectors are kept as exact rationals, and updated incrementally as
+// vectors are reduced and swapped, following Algorithm 2.6.3 of Cohen's "A
+// Course in Computational Algebraic Number Theory". As such no precision is
+// lost, at the cost of speed.
+//
+// Delta is the parameter of the Lovász condition, which must be in (1/4, 1).
+// Values close to 1 yield a better reduced basis, but take longer. If it is
+// nil, DefaultDelta is used.
+func LLL(basis []Vector, delta *big.Rat) []Vector {
+	if delta == nil {
+		delta = DefaultDelta
+	}
+
+	n := len(basis)
+	b := make([]Vector, n)
+	for i := range basis {
+		b[i] = basis[i].Copy()
+	}
+	if n == 0 {
+		return b
+	}
+
+	// mu[i][j] for j < i
+	mu := make([][]*big.Rat, n)
+	for i := range mu {
+		mu[i] = make([]*big.Rat, i)
+	}
+	norms := make([]*big.Rat, n)
+	norms[0] = b[0].Dot(b[0])
+
+	half := big.NewRat(1, 2)
+	tmp := new(big.Rat)
+
+	// reduce size-reduces b_k with respect to b_l, such that
+	// |mu_(k,l)| <= 1/2.
+	reduce := func(k, l int) {
+		if tmp.Abs(mu[k][l]).Cmp(half) <= 0 {
+			return
+		}
+
+		q := new(big.Rat).SetInt(round(mu[k][l]))
+		b[k].SubScaled(b[l], q)
+		mu[k][l].Sub(mu[k][l], q)
+		for i := 0; i < l; i++ {
+			mu[k][i].Sub(mu[k][i], tmp.Mul(q, mu[l][i]))
+		}
+	}
+
+	// swap exchanges b_k and b_(k-1), updating all affected coefficients.
+	swap := func(k, kmax int) {
+		b[k], b[k-1] = b[k-1], b[k]
+		for j := 0; j < k-1; j++ {
+			mu[k][j], mu[k-1][j] = mu[k-1][j], mu[k][j]
+		}
+
+		m := mu[k][k-1]
+
+		// B = B_k + mu^2 * B_(k-1)
+		bb := new(big.Rat).Mul(m, m)
+		bb.Mul(bb, norms[k-1])
+		bb.Add(bb, norms[k])
+
+		mu[k][k-1] = new(big.Rat).Mul(m, norms[k-1])
+		mu[k][k-1].Quo(mu[k][k-1], bb)
+
+		norms[k] = new(big.Rat).Mul(norms[k-1], norms[k])
+		norms[k].Quo(norms[k], bb)
+		norms[k-1] = bb
+
+		for i := k + 1; i <= kmax; i++ {
+			t := mu[i][k]
+			mu[i][k] = new(big.Rat).Sub(mu[i][k-1], tmp.Mul(m, t))
+			mu[i][k-1] = t.Add(t, tmp.Mul(mu[k][k-1], mu[i][k]))
+		}
+	}
+
+	k, kmax := 1, 0
+	for k < n {
+		if k > kmax {
+			// Incremental Gram-Schmidt
+			kmax = k
+			for j := 0; j < k; j++ {
+				m := b[k].Dot(b[j])
+				for i := 0; i < j; i++ {
+					tmp.Mul(mu[j][i], mu[k][i])
+					m.Sub(m, tmp.Mul(tmp, norms[i]))
+				}
+				mu[k][j] = m.Quo(m, norms[j])
+			}
+
+			norms[k] = b[k].Dot(b[k])
+			for j := 0; j < k; j++ {
+				tmp.Mul(mu[k][j], mu[k][j])
+				norms[k].Sub(norms[k], tmp.Mul(tmp, norms[j]))
+			}
+		}
+
+		reduce(k, k-1)
+
+		// Lovász condition: B_k >= (delta - mu_(k,k-1)^2) B_(k-1)
+		bound := new(big.Rat).Mul(mu[k][k-1], mu[k][k-1])
+		bound.Sub(delta, bound)
+		bound.Mul(bound, norms[k-1])
+		if norms[k].Cmp(bound) < 0 {
+			swap(k, kmax)
+			if k > 1 {
+				k--
+			}
+			continue
+		}
+
+		for l := k - 2; l >= 0; l-- {
+			reduce(k, l)
+		}
+		k++
+	}
+
+	return b
+}
+
+// round rounds the rational to the nearest integer, with halves rounded up.
+func round(x *big.Rat) *big.Int {
+	// floor(x + 1/2)
+	y := new(big.Rat).Add(x, big.NewRat(1, 2))
+
+	// Euclidean division rounds down, as the denominator is positive
+	return new(big.Int).Div(y.Num(), y.Denom())
+}
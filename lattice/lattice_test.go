@@ -0,0 +1,98 @@
+package lattice
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intVector(xs ...int64) Vector {
+	v := make(Vector, len(xs))
+	for i, x := range xs {
+		v[i] = new(big.Rat).SetInt64(x)
+	}
+
+	return v
+}
+
+func assertVectorEqual(t *testing.T, expected, actual Vector) {
+	assert.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assert.Equal(t, 0, expected[i].Cmp(actual[i]), "Vectors differ at index %d: %v != %v", i, expected[i], actual[i])
+	}
+}
+
+func TestLLL(t *testing.T) {
+	// Example from Wikipedia
+	basis := []Vector{
+		intVector(1, 1, 1),
+		intVector(-1, 0, 2),
+		intVector(3, 5, 6),
+	}
+
+	reduced := LLL(basis, big.NewRat(3, 4))
+	assertVectorEqual(t, intVector(0, 1, 0), reduced[0])
+	assertVectorEqual(t, intVector(1, 0, 1), reduced[1])
+	assertVectorEqual(t, intVector(-1, 0, 2), reduced[2])
+
+	// Input is left untouched
+	assertVectorEqual(t, intVector(1, 1, 1), basis[0])
+}
+
+func TestLLLRational(t *testing.T) {
+	// Scaling the lattice scales the reduced basis
+	basis := []Vector{
+		intVector(1, 1, 1),
+		intVector(-1, 0, 2),
+		intVector(3, 5, 6),
+	}
+	scaled := make([]Vector, len(basis))
+	for i := range basis {
+		scaled[i] = basis[i].Copy()
+		for j := range scaled[i] {
+			scaled[i][j].Mul(scaled[i][j], big.NewRat(1, 3))
+		}
+	}
+
+	reduced := LLL(basis, nil)
+	reducedScaled := LLL(scaled, nil)
+	for i := range reduced {
+		expected := NewVector(len(reduced[i])).SubScaled(reduced[i], big.NewRat(-1, 3))
+		assertVectorEqual(t, expected, reducedScaled[i])
+	}
+}
+
+func TestLLLShortVector(t *testing.T) {
+	// The lattice spanned by (1, a) and (0, m) contains the short vector
+	// (x, a*x mod m) for small x.
+	m := int64(1000003)
+	a := int64(123457)
+
+	basis := []Vector{
+		intVector(1, a),
+		intVector(0, m),
+	}
+
+	reduced := LLL(basis, nil)
+	norm := reduced[0].Dot(reduced[0])
+	assert.Equal(t, -1, norm.Cmp(new(big.Rat).SetInt64(2*m)))
+
+	// Must still be a lattice vector: second coordinate equals a times
+	// the first modulo m.
+	x := reduced[0][0].Num().Int64()
+	y := reduced[0][1].Num().Int64()
+	assert.Equal(t, int64(0), (((a*x-y)%m)+m)%m)
+}
+
+func TestVector(t *testing.T) {
+	v := intVector(1, 2, 3)
+	w := intVector(4, 5, 6)
+
+	assert.Equal(t, 0, v.Dot(w).Cmp(big.NewRat(32, 1)))
+
+	v.SubScaled(w, big.NewRat(1, 2))
+	assertVectorEqual(t, Vector{big.NewRat(-1, 1), big.NewRat(-1, 2), big.NewRat(0, 1)}, v)
+
+	assert.Equal(t, 3, len(NewVector(3)))
+}
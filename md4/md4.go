@@ -0,0 +1,147 @@
+package md4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// BlockSize specifies the length of a message block in bytes.
+const BlockSize = 64
+
+// Size specifies the length of a digest in bytes.
+const Size = 16
+
+// IV is the initial state of MD4, consisting of the four words A, B, C and
+// D.
+var IV = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+// Round constants of the second and third round.
+const (
+	K2 = 0x5a827999
+	K3 = 0x6ed9eba1
+)
+
+// Shifts are the rotation amounts of the four steps of each round, which are
+// repeated four times per round.
+var Shifts = [3][4]int{
+	{3, 7, 11, 19},
+	{3, 5, 9, 13},
+	{3, 9, 11, 15},
+}
+
+// order2 and order3 specify the order in which message words are processed in
+// the second and third round.
+var (
+	order2 = [16]int{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+	order3 = [16]int{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+)
+
+// F is the boolean function of the first round, selecting bits of y or z
+// depending on x.
+func F(x, y, z uint32) uint32 {
+	return (x & y) | (^x & z)
+}
+
+// G is the boolean function of the second round, the majority of x, y and z.
+func G(x, y, z uint32) uint32 {
+	return (x & y) | (x & z) | (y & z)
+}
+
+// H is the boolean function of the third round.
+func H(x, y, z uint32) uint32 {
+	return x ^ y ^ z
+}
+
+// Sum calculates the MD4 digest of the message, as specified in RFC 1320.
+func Sum(msg []byte) []byte {
+	padded := append(append([]byte{}, msg...), Padding(len(msg))...)
+
+	state := IV
+	for i := 0; i < len(padded); i += BlockSize {
+		state = Compress(state, padded[i:i+BlockSize])
+	}
+
+	digest := make([]byte, Size)
+	for i, word := range state {
+		binary.LittleEndian.PutUint32(digest[4*i:], word)
+	}
+
+	return digest
+}
+
+// Padding returns the padding which is appended to a message of the given
+// length in bytes. It consists of a single 1 bit, as many 0 bits as required,
+// and the message length in bits as a 64-bit little-endian integer.
+func Padding(length int) []byte {
+	padLength := (BlockSize - (length+1+8)%BlockSize) % BlockSize
+
+	padding := make([]byte, 1+padLength+8)
+	padding[0] = 0x80
+	binary.LittleEndian.PutUint64(padding[1+padLength:], uint64(length)*8)
+
+	return padding
+}
+
+// Words decodes a message block into its sixteen little-endian words.
+//
+// It panics if the block is not exactly BlockSize bytes long.
+func Words(block []byte) [16]uint32 {
+	if len(block) != BlockSize {
+		panic(fmt.Sprintf("Block must be of length %d, but was %d", BlockSize, len(block)))
+	}
+
+	var words [16]uint32
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+
+	return words
+}
+
+// Block encodes sixteen words into a message block.
+func Block(words [16]uint32) []byte {
+	block := make([]byte, BlockSize)
+	for i, word := range words {
+		binary.LittleEndian.PutUint32(block[4*i:], word)
+	}
+
+	return block
+}
+
+// Compress applies the MD4 compression function to the state and a single
+// message block.
+//
+// It panics if the block is not exactly BlockSize bytes long.
+func Compress(state [4]uint32, block []byte) [4]uint32 {
+	return CompressWords(state, Words(block))
+}
+
+// CompressWords applies the MD4 compression function to the state and a
+// single message block, given as its sixteen words.
+func CompressWords(state [4]uint32, m [16]uint32) [4]uint32 {
+	a, b, c, d := state[0], state[1], state[2], state[3]
+
+	for i := 0; i < 16; i += 4 {
+		a = bits.RotateLeft32(a+F(b, c, d)+m[i], Shifts[0][0])
+		d = bits.RotateLeft32(d+F(a, b, c)+m[i+1], Shifts[0][1])
+		c = bits.RotateLeft32(c+F(d, a, b)+m[i+2], Shifts[0][2])
+		b = bits.RotateLeft32(b+F(c, d, a)+m[i+3], Shifts[0][3])
+	}
+
+	for i := 0; i < 16; i += 4 {
+		a = bits.RotateLeft32(a+G(b, c, d)+m[order2[i]]+K2, Shifts[1][0])
+		d = bits.RotateLeft32(d+G(a, b, c)+m[order2[i+1]]+K2, Shifts[1][1])
+		c = bits.RotateLeft32(c+G(d, a, b)+m[order2[i+2]]+K2, Shifts[1][2])
+		b = bits.RotateLeft32(b+G(c, d, a)+m[order2[i+3]]+K2, Shifts[1][3])
+	}
+
+	for i := 0; i < 16; i += 4 {
+		a = bits.RotateLeft32(a+H(b, c, d)+m[order3[i]]+K3, Shifts[2][0])
+		d = bits.RotateLeft32(d+H(a, b, c)+m[order3[i+1]]+K3, Shifts[2][1])
+		c = bits.RotateLeft32(c+H(d, a, b)+m[order3[i+2]]+K3, Shifts[2][2])
+		b = bits.RotateLeft32(b+H(c, d, a)+m[order3[i+3]]+K3, Shifts[2][3])
+	}
+
+	return [4]uint32{state[0] + a, state[1] + b, state[2] + c, state[3] + d}
+}
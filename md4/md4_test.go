@@ -0,0 +1,52 @@
+package md4
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum(t *testing.T) {
+	// Test vectors from RFC 1320
+	vectors := []struct {
+		msg    string
+		digest string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+
+	for _, vector := range vectors {
+		assert.Equal(t, vector.digest, hex.EncodeToString(Sum([]byte(vector.msg))), "Digest of %q", vector.msg)
+	}
+}
+
+func TestPadding(t *testing.T) {
+	for length := 0; length < 200; length++ {
+		padding := Padding(length)
+
+		assert.Equal(t, 0, (length+len(padding))%BlockSize)
+		assert.Equal(t, byte(0x80), padding[0])
+	}
+
+	padding := Padding(3)
+	assert.Equal(t, 61, len(padding))
+	assert.Equal(t, []byte{24, 0, 0, 0, 0, 0, 0, 0}, padding[53:])
+}
+
+func TestWordsBlock(t *testing.T) {
+	block := make([]byte, BlockSize)
+	for i := range block {
+		block[i] = byte(i)
+	}
+
+	words := Words(block)
+	assert.Equal(t, uint32(0x03020100), words[0])
+	assert.Equal(t, block, Block(words))
+}
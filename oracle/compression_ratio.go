@@ -0,0 +1,118 @@
+package oracle
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/padding"
+)
+
+// CompressionCipher specifies which cipher is used to encrypt the compressed
+// requests.
+type CompressionCipher int
+
+const (
+	// StreamCipher refers to AES-128 in CTR mode, where the ciphertext is
+	// exactly as long as the compressed request.
+	StreamCipher CompressionCipher = iota
+	// BlockCipher refers to AES-128 in CBC mode with PKCS#7 padding, where
+	// the ciphertext length is a multiple of the block size.
+	BlockCipher
+)
+
+// CompressionRatio provides an oracle which leaks the length of compressed
+// and then encrypted requests, each of which contains a secret session ID as
+// well as an attacker-controlled body.
+//
+// Every request is encrypted under a fresh random key (and nonce or IV), so
+// only its length is of any use to the attacker.
+type CompressionRatio struct {
+	SessionID []byte
+	Cipher    CompressionCipher
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+}
+
+// Length formats, compresses and encrypts a request with the given body, and
+// returns the length of the resulting ciphertext.
+//
+// The request is of the form:
+//
+//	POST / HTTP/1.1
+//	Host: hapless.com
+//	Cookie: sessionid=#{session_id}
+//	Content-Length: #{len(body)}
+//	#{body}
+func (or *CompressionRatio) Length(body []byte) (int, error) {
+	request := []byte(fmt.Sprintf(
+		"POST / HTTP/1.1\nHost: hapless.com\nCookie: sessionid=%s\nContent-Length: %d\n%s",
+		or.SessionID,
+		len(body),
+		body,
+	))
+
+	compressed, err := compress(request)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := cipher.NewKeyFrom(or.Rand)
+	if err != nil {
+		return 0, err
+	}
+
+	var ctxt []byte
+	switch or.Cipher {
+	case StreamCipher:
+		nonce, err := cipher.NewKeyFrom(or.Rand)
+		if err != nil {
+			return 0, err
+		}
+
+		ctr := cipher.AESCTR{Key: key, Nonce: nonce[:cipher.AESCTRNonceSize]}
+		ctxt, err = ctr.Encrypt(compressed)
+		if err != nil {
+			return 0, err
+		}
+	case BlockCipher:
+		iv, err := cipher.NewIV(or.Rand)
+		if err != nil {
+			return 0, err
+		}
+
+		cbc := cipher.AESCBC{Key: key, IV: iv}
+		ctxt, err = cbc.Encrypt(padding.PKCS7Pad(compressed, cipher.AESBlockSize))
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("Invalid cipher: %d", or.Cipher)
+	}
+
+	return len(ctxt), nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error initializing compression: %v", err)
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error compressing data: %v", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error compressing data: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
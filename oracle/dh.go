@@ -0,0 +1,78 @@
+package oracle
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/dh"
+)
+
+// DHResponderMessage is the message which DHResponder authenticates, unless
+// configured otherwise.
+var DHResponderMessage = []byte("crazy flamboyant for the rap enjoyment")
+
+// DHResponder provides an oracle simulating a party of a Diffie-Hellman key
+// exchange, which derives the shared secret with the peer's public key and
+// then sends a message authenticated with it.
+//
+// Unless ValidatePeerKey is set, the peer's public key is used as is, without
+// checking whether it is in the subgroup of order Q. This allows an attacker
+// to learn the private key modulo the order of any small subgroup.
+type DHResponder struct {
+	Parameters dh.Parameters
+	// Message is the message which is sent. Defaults to
+	// DHResponderMessage if left unset.
+	Message []byte
+	// ValidatePeerKey enables validation of the peer's public key.
+	ValidatePeerKey bool
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *dh.PrivateKey
+}
+
+// PublicKey returns the responder's public key.
+func (or *DHResponder) PublicKey() (dh.PublicKey, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return dh.PublicKey{}, err
+	}
+
+	return key.PublicKey, nil
+}
+
+// Respond derives the shared secret with the peer's public key, and returns
+// the message and its MAC under the shared secret, as calculated by dh.MAC().
+func (or *DHResponder) Respond(peer *big.Int) ([]byte, []byte, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	if or.ValidatePeerKey {
+		if err := or.Parameters.ValidateElement(peer); err != nil {
+			return []byte{}, []byte{}, fmt.Errorf("Invalid peer key: %v", err)
+		}
+	}
+
+	msg := or.Message
+	if msg == nil {
+		msg = DHResponderMessage
+	}
+
+	return msg, dh.MAC(key.SharedSecret(peer), msg), nil
+}
+
+func (or *DHResponder) privateKey() (*dh.PrivateKey, error) {
+	if or.key == nil {
+		key, err := dh.GenerateKeyFrom(or.Rand, or.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = &key
+	}
+
+	return or.key, nil
+}
@@ -0,0 +1,51 @@
+package oracle
+
+import (
+	"crypto"
+
+	"github.com/Lavode/cryptopals/dsa"
+)
+
+// DSAVerifier provides an oracle which verifies DSA signatures under domain
+// parameters supplied by the caller, as a service might do when it lets
+// clients bring their own keys.
+//
+// Unless ValidateParameters is set, it neither checks the domain parameters
+// nor the range of the signature's components, which allows forging
+// signatures by tampering with the generator.
+type DSAVerifier struct {
+	// Hash is the hash function used to digest messages. Defaults to
+	// SHA-1 if left unset.
+	Hash crypto.Hash
+	// ValidateParameters enables validation of domain parameters and
+	// signatures as done by a careful verifier.
+	ValidateParameters bool
+}
+
+// Verify checks whether sig is a valid signature of msg under the public key,
+// which includes the domain parameters.
+func (or *DSAVerifier) Verify(pub dsa.PublicKey, msg []byte, sig dsa.Signature) bool {
+	hash := or.Hash
+	if hash == 0 {
+		hash = crypto.SHA1
+	}
+
+	if or.ValidateParameters {
+		if err := pub.Parameters.Validate(); err != nil {
+			return false
+		}
+
+		return pub.Verify(hash, msg, sig)
+	}
+
+	if sig.R == nil || sig.S == nil {
+		return false
+	}
+
+	h, err := dsa.Digest(hash, msg, pub.Q)
+	if err != nil {
+		return false
+	}
+
+	return sig.R.Cmp(pub.V(h, sig)) == 0
+}
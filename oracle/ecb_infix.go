@@ -1,11 +1,12 @@
 package oracle
 
 import (
-	"crypto/rand"
 	"fmt"
+	"io"
 
 	"github.com/Lavode/cryptopals/cipher"
 	"github.com/Lavode/cryptopals/padding"
+	"github.com/Lavode/cryptopals/randutil"
 )
 
 // ECBInfix provides an oracle allowing for a chosen-message attack on AES-128
@@ -17,7 +18,12 @@ type ECBInfix struct {
 	key          *[]byte
 	Postfix      []byte
 	PrefixLength int
-	prefix       *[]byte
+	// Queries counts the calls to Encrypt().
+	Queries int
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand   io.Reader
+	prefix *[]byte
 }
 
 // Encrypt encrypts a message where the user-supplied message is used as an
@@ -35,8 +41,10 @@ type ECBInfix struct {
 // The AES key is chosen randomly on the first oracle call, and reused
 // subsequently.
 func (or *ECBInfix) Encrypt(msg []byte) (ctxt cipher.AESCiphertext, err error) {
+	or.Queries++
+
 	if or.key == nil {
-		key, err := cipher.NewKey()
+		key, err := cipher.NewKeyFrom(or.Rand)
 		if err != nil {
 			return ctxt, err
 		}
@@ -46,7 +54,7 @@ func (or *ECBInfix) Encrypt(msg []byte) (ctxt cipher.AESCiphertext, err error) {
 
 	if or.prefix == nil {
 		prefix := make([]byte, or.PrefixLength)
-		_, err = rand.Read(prefix)
+		_, err = io.ReadFull(randutil.Reader(or.Rand), prefix)
 		if err != nil {
 			return ctxt, fmt.Errorf("Error generating random prefix: %v", err)
 		}
@@ -2,15 +2,19 @@ package oracle
 
 import (
 	"fmt"
-	"math/rand"
+	"io"
 
 	"github.com/Lavode/cryptopals/cipher"
 	"github.com/Lavode/cryptopals/padding"
+	"github.com/Lavode/cryptopals/randutil"
 )
 
 // ECBOrCBC provides an oracle which encrypts a given message in either ECB or
 // CBC mode.
 type ECBOrCBC struct {
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
 }
 
 // Encrypt encrypts the given message in either ECB or CBC mode with a random
@@ -24,26 +28,35 @@ type ECBOrCBC struct {
 //
 // Every invocation has a chance of 50% of using either ECB or CBC.
 func (or *ECBOrCBC) Encrypt(msg []byte) (ctxt cipher.AESCiphertext, err error, wasECB bool) {
-	key, err := cipher.NewKey()
+	rnd := randutil.Reader(or.Rand)
+
+	key, err := cipher.NewKeyFrom(rnd)
 	if err != nil {
 		return ctxt, err, wasECB
 	}
 
 	// [0, 6) => [5, 11)
-	prefixBytes := rand.Intn(6) + 5
-	postfixBytes := rand.Intn(6) + 5
+	prefixBytes, err := randutil.Intn(rnd, 6)
+	if err != nil {
+		return ctxt, err, wasECB
+	}
+	prefixBytes += 5
+	postfixBytes, err := randutil.Intn(rnd, 6)
+	if err != nil {
+		return ctxt, err, wasECB
+	}
+	postfixBytes += 5
 
 	prefixedMsg := make([]byte, len(msg)+prefixBytes+postfixBytes)
+	copy(prefixedMsg[prefixBytes:], msg)
 
-	// This is not a CSPRNG, but good enough for the purpose of this
-	// exercise.
 	// Fill with random prefix
-	_, err = rand.Read(prefixedMsg[:prefixBytes])
+	_, err = io.ReadFull(rnd, prefixedMsg[:prefixBytes])
 	if err != nil {
 		return ctxt, fmt.Errorf("Error padding message with random bytes: %v", err), wasECB
 	}
 	// Fill with random postfix
-	_, err = rand.Read(prefixedMsg[prefixBytes+len(msg):])
+	_, err = io.ReadFull(rnd, prefixedMsg[prefixBytes+len(msg):])
 	if err != nil {
 		return ctxt, fmt.Errorf("Error padding message with random bytes: %v", err), wasECB
 	}
@@ -51,7 +64,11 @@ func (or *ECBOrCBC) Encrypt(msg []byte) (ctxt cipher.AESCiphertext, err error, w
 	// Fill to next multiple of AES block size
 	paddedMsg := padding.PKCS7Pad(prefixedMsg, cipher.AESBlockSize)
 
-	wasECB = rand.Intn(2) == 0
+	mode, err := randutil.Intn(rnd, 2)
+	if err != nil {
+		return ctxt, err, wasECB
+	}
+	wasECB = mode == 0
 	if wasECB {
 		ecb := cipher.AESECB{Key: key}
 
@@ -61,7 +78,7 @@ func (or *ECBOrCBC) Encrypt(msg []byte) (ctxt cipher.AESCiphertext, err error, w
 		}
 		ctxt.Bytes = rawCtxt
 	} else {
-		iv, err := cipher.NewKey()
+		iv, err := cipher.NewIV(rnd)
 		if err != nil {
 			return ctxt, err, wasECB
 		}
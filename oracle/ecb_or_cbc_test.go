@@ -0,0 +1,45 @@
+package oracle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECBOrCBCSeeded(t *testing.T) {
+	msg := make([]byte, 64)
+
+	or1 := ECBOrCBC{Rand: randutil.NewSeeded(42)}
+	or2 := ECBOrCBC{Rand: randutil.NewSeeded(42)}
+
+	modes := make(map[bool]bool)
+	for i := 0; i < 20; i++ {
+		ctxt1, err, wasECB1 := or1.Encrypt(msg)
+		assert.Nil(t, err)
+		ctxt2, err, wasECB2 := or2.Encrypt(msg)
+		assert.Nil(t, err)
+
+		assert.Equal(t, ctxt1.Bytes, ctxt2.Bytes)
+		assert.Equal(t, wasECB1, wasECB2)
+		modes[wasECB1] = true
+	}
+
+	// Both modes are used
+	assert.Len(t, modes, 2)
+}
+
+func TestECBOrCBCEncryptsMessage(t *testing.T) {
+	// With the same randomness, the ciphertexts only differ if the
+	// message is part of the plaintext.
+	or1 := ECBOrCBC{Rand: randutil.NewSeeded(42)}
+	or2 := ECBOrCBC{Rand: randutil.NewSeeded(42)}
+
+	ctxt1, err, _ := or1.Encrypt(make([]byte, 32))
+	assert.Nil(t, err)
+	ctxt2, err, _ := or2.Encrypt(bytes.Repeat([]byte{0x42}, 32))
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, ctxt1.Bytes, ctxt2.Bytes)
+}
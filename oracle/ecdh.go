@@ -0,0 +1,143 @@
+package oracle
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/ec"
+)
+
+// ECDHResponder provides an oracle simulating a party of an elliptic curve
+// Diffie-Hellman key exchange, which derives the shared secret with the
+// peer's public key and then sends a message authenticated with it.
+//
+// Unless ValidatePeerKey is set, the peer's public key is used as is, without
+// checking whether it is on the curve. As the formulas for point addition do
+// not depend on the curve's coefficient B, the responder then happily
+// computes on any curve which only differs from its own in B.
+type ECDHResponder struct {
+	Curve ec.Curve
+	// Message is the message which is sent. Defaults to
+	// DHResponderMessage if left unset.
+	Message []byte
+	// ValidatePeerKey enables validation of the peer's public key.
+	ValidatePeerKey bool
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *ec.PrivateKey
+}
+
+// PublicKey returns the responder's public key.
+func (or *ECDHResponder) PublicKey() (ec.PublicKey, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return ec.PublicKey{}, err
+	}
+
+	return key.PublicKey, nil
+}
+
+// Respond derives the shared secret with the peer's public key, and returns
+// the message and its MAC, as calculated by ec.MAC() keyed with the encoding
+// of the shared secret.
+func (or *ECDHResponder) Respond(peer ec.Point) ([]byte, []byte, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	if or.ValidatePeerKey {
+		if err := or.Curve.Validate(peer); err != nil {
+			return []byte{}, []byte{}, fmt.Errorf("Invalid peer key: %v", err)
+		}
+	}
+
+	msg := or.Message
+	if msg == nil {
+		msg = DHResponderMessage
+	}
+
+	secret := key.SharedSecret(peer)
+	return msg, ec.MAC(or.Curve.Marshal(secret), msg), nil
+}
+
+func (or *ECDHResponder) privateKey() (*ec.PrivateKey, error) {
+	if or.key == nil {
+		key, err := ec.GenerateKeyFrom(or.Rand, or.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = &key
+	}
+
+	return or.key, nil
+}
+
+// MontgomeryECDHResponder provides an oracle simulating a party of an
+// elliptic curve Diffie-Hellman key exchange on a Montgomery curve, which
+// only ever uses the u-coordinate of its peer's public key, and derives the
+// shared secret with the Montgomery ladder. It then sends a message
+// authenticated with it.
+//
+// Unless ValidatePeerKey is set, the peer's u-coordinate is used as is,
+// without checking whether it is on the curve rather than its twist.
+type MontgomeryECDHResponder struct {
+	Curve ec.MontgomeryCurve
+	// Message is the message which is sent. Defaults to
+	// DHResponderMessage if left unset.
+	Message []byte
+	// ValidatePeerKey enables validation of the peer's public key.
+	ValidatePeerKey bool
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *ec.MontgomeryPrivateKey
+}
+
+// PublicKey returns the responder's public key.
+func (or *MontgomeryECDHResponder) PublicKey() (ec.MontgomeryPublicKey, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return ec.MontgomeryPublicKey{}, err
+	}
+
+	return key.MontgomeryPublicKey, nil
+}
+
+// Respond derives the shared secret with the u-coordinate of the peer's
+// public key, and returns the message and its MAC, as calculated by ec.MAC()
+// keyed with the encoding of the shared secret's u-coordinate.
+func (or *MontgomeryECDHResponder) Respond(peer *big.Int) ([]byte, []byte, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	if or.ValidatePeerKey && !or.Curve.IsOnCurve(peer) {
+		return []byte{}, []byte{}, fmt.Errorf("Invalid peer key: Not on curve")
+	}
+
+	msg := or.Message
+	if msg == nil {
+		msg = DHResponderMessage
+	}
+
+	secret := key.SharedSecret(peer)
+	return msg, ec.MAC(or.Curve.Marshal(secret), msg), nil
+}
+
+func (or *MontgomeryECDHResponder) privateKey() (*ec.MontgomeryPrivateKey, error) {
+	if or.key == nil {
+		key, err := ec.GenerateMontgomeryKeyFrom(or.Rand, or.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = &key
+	}
+
+	return or.key, nil
+}
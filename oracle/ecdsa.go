@@ -0,0 +1,81 @@
+package oracle
+
+import (
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/ec"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// ECDSABiasedSigner provides an oracle which signs messages with ECDSA, using
+// nonces whose lowest Bits bits are zero, as might happen with a buggy
+// implementation which fills the nonce's last byte with garbage which turns
+// out to be zero.
+//
+// The key pair is generated on first use.
+type ECDSABiasedSigner struct {
+	Curve ec.Curve
+	// Hash is the hash function used to digest messages. Defaults to
+	// SHA-256 if left unset.
+	Hash crypto.Hash
+	// Bits is the number of low bits of the nonce which are zero.
+	Bits uint
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *ec.PrivateKey
+}
+
+// PublicKey returns the signer's public key.
+func (or *ECDSABiasedSigner) PublicKey() (ec.PublicKey, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return ec.PublicKey{}, err
+	}
+
+	return key.PublicKey, nil
+}
+
+// Sign signs the message with a nonce whose lowest Bits bits are zero.
+func (or *ECDSABiasedSigner) Sign(msg []byte) (ec.Signature, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return ec.Signature{}, err
+	}
+
+	hash := or.Hash
+	if hash == 0 {
+		hash = crypto.SHA256
+	}
+
+	for {
+		k, err := rand.Int(randutil.Reader(or.Rand), or.Curve.N)
+		if err != nil {
+			return ec.Signature{}, fmt.Errorf("Error generating nonce: %v", err)
+		}
+
+		k.Rsh(k, or.Bits)
+		k.Lsh(k, or.Bits)
+		if k.Sign() == 0 {
+			continue
+		}
+
+		return key.SignWithNonce(hash, msg, k)
+	}
+}
+
+func (or *ECDSABiasedSigner) privateKey() (*ec.PrivateKey, error) {
+	if or.key == nil {
+		key, err := ec.GenerateKeyFrom(or.Rand, or.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = &key
+	}
+
+	return or.key, nil
+}
@@ -0,0 +1,61 @@
+package oracle
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// GCMFixedNonce provides an oracle which encrypts and authenticates messages
+// with AES-GCM, reusing the same nonce for every message, as might happen
+// with an implementation whose nonce counter is reset on every restart.
+//
+// Key and nonce are generated on first use.
+type GCMFixedNonce struct {
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	gcm  *cipher.AESGCM
+}
+
+// Encrypt encrypts the message and authenticates it along with the
+// additional data, returning the ciphertext and tag.
+func (or *GCMFixedNonce) Encrypt(msg []byte, aad []byte) ([]byte, []byte, error) {
+	gcm, err := or.cipher()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	return gcm.Encrypt(msg, aad)
+}
+
+// Decrypt verifies the tag of the ciphertext and additional data, and
+// decrypts the ciphertext. An error is returned if the tag is invalid.
+func (or *GCMFixedNonce) Decrypt(ctxt []byte, aad []byte, tag []byte) ([]byte, error) {
+	gcm, err := or.cipher()
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return gcm.Decrypt(ctxt, aad, tag)
+}
+
+func (or *GCMFixedNonce) cipher() (*cipher.AESGCM, error) {
+	if or.gcm == nil {
+		key, err := cipher.NewKeyFrom(or.Rand)
+		if err != nil {
+			return nil, err
+		}
+
+		nonce := make([]byte, cipher.AESGCMNonceSize)
+		if _, err := io.ReadFull(randutil.Reader(or.Rand), nonce); err != nil {
+			return nil, fmt.Errorf("Error generating nonce: %v", err)
+		}
+
+		or.gcm = &cipher.AESGCM{Key: key, Nonce: nonce}
+	}
+
+	return or.gcm, nil
+}
@@ -0,0 +1,73 @@
+package oracle
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// GCMTruncatedTag provides an oracle which encrypts messages with AES-GCM
+// under a fresh random nonce each, using tags truncated to TagSize bytes,
+// and verifies ciphertexts submitted to it.
+//
+// The key is generated on first use.
+type GCMTruncatedTag struct {
+	// TagSize is the length of the tags in bytes.
+	TagSize int
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  []byte
+}
+
+// Encrypt encrypts the message and authenticates it along with the
+// additional data under a fresh random nonce. It returns the nonce,
+// ciphertext and truncated tag.
+func (or *GCMTruncatedTag) Encrypt(msg []byte, aad []byte) ([]byte, []byte, []byte, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return []byte{}, []byte{}, []byte{}, err
+	}
+
+	nonce := make([]byte, cipher.AESGCMNonceSize)
+	if _, err := io.ReadFull(randutil.Reader(or.Rand), nonce); err != nil {
+		return []byte{}, []byte{}, []byte{}, fmt.Errorf("Error generating nonce: %v", err)
+	}
+
+	gcm := cipher.AESGCM{Key: key, Nonce: nonce, TagSize: or.TagSize}
+	ctxt, tag, err := gcm.Encrypt(msg, aad)
+	if err != nil {
+		return []byte{}, []byte{}, []byte{}, err
+	}
+
+	return nonce, ctxt, tag, nil
+}
+
+// Verify returns whether the tag of the ciphertext and additional data is
+// valid under the given nonce.
+func (or *GCMTruncatedTag) Verify(nonce []byte, ctxt []byte, aad []byte, tag []byte) (bool, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return false, err
+	}
+
+	gcm := cipher.AESGCM{Key: key, Nonce: nonce, TagSize: or.TagSize}
+	_, err = gcm.Decrypt(ctxt, aad, tag)
+
+	return err == nil, nil
+}
+
+func (or *GCMTruncatedTag) privateKey() ([]byte, error) {
+	if or.key == nil {
+		key, err := cipher.NewKeyFrom(or.Rand)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = key
+	}
+
+	return or.key, nil
+}
@@ -1,6 +1,11 @@
 package oracle
 
-import "github.com/Lavode/cryptopals/cipher"
+import (
+	"math/big"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/ec"
+)
 
 type EncryptionOracle interface {
 	Encrypt(msg []byte) (ctxt cipher.AESCiphertext, err error)
@@ -9,3 +14,39 @@ type EncryptionOracle interface {
 type DecryptionOracle interface {
 	Decrypt(msg []byte) (ctxt []byte, err error)
 }
+
+type ParityOracle interface {
+	IsEven(ctxt *big.Int) (bool, error)
+}
+
+type PKCS1v15Oracle interface {
+	IsConforming(ctxt *big.Int) (bool, error)
+}
+
+type LengthOracle interface {
+	Length(body []byte) (int, error)
+}
+
+type StreamEncryptionOracle interface {
+	Encrypt(msg []byte) ([]byte, error)
+}
+
+type DHOracle interface {
+	Respond(peer *big.Int) (msg []byte, mac []byte, err error)
+}
+
+type ECDHOracle interface {
+	Respond(peer ec.Point) (msg []byte, mac []byte, err error)
+}
+
+type MontgomeryECDHOracle interface {
+	Respond(peer *big.Int) (msg []byte, mac []byte, err error)
+}
+
+type AEADDecryptionOracle interface {
+	Decrypt(ctxt []byte, aad []byte, tag []byte) (msg []byte, err error)
+}
+
+type GCMVerificationOracle interface {
+	Verify(nonce []byte, ctxt []byte, aad []byte, tag []byte) (bool, error)
+}
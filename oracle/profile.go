@@ -2,6 +2,7 @@ package oracle
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/Lavode/cryptopals/cipher"
 	"github.com/Lavode/cryptopals/padding"
@@ -12,14 +13,17 @@ import (
 // user-chosen e-mail address, and decrypt user-supplied ciphertexts to produce
 // such oracles again.
 type Profile struct {
-	key *[]byte
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *[]byte
 }
 
 func (or *Profile) Encrypt(email string) (cipher.AESCiphertext, error) {
 	ctxt := cipher.AESCiphertext{}
 
 	if or.key == nil {
-		key, err := cipher.NewKey()
+		key, err := cipher.NewKeyFrom(or.Rand)
 		if err != nil {
 			return ctxt, err
 		}
@@ -44,7 +48,7 @@ func (or *Profile) Encrypt(email string) (cipher.AESCiphertext, error) {
 
 func (or *Profile) Decrypt(ctxt []byte) (profile.Profile, error) {
 	if or.key == nil {
-		key, err := cipher.NewKey()
+		key, err := cipher.NewKeyFrom(or.Rand)
 		if err != nil {
 			return profile.Profile{}, err
 		}
@@ -0,0 +1,33 @@
+package oracle
+
+import (
+	"io"
+
+	"github.com/Lavode/cryptopals/cipher"
+)
+
+// RC4Cookie provides an oracle which encrypts an attacker-controlled request,
+// followed by a secret cookie, with RC4.
+//
+// Every request is encrypted under a fresh random key, as would e.g. happen
+// if a victim's browser was made to send many requests over fresh TLS
+// connections. It is safe for concurrent use, as long as Rand is.
+type RC4Cookie struct {
+	Cookie []byte
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+}
+
+// Encrypt encrypts request || cookie under a fresh random key.
+func (or *RC4Cookie) Encrypt(request []byte) ([]byte, error) {
+	key, err := cipher.NewKeyFrom(or.Rand)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	msg := append(append([]byte{}, request...), or.Cookie...)
+	rc4 := cipher.RC4{Key: key}
+
+	return rc4.Encrypt(msg)
+}
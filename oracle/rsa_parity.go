@@ -0,0 +1,59 @@
+package oracle
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/rsa"
+)
+
+// RSAParity provides an oracle which decrypts RSA ciphertexts, but only
+// reveals whether the resulting plaintext is even or odd.
+//
+// The key pair is generated on first use, with a modulus of the given number
+// of bits.
+type RSAParity struct {
+	Bits int
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *rsa.PrivateKey
+}
+
+// PublicKey returns the oracle's public key.
+func (or *RSAParity) PublicKey() (rsa.PublicKey, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return rsa.PublicKey{}, err
+	}
+
+	return key.PublicKey, nil
+}
+
+// IsEven decrypts the ciphertext and returns whether the plaintext is even.
+func (or *RSAParity) IsEven(ctxt *big.Int) (bool, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return false, err
+	}
+
+	msg, err := key.Decrypt(ctxt)
+	if err != nil {
+		return false, err
+	}
+
+	return msg.Bit(0) == 0, nil
+}
+
+func (or *RSAParity) privateKey() (*rsa.PrivateKey, error) {
+	if or.key == nil {
+		key, err := rsa.GenerateKeyFrom(or.Rand, or.Bits, rsa.DefaultExponent)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = &key
+	}
+
+	return or.key, nil
+}
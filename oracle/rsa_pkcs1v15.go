@@ -0,0 +1,84 @@
+package oracle
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/padding"
+	"github.com/Lavode/cryptopals/rsa"
+)
+
+// RSAPKCS1v15 provides an oracle which decrypts RSA ciphertexts, but only
+// reveals whether the resulting plaintext starts with the 00 02 header of
+// PKCS#1 v1.5 encryption padding.
+//
+// The key pair is generated on first use, with a modulus of the given number
+// of bits.
+type RSAPKCS1v15 struct {
+	Bits int
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *rsa.PrivateKey
+}
+
+// PublicKey returns the oracle's public key.
+func (or *RSAPKCS1v15) PublicKey() (rsa.PublicKey, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return rsa.PublicKey{}, err
+	}
+
+	return key.PublicKey, nil
+}
+
+// Encrypt pads the message with PKCS#1 v1.5 encryption padding and encrypts
+// it with the oracle's public key.
+func (or *RSAPKCS1v15) Encrypt(msg []byte) (*big.Int, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	padded, err := padding.PKCS1v15PadFrom(or.Rand, msg, key.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	return key.Encrypt(new(big.Int).SetBytes(padded))
+}
+
+// IsConforming decrypts the ciphertext and returns whether the plaintext
+// starts with 00 02.
+func (or *RSAPKCS1v15) IsConforming(ctxt *big.Int) (bool, error) {
+	key, err := or.privateKey()
+	if err != nil {
+		return false, err
+	}
+
+	msg, err := key.Decrypt(ctxt)
+	if err != nil {
+		return false, err
+	}
+
+	// With B = 2^(8(k-2)), a k-byte plaintext starts with 00 02 exactly
+	// if it is in [2B, 3B).
+	b := new(big.Int).Lsh(big.NewInt(1), uint(8*(key.Size()-2)))
+	lower := new(big.Int).Lsh(b, 1)
+	upper := new(big.Int).Add(lower, b)
+
+	return msg.Cmp(lower) >= 0 && msg.Cmp(upper) < 0, nil
+}
+
+func (or *RSAPKCS1v15) privateKey() (*rsa.PrivateKey, error) {
+	if or.key == nil {
+		key, err := rsa.GenerateKeyFrom(or.Rand, or.Bits, rsa.DefaultExponent)
+		if err != nil {
+			return nil, err
+		}
+
+		or.key = &key
+	}
+
+	return or.key, nil
+}
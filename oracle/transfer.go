@@ -0,0 +1,244 @@
+package oracle
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Lavode/cryptopals/cipher"
+	"github.com/Lavode/cryptopals/profile"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// Transfer is a transfer of an amount of money from one account to another.
+type Transfer struct {
+	From   int
+	To     int
+	Amount int
+}
+
+// TransferServer provides an oracle simulating an API server which executes
+// money transfers, as well as the web client which users use to create
+// requests for it. Requests are authenticated with a CBC-MAC, using a key
+// shared between client and server.
+//
+// The client only creates requests for transfers from the attacker's account,
+// given by AttackerAccount. Requests from other users can be captured from
+// the network.
+//
+// Two versions of the protocol are supported:
+//
+// Version 1 requests are of the form message || IV || MAC, where the message
+// is:
+//
+//	from=#{from_id}&to=#{to_id}&amount=#{amount}
+//
+// and the IV is chosen at random by the client.
+//
+// Version 2 requests are of the form message || MAC, where the message is:
+//
+//	from=#{from_id}&tx_list=#{to_id}:#{amount}(;#{to_id}:#{amount})*
+//
+// and the IV is fixed to zero bytes.
+type TransferServer struct {
+	AttackerAccount int
+	// Rand is the source of randomness. Defaults to a CSPRNG if left
+	// unset.
+	Rand io.Reader
+	key  *[]byte
+}
+
+// ClientV1 creates a version 1 request for a transfer from the attacker's
+// account.
+func (or *TransferServer) ClientV1(from int, to int, amount int) ([]byte, error) {
+	if from != or.AttackerAccount {
+		return []byte{}, fmt.Errorf("Not authorized to transfer from account %d", from)
+	}
+
+	return or.requestV1(Transfer{From: from, To: to, Amount: amount})
+}
+
+// ClientV2 creates a version 2 request for a list of transfers from the
+// attacker's account. The From field of the transfers is ignored.
+func (or *TransferServer) ClientV2(from int, transfers []Transfer) ([]byte, error) {
+	if from != or.AttackerAccount {
+		return []byte{}, fmt.Errorf("Not authorized to transfer from account %d", from)
+	}
+
+	return or.requestV2(from, transfers)
+}
+
+// CaptureV2 returns a version 2 request which the given victim sent to the
+// server, paying random amounts to two other users.
+func (or *TransferServer) CaptureV2(victim int) ([]byte, error) {
+	transfers := make([]Transfer, 2)
+	for i := range transfers {
+		amount, err := randutil.Intn(randutil.Reader(or.Rand), 1000)
+		if err != nil {
+			return []byte{}, fmt.Errorf("Error generating random amount: %v", err)
+		}
+
+		transfers[i] = Transfer{To: victim + i + 1, Amount: amount + 1}
+	}
+
+	return or.requestV2(victim, transfers)
+}
+
+// HandleV1 verifies the MAC of a version 1 request, and returns the transfer
+// which the server executed.
+func (or *TransferServer) HandleV1(request []byte) (Transfer, error) {
+	if len(request) < 2*cipher.AESBlockSize {
+		return Transfer{}, fmt.Errorf("Request too short")
+	}
+
+	msgLength := len(request) - 2*cipher.AESBlockSize
+	msg := request[:msgLength]
+	iv := request[msgLength : msgLength+cipher.AESBlockSize]
+	mac := request[msgLength+cipher.AESBlockSize:]
+
+	err := or.verify(msg, iv, mac)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	params := profile.ParseQuery(string(msg))
+	transfer := Transfer{}
+	transfer.From, err = strconv.Atoi(params["from"])
+	if err != nil {
+		return Transfer{}, fmt.Errorf("Invalid sender: %v", err)
+	}
+	transfer.To, err = strconv.Atoi(params["to"])
+	if err != nil {
+		return Transfer{}, fmt.Errorf("Invalid recipient: %v", err)
+	}
+	transfer.Amount, err = strconv.Atoi(params["amount"])
+	if err != nil {
+		return Transfer{}, fmt.Errorf("Invalid amount: %v", err)
+	}
+
+	return transfer, nil
+}
+
+// HandleV2 verifies the MAC of a version 2 request, and returns the transfers
+// which the server executed, as parsed by ParseTransfersV2().
+func (or *TransferServer) HandleV2(request []byte) ([]Transfer, error) {
+	if len(request) < cipher.AESBlockSize {
+		return []Transfer{}, fmt.Errorf("Request too short")
+	}
+
+	msgLength := len(request) - cipher.AESBlockSize
+	msg := request[:msgLength]
+	mac := request[msgLength:]
+
+	err := or.verify(msg, make([]byte, cipher.AESBlockSize), mac)
+	if err != nil {
+		return []Transfer{}, err
+	}
+
+	return ParseTransfersV2(msg)
+}
+
+// ParseTransfersV2 parses the message of a version 2 request, without its
+// MAC, into the transfers it describes.
+//
+// As with common query parsers, each parameter is split at its first '=', so
+// values may contain further ones, and later parameters take precedence over
+// earlier ones of the same name.
+//
+// Much like the rest of the server, parsing of the transaction list is
+// lenient, and invalid transactions are silently skipped.
+func ParseTransfersV2(msg []byte) ([]Transfer, error) {
+	params := make(map[string]string)
+	for _, pair := range strings.Split(string(msg), "&") {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			params[key] = value
+		}
+	}
+
+	from, err := strconv.Atoi(params["from"])
+	if err != nil {
+		return []Transfer{}, fmt.Errorf("Invalid sender: %v", err)
+	}
+
+	transfers := make([]Transfer, 0)
+	for _, tx := range strings.Split(params["tx_list"], ";") {
+		fields := strings.Split(tx, ":")
+		if len(fields) != 2 {
+			continue
+		}
+
+		to, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		amount, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		transfers = append(transfers, Transfer{From: from, To: to, Amount: amount})
+	}
+
+	return transfers, nil
+}
+
+func (or *TransferServer) requestV1(transfer Transfer) ([]byte, error) {
+	msg := []byte(fmt.Sprintf("from=%d&to=%d&amount=%d", transfer.From, transfer.To, transfer.Amount))
+
+	iv, err := cipher.NewIV(or.Rand)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	mac, err := or.mac(msg, iv)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	request := append(msg, iv...)
+	return append(request, mac...), nil
+}
+
+func (or *TransferServer) requestV2(from int, transfers []Transfer) ([]byte, error) {
+	txs := make([]string, len(transfers))
+	for i, transfer := range transfers {
+		txs[i] = fmt.Sprintf("%d:%d", transfer.To, transfer.Amount)
+	}
+	msg := []byte(fmt.Sprintf("from=%d&tx_list=%s", from, strings.Join(txs, ";")))
+
+	mac, err := or.mac(msg, make([]byte, cipher.AESBlockSize))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append(msg, mac...), nil
+}
+
+func (or *TransferServer) mac(msg []byte, iv []byte) ([]byte, error) {
+	if or.key == nil {
+		key, err := cipher.NewKeyFrom(or.Rand)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		or.key = &key
+	}
+
+	cbc := cipher.AESCBC{Key: *or.key, IV: iv}
+	return cbc.MAC(msg)
+}
+
+func (or *TransferServer) verify(msg []byte, iv []byte, mac []byte) error {
+	expected, err := or.mac(msg, iv)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(expected, mac) != 1 {
+		return fmt.Errorf("Invalid MAC")
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package padding
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// pkcs1v15MinPaddingLength is the minimum number of non-zero random padding
+// bytes mandated by PKCS#1 v1.5.
+const pkcs1v15MinPaddingLength = 8
+
+// PKCS1v15Pad pads the message to a length of k bytes - the size of the RSA
+// modulus - using PKCS#1 v1.5 encryption padding (block type 2).
+//
+// The padded message has the form:
+//
+//	00 || 02 || PS || 00 || msg
+//
+// where PS consists of at least 8 non-zero bytes chosen at random.
+//
+// An error is returned if the message is too long to fit into k bytes.
+func PKCS1v15Pad(msg []byte, k int) ([]byte, error) {
+	return PKCS1v15PadFrom(nil, msg, k)
+}
+
+// PKCS1v15PadFrom pads the message as PKCS1v15Pad() does, reading the random
+// padding bytes from the given source of randomness. If r is nil, a CSPRNG is
+// used.
+func PKCS1v15PadFrom(r io.Reader, msg []byte, k int) ([]byte, error) {
+	r = randutil.Reader(r)
+
+	psLength := k - len(msg) - 3
+	if psLength < pkcs1v15MinPaddingLength {
+		return []byte{}, fmt.Errorf(
+			"Message of length %d too long for %d byte modulus",
+			len(msg),
+			k,
+		)
+	}
+
+	padded := make([]byte, k)
+	padded[1] = 0x02
+
+	ps := padded[2 : 2+psLength]
+	_, err := io.ReadFull(r, ps)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Error generating random padding: %v", err)
+	}
+
+	// Padding bytes must be non-zero, so we'll replace any zero byte
+	// until there are none left.
+	for i := range ps {
+		for ps[i] == 0 {
+			_, err = io.ReadFull(r, ps[i:i+1])
+			if err != nil {
+				return []byte{}, fmt.Errorf("Error generating random padding: %v", err)
+			}
+		}
+	}
+
+	copy(padded[3+psLength:], msg)
+
+	return padded, nil
+}
+
+// PKCS1v15Unpad removes PKCS#1 v1.5 encryption padding (block type 2) from
+// the supplied message.
+//
+// If the padding is invalid, an error is returned.
+func PKCS1v15Unpad(padded []byte) ([]byte, error) {
+	if len(padded) < 3+pkcs1v15MinPaddingLength {
+		return []byte{}, fmt.Errorf("Padded message too short")
+	}
+
+	if padded[0] != 0x00 || padded[1] != 0x02 {
+		return []byte{}, fmt.Errorf("Invalid padding header %x", padded[:2])
+	}
+
+	for i := 2; i < len(padded); i++ {
+		if padded[i] == 0x00 {
+			if i-2 < pkcs1v15MinPaddingLength {
+				return []byte{}, fmt.Errorf("Padding string too short")
+			}
+
+			msg := make([]byte, len(padded)-i-1)
+			copy(msg, padded[i+1:])
+
+			return msg, nil
+		}
+	}
+
+	return []byte{}, fmt.Errorf("Missing separator between padding and message")
+}
@@ -0,0 +1,59 @@
+package padding
+
+import (
+	"crypto"
+	"fmt"
+
+	// Registers the hash functions supported for signatures
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+)
+
+// pkcs1v15DigestInfoPrefixes are the DER encodings of the DigestInfo
+// structure of PKCS#1 v1.5 signatures, up to the hash value itself.
+var pkcs1v15DigestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+}
+
+// PKCS1v15SignaturePad hashes the message and pads the hash to a length of k
+// bytes - the size of the RSA modulus - using PKCS#1 v1.5 signature padding
+// (block type 1).
+//
+// The padded message has the form:
+//
+//	00 || 01 || FF ... FF || 00 || DigestInfo(hash, H(msg))
+//
+// where there are at least 8 FF bytes. Only crypto.SHA1 and crypto.SHA256
+// are supported.
+//
+// An error is returned if the hash function is not supported, or the padded
+// hash does not fit into k bytes.
+func PKCS1v15SignaturePad(hash crypto.Hash, msg []byte, k int) ([]byte, error) {
+	prefix, ok := pkcs1v15DigestInfoPrefixes[hash]
+	if !ok || !hash.Available() {
+		return []byte{}, fmt.Errorf("Hash function %v not supported", hash)
+	}
+
+	hasher := hash.New()
+	hasher.Write(msg)
+	digestInfo := append(append([]byte{}, prefix...), hasher.Sum(nil)...)
+
+	psLength := k - len(digestInfo) - 3
+	if psLength < pkcs1v15MinPaddingLength {
+		return []byte{}, fmt.Errorf(
+			"Hash of length %d too long for %d byte modulus",
+			len(digestInfo),
+			k,
+		)
+	}
+
+	padded := make([]byte, k)
+	padded[1] = 0x01
+	for i := 2; i < 2+psLength; i++ {
+		padded[i] = 0xff
+	}
+	copy(padded[3+psLength:], digestInfo)
+
+	return padded, nil
+}
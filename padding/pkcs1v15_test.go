@@ -0,0 +1,113 @@
+package padding
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPKCS1v15PadAndUnpad(t *testing.T) {
+	msg := []byte("kick it, CC")
+
+	padded, err := PKCS1v15Pad(msg, 32)
+	assert.Nil(t, err)
+	assert.Equal(t, 32, len(padded))
+	assert.Equal(t, []byte{0x00, 0x02}, padded[:2])
+	assert.Equal(t, byte(0x00), padded[32-len(msg)-1])
+	assert.Equal(t, -1, bytes.IndexByte(padded[2:32-len(msg)-1], 0x00))
+
+	unpadded, err := PKCS1v15Unpad(padded)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, unpadded)
+
+	// Empty message
+	padded, err = PKCS1v15Pad([]byte{}, 16)
+	assert.Nil(t, err)
+	unpadded, err = PKCS1v15Unpad(padded)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{}, unpadded)
+}
+
+func TestPKCS1v15PadFrom(t *testing.T) {
+	msg := []byte("kick it, CC")
+
+	padded1, err := PKCS1v15PadFrom(randutil.NewSeeded(42), msg, 32)
+	assert.Nil(t, err)
+	padded2, err := PKCS1v15PadFrom(randutil.NewSeeded(42), msg, 32)
+	assert.Nil(t, err)
+	assert.Equal(t, padded1, padded2)
+
+	// Zero bytes are replaced until the padding is non-zero
+	src := append(make([]byte, 20), bytes.Repeat([]byte{0x42}, 40)...)
+	padded, err := PKCS1v15PadFrom(bytes.NewReader(src), msg, 32)
+	assert.Nil(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{0x42}, 32-len(msg)-3), padded[2:32-len(msg)-1])
+}
+
+func TestPKCS1v15PadMessageTooLong(t *testing.T) {
+	// 3 bytes of framing and 8 of padding leave room for 21 bytes.
+	_, err := PKCS1v15Pad(make([]byte, 21), 32)
+	assert.Nil(t, err)
+
+	_, err = PKCS1v15Pad(make([]byte, 22), 32)
+	assert.Error(t, err)
+}
+
+func TestPKCS1v15UnpadInvalidPadding(t *testing.T) {
+	valid := []byte{
+		0x00, 0x02, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66,
+		0x77, 0x88, 0x00, 0x41, 0x42,
+	}
+	_, err := PKCS1v15Unpad(valid)
+	assert.Nil(t, err)
+
+	// Wrong block type
+	invalid := append([]byte{}, valid...)
+	invalid[1] = 0x01
+	_, err = PKCS1v15Unpad(invalid)
+	assert.Error(t, err)
+
+	// Padding string too short
+	invalid = append([]byte{}, valid...)
+	invalid[9] = 0x00
+	_, err = PKCS1v15Unpad(invalid)
+	assert.Error(t, err)
+
+	// Missing separator
+	invalid = append([]byte{}, valid...)
+	invalid[10] = 0x99
+	_, err = PKCS1v15Unpad(invalid)
+	assert.Error(t, err)
+}
+
+func TestPKCS1v15SignaturePad(t *testing.T) {
+	msg := []byte("hi mom")
+
+	padded, err := PKCS1v15SignaturePad(crypto.SHA256, msg, 128)
+	assert.Nil(t, err)
+	assert.Equal(t, 128, len(padded))
+	assert.Equal(t, []byte{0x00, 0x01, 0xff}, padded[:3])
+
+	hash := sha256.Sum256(msg)
+	assert.Equal(t, hash[:], padded[128-32:])
+	assert.Equal(t, byte(0x00), padded[128-32-19-1])
+	assert.Equal(t, byte(0xff), padded[128-32-19-2])
+
+	// Deterministic
+	again, err := PKCS1v15SignaturePad(crypto.SHA256, msg, 128)
+	assert.Nil(t, err)
+	assert.Equal(t, padded, again)
+
+	// 3 bytes of framing, 8 of padding and 35 of SHA-1 DigestInfo
+	_, err = PKCS1v15SignaturePad(crypto.SHA1, msg, 46)
+	assert.Nil(t, err)
+	_, err = PKCS1v15SignaturePad(crypto.SHA1, msg, 45)
+	assert.Error(t, err)
+
+	_, err = PKCS1v15SignaturePad(crypto.MD5, msg, 128)
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Package randutil provides sources of randomness which may be injected into
+// key generation and oracles, so that runs involving them can be reproduced.
+package randutil
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	mathrand "math/rand"
+	"sync"
+)
+
+// Reader returns r, or the CSPRNG crypto/rand.Reader if r is nil.
+//
+// This allows for structs to expose an optional source of randomness, which
+// defaults to a secure one if left unset.
+func Reader(r io.Reader) io.Reader {
+	if r == nil {
+		return rand.Reader
+	}
+
+	return r
+}
+
+// NewSeeded returns a deterministic source of randomness, which produces the
+// same stream of bytes for the same seed.
+//
+// It is safe for concurrent use. Each call to Read() takes a contiguous chunk
+// of the stream, so concurrent readers get the same chunks as sequential ones,
+// albeit possibly in a different order.
+//
+// It is not cryptographically secure, and only meant for tests and for
+// replaying runs of oracles.
+func NewSeeded(seed int64) io.Reader {
+	return &lockedReader{r: mathrand.New(mathrand.NewSource(seed))}
+}
+
+// lockedReader serializes calls to Read() of the underlying reader.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.r.Read(p)
+}
+
+// Intn returns an integer chosen uniformly at random from [0, n), reading
+// from r.
+func Intn(r io.Reader, n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("Upper bound must be positive, got %d", n)
+	}
+
+	x, err := rand.Int(r, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("Error generating random integer: %v", err)
+	}
+
+	return int(x.Int64()), nil
+}
@@ -0,0 +1,83 @@
+package randutil
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	assert.Equal(t, rand.Reader, Reader(nil))
+
+	r := NewSeeded(1)
+	assert.Equal(t, r, Reader(r))
+}
+
+func TestNewSeeded(t *testing.T) {
+	a := make([]byte, 64)
+	b := make([]byte, 64)
+	c := make([]byte, 64)
+
+	_, err := io.ReadFull(NewSeeded(42), a)
+	assert.Nil(t, err)
+	_, err = io.ReadFull(NewSeeded(42), b)
+	assert.Nil(t, err)
+	_, err = io.ReadFull(NewSeeded(43), c)
+	assert.Nil(t, err)
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestNewSeededConcurrent(t *testing.T) {
+	// Concurrent readers get the same chunks as a sequential one
+	expected := make(map[string]bool)
+	r := NewSeeded(42)
+	for i := 0; i < 100; i++ {
+		chunk := make([]byte, 16)
+		_, err := io.ReadFull(r, chunk)
+		assert.Nil(t, err)
+		expected[string(chunk)] = true
+	}
+
+	r = NewSeeded(42)
+	chunks := make(chan []byte, 100)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				chunk := make([]byte, 16)
+				io.ReadFull(r, chunk)
+				chunks <- chunk
+			}
+		}()
+	}
+	wg.Wait()
+	close(chunks)
+
+	actual := make(map[string]bool)
+	for chunk := range chunks {
+		actual[string(chunk)] = true
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestIntn(t *testing.T) {
+	r := NewSeeded(42)
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		x, err := Intn(r, 6)
+		assert.Nil(t, err)
+		assert.True(t, x >= 0 && x < 6)
+		seen[x] = true
+	}
+	assert.Len(t, seen, 6)
+
+	_, err := Intn(r, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,227 @@
+package rsa
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Lavode/cryptopals/padding"
+	"github.com/Lavode/cryptopals/randutil"
+)
+
+// DefaultExponent is the public exponent used unless specified otherwise.
+const DefaultExponent = 65537
+
+// PublicKey is an RSA public key, consisting of the modulus N and the public
+// exponent E.
+type PublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// PrivateKey is an RSA private key, consisting of the public key, the private
+// exponent D and the prime factors P and Q of the modulus.
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+	P *big.Int
+	Q *big.Int
+}
+
+// GenerateKey generates a new key pair with a modulus of the given size in
+// bits and the given public exponent.
+//
+// The prime factors are chosen using a CSPRNG, and such that the public
+// exponent is invertible modulo (p-1)(q-1).
+func GenerateKey(bits int, e int64) (PrivateKey, error) {
+	return GenerateKeyFrom(nil, bits, e)
+}
+
+// GenerateKeyFrom generates a new key pair as GenerateKey() does, reading
+// from the given source of randomness. If r is nil, a CSPRNG is used.
+func GenerateKeyFrom(r io.Reader, bits int, e int64) (PrivateKey, error) {
+	r = randutil.Reader(r)
+
+	if bits < 16 {
+		return PrivateKey{}, fmt.Errorf("Modulus must have at least 16 bits, got %d", bits)
+	}
+
+	exponent := big.NewInt(e)
+	one := big.NewInt(1)
+
+	for {
+		p, err := randomPrime(r, (bits+1)/2)
+		if err != nil {
+			return PrivateKey{}, fmt.Errorf("Error generating prime: %v", err)
+		}
+
+		q, err := randomPrime(r, bits/2)
+		if err != nil {
+			return PrivateKey{}, fmt.Errorf("Error generating prime: %v", err)
+		}
+
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			// Product of two k-bit primes might have 2k - 1 bits
+			continue
+		}
+
+		pMinusOne := new(big.Int).Sub(p, one)
+		qMinusOne := new(big.Int).Sub(q, one)
+		totient := new(big.Int).Mul(pMinusOne, qMinusOne)
+
+		d := new(big.Int).ModInverse(exponent, totient)
+		if d == nil {
+			// e not coprime to totient
+			continue
+		}
+
+		return PrivateKey{
+			PublicKey: PublicKey{N: n, E: exponent},
+			D:         d,
+			P:         p,
+			Q:         q,
+		}, nil
+	}
+}
+
+// Size returns the size of the modulus in bytes.
+func (pub *PublicKey) Size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// Encrypt encrypts the message with textbook RSA, that is it calculates
+// m^e mod N.
+//
+// No padding is applied. An error is returned if the message is not in
+// [0, N).
+func (pub *PublicKey) Encrypt(msg *big.Int) (*big.Int, error) {
+	if msg.Sign() < 0 || msg.Cmp(pub.N) >= 0 {
+		return nil, fmt.Errorf("Message must be in range [0, N)")
+	}
+
+	return new(big.Int).Exp(msg, pub.E, pub.N), nil
+}
+
+// Decrypt decrypts the ciphertext with textbook RSA, that is it calculates
+// c^d mod N.
+//
+// If the prime factors of the modulus are known, the Chinese remainder
+// theorem is used to speed up decryption.
+//
+// An error is returned if the ciphertext is not in [0, N).
+func (priv *PrivateKey) Decrypt(ctxt *big.Int) (*big.Int, error) {
+	if ctxt.Sign() < 0 || ctxt.Cmp(priv.N) >= 0 {
+		return nil, fmt.Errorf("Ciphertext must be in range [0, N)")
+	}
+
+	if priv.P == nil || priv.Q == nil {
+		return new(big.Int).Exp(ctxt, priv.D, priv.N), nil
+	}
+
+	one := big.NewInt(1)
+
+	// m_p = c^(d mod p-1) mod p, and likewise for q
+	dp := new(big.Int).Mod(priv.D, new(big.Int).Sub(priv.P, one))
+	dq := new(big.Int).Mod(priv.D, new(big.Int).Sub(priv.Q, one))
+	mp := new(big.Int).Exp(ctxt, dp, priv.P)
+	mq := new(big.Int).Exp(ctxt, dq, priv.Q)
+
+	// m = m_q + q * ((m_p - m_q) * q^-1 mod p)
+	qInv := new(big.Int).ModInverse(priv.Q, priv.P)
+	h := new(big.Int).Sub(mp, mq)
+	h.Mul(h, qInv)
+	h.Mod(h, priv.P)
+
+	msg := h.Mul(h, priv.Q)
+	msg.Add(msg, mq)
+
+	return msg, nil
+}
+
+// Sign signs the message with RSA, using PKCS#1 v1.5 signature padding as
+// applied by padding.PKCS1v15SignaturePad(). The signature is of the size of
+// the modulus.
+func (priv *PrivateKey) Sign(hash crypto.Hash, msg []byte) ([]byte, error) {
+	padded, err := padding.PKCS1v15SignaturePad(hash, msg, priv.Size())
+	if err != nil {
+		return []byte{}, err
+	}
+
+	sig, err := priv.Decrypt(new(big.Int).SetBytes(padded))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	out := make([]byte, priv.Size())
+	return sig.FillBytes(out), nil
+}
+
+// Verify checks whether the signature is valid for the given message.
+//
+// Rather than parsing the padding of s^e mod N, which is prone to errors,
+// the expected padding of the message is calculated and compared.
+func (pub *PublicKey) Verify(hash crypto.Hash, msg []byte, sig []byte) bool {
+	if len(sig) != pub.Size() {
+		return false
+	}
+
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pub.N) >= 0 {
+		return false
+	}
+
+	expected, err := padding.PKCS1v15SignaturePad(hash, msg, pub.Size())
+	if err != nil {
+		return false
+	}
+
+	padded := new(big.Int).Exp(s, pub.E, pub.N)
+	return padded.Cmp(new(big.Int).SetBytes(expected)) == 0
+}
+
+// randomPrime returns a prime of the given number of bits, whose candidates
+// are read from r.
+//
+// This does the same as crypto/rand.Prime(), which however ignores the reader
+// given to it as of Go 1.26, so would not be deterministic for deterministic
+// readers. As with it, the top two bits are set, so the product of two such
+// primes has exactly the sum of their lengths.
+func randomPrime(r io.Reader, bits int) (*big.Int, error) {
+	if bits < 2 {
+		return nil, fmt.Errorf("Prime must have at least 2 bits, got %d", bits)
+	}
+
+	// Number of bits used in the first byte
+	top := uint(bits % 8)
+	if top == 0 {
+		top = 8
+	}
+
+	buf := make([]byte, (bits+7)/8)
+	p := new(big.Int)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		buf[0] &= byte(1<<top - 1)
+		if top >= 2 {
+			buf[0] |= 3 << (top - 2)
+		} else {
+			buf[0] |= 1
+			buf[1] |= 0x80
+		}
+		buf[len(buf)-1] |= 1
+
+		p.SetBytes(buf)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
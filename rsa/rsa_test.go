@@ -0,0 +1,93 @@
+package rsa
+
+import (
+	"crypto"
+	"math/big"
+	"testing"
+
+	"github.com/Lavode/cryptopals/randutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKey(t *testing.T) {
+	for _, bits := range []int{256, 512, 1024} {
+		priv, err := GenerateKey(bits, DefaultExponent)
+		assert.Nil(t, err)
+
+		assert.Equal(t, bits, priv.N.BitLen())
+		assert.Equal(t, bits/8, priv.Size())
+		assert.Equal(t, priv.N, new(big.Int).Mul(priv.P, priv.Q))
+	}
+
+	_, err := GenerateKey(8, DefaultExponent)
+	assert.Error(t, err)
+}
+
+func TestGenerateKeyFrom(t *testing.T) {
+	priv1, err := GenerateKeyFrom(randutil.NewSeeded(42), 512, DefaultExponent)
+	assert.Nil(t, err)
+
+	priv2, err := GenerateKeyFrom(randutil.NewSeeded(42), 512, DefaultExponent)
+	assert.Nil(t, err)
+
+	assert.Equal(t, priv1.N, priv2.N)
+	assert.Equal(t, priv1.D, priv2.D)
+}
+
+func TestRandomPrime(t *testing.T) {
+	r := randutil.NewSeeded(42)
+	for _, bits := range []int{2, 3, 8, 9, 16, 127, 512} {
+		p, err := randomPrime(r, bits)
+		assert.Nil(t, err)
+		assert.Equal(t, bits, p.BitLen())
+		assert.True(t, p.ProbablyPrime(20))
+	}
+
+	_, err := randomPrime(r, 1)
+	assert.Error(t, err)
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	priv, err := GenerateKey(512, 3)
+	assert.Nil(t, err)
+
+	msg := new(big.Int).SetBytes([]byte("Hello world"))
+	ctxt, err := priv.Encrypt(msg)
+	assert.Nil(t, err)
+	assert.NotEqual(t, msg, ctxt)
+
+	decrypted, err := priv.Decrypt(ctxt)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, msg.Cmp(decrypted))
+
+	// Without the prime factors, decryption falls back to plain
+	// exponentiation.
+	withoutFactors := PrivateKey{PublicKey: priv.PublicKey, D: priv.D}
+	decrypted, err = withoutFactors.Decrypt(ctxt)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, msg.Cmp(decrypted))
+
+	_, err = priv.Encrypt(priv.N)
+	assert.Error(t, err)
+	_, err = priv.Decrypt(big.NewInt(-1))
+	assert.Error(t, err)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	priv, err := GenerateKey(1024, DefaultExponent)
+	assert.Nil(t, err)
+
+	msg := []byte("hi mom")
+
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		sig, err := priv.Sign(hash, msg)
+		assert.Nil(t, err)
+		assert.Equal(t, priv.Size(), len(sig))
+		assert.True(t, priv.Verify(hash, msg, sig))
+
+		assert.False(t, priv.Verify(hash, []byte("hi dad"), sig))
+
+		sig[len(sig)-1] ^= 0x01
+		assert.False(t, priv.Verify(hash, msg, sig))
+	}
+}
@@ -0,0 +1,121 @@
+package toyhash
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Lavode/cryptopals/cipher"
+)
+
+// BlockSize specifies the length of a message block in bytes.
+const BlockSize = cipher.AESBlockSize
+
+// MinBits and MaxBits specify the range of supported state sizes in bits.
+const (
+	MinBits = 16
+	MaxBits = 32
+)
+
+// Hash is a toy Merkle-Damgård hash function, with a state of only a few
+// bits. This makes generic attacks on iterated hashes - which would be
+// infeasible against a real hash - run in seconds.
+//
+// The compression function uses AES-128, with the message block as the key,
+// to encrypt the state (padded to a full block with zero bytes). The
+// ciphertext is then truncated to the size of the state:
+//
+//	h_i = TRUNC(AES_{m_i}(h_{i-1}))
+//
+// Messages are padded with MD strengthening, that is with a single 1 bit,
+// as many 0 bits as required, and the message length in bits as a 64-bit
+// big-endian integer.
+//
+// The number of calls to the compression function is tracked in Calls, which
+// allows to compare the costs of attacks with their theoretical bounds.
+type Hash struct {
+	// Bits is the size of the state in bits.
+	Bits int
+	// IV is the initial state.
+	IV uint32
+	// Calls is the number of calls to the compression function so far.
+	Calls int
+}
+
+// New instantiates a hash function with a state of the given number of bits,
+// and the given initial state.
+//
+// An error is returned if the state size is outside of [MinBits, MaxBits].
+func New(bits int, iv uint32) (*Hash, error) {
+	if bits < MinBits || bits > MaxBits {
+		return nil, fmt.Errorf("State size must be in [%d, %d], but was %d", MinBits, MaxBits, bits)
+	}
+
+	h := &Hash{Bits: bits}
+	h.IV = iv & h.mask()
+
+	return h, nil
+}
+
+// Compress applies the compression function to the state and a single
+// message block.
+//
+// It panics if the block is not exactly BlockSize bytes long.
+func (h *Hash) Compress(state uint32, block []byte) uint32 {
+	if len(block) != BlockSize {
+		panic(fmt.Sprintf("Block must be of length %d, but was %d", BlockSize, len(block)))
+	}
+	h.Calls++
+
+	msg := make([]byte, cipher.AESBlockSize)
+	binary.BigEndian.PutUint32(msg, state)
+
+	// Block of the right length is guaranteed to be a valid key, and the
+	// message is guaranteed to be a single block.
+	ecb := cipher.AESECB{Key: block}
+	ctxt, _ := ecb.Encrypt(msg)
+
+	return binary.BigEndian.Uint32(ctxt) >> (32 - h.Bits)
+}
+
+// Iterate applies the compression function to the state and each block of
+// the message in turn, returning the final state. No padding is applied.
+//
+// It panics if the message is not a multiple of BlockSize bytes long.
+func (h *Hash) Iterate(state uint32, msg []byte) uint32 {
+	if len(msg)%BlockSize != 0 {
+		panic(fmt.Sprintf("Message must be a multiple of %d bytes, but was %d", BlockSize, len(msg)))
+	}
+
+	for i := 0; i < len(msg); i += BlockSize {
+		state = h.Compress(state, msg[i:i+BlockSize])
+	}
+
+	return state
+}
+
+// Sum pads the message and calculates its hash, starting at the IV.
+func (h *Hash) Sum(msg []byte) uint32 {
+	padded := append(append([]byte{}, msg...), Padding(len(msg))...)
+	return h.Iterate(h.IV, padded)
+}
+
+// Padding returns the padding which is appended to a message of the given
+// length in bytes.
+func Padding(length int) []byte {
+	// 0x80, then zero bytes up to the last 8 bytes of a block, then the
+	// length.
+	padLength := BlockSize - (length+1+8)%BlockSize
+	if padLength == BlockSize {
+		padLength = 0
+	}
+
+	padding := make([]byte, 1+padLength+8)
+	padding[0] = 0x80
+	binary.BigEndian.PutUint64(padding[1+padLength:], uint64(length)*8)
+
+	return padding
+}
+
+func (h *Hash) mask() uint32 {
+	return uint32(1<<h.Bits - 1)
+}
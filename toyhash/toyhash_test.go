@@ -0,0 +1,73 @@
+package toyhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	h, err := New(16, 0xdeadbeef)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0xbeef), h.IV)
+
+	_, err = New(15, 0)
+	assert.Error(t, err)
+	_, err = New(33, 0)
+	assert.Error(t, err)
+}
+
+func TestCompressTruncatesState(t *testing.T) {
+	for _, bits := range []int{16, 20, 24, 32} {
+		h, err := New(bits, 0)
+		assert.Nil(t, err)
+
+		for i := 0; i < 100; i++ {
+			block := make([]byte, BlockSize)
+			block[0] = byte(i)
+
+			state := h.Compress(uint32(i), block)
+			assert.Less(t, uint64(state), uint64(1)<<bits)
+		}
+		assert.Equal(t, 100, h.Calls)
+	}
+}
+
+func TestCompressIsTruncatedAES(t *testing.T) {
+	h32, err := New(32, 0)
+	assert.Nil(t, err)
+	h16, err := New(16, 0)
+	assert.Nil(t, err)
+
+	block := []byte("YELLOW SUBMARINE")
+	// The smaller state consists of the leading bits of the larger one.
+	assert.Equal(t, h32.Compress(0x1234, block)>>16, h16.Compress(0x1234, block))
+}
+
+func TestPadding(t *testing.T) {
+	for length := 0; length < 100; length++ {
+		padding := Padding(length)
+
+		assert.Equal(t, 0, (length+len(padding))%BlockSize)
+		assert.Equal(t, byte(0x80), padding[0])
+		assert.LessOrEqual(t, len(padding), BlockSize+8)
+	}
+
+	assert.Equal(t, []byte{
+		0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40,
+	}, Padding(8))
+}
+
+func TestSum(t *testing.T) {
+	h, err := New(24, 0x1337)
+	assert.Nil(t, err)
+
+	msg := []byte("Hello world")
+	assert.Equal(t, h.Sum(msg), h.Sum(msg))
+	assert.NotEqual(t, h.Sum(msg), h.Sum([]byte("Hello world!")))
+
+	padded := append(append([]byte{}, msg...), Padding(len(msg))...)
+	assert.Equal(t, h.Iterate(h.IV, padded), h.Sum(msg))
+}